@@ -7,15 +7,43 @@ import (
 )
 
 type WebSocketConfig struct {
-	JWTSecret    string `yaml:"jwt_secret"`
-	RateLimit    int    `yaml:"rate_limit"`
-	WriteTimeout int    `yaml:"write_timeout"`
+	JWTSecret             string `yaml:"jwt_secret"`
+	RateLimit             int    `yaml:"rate_limit"`
+	WriteTimeout          int    `yaml:"write_timeout"`
+	MaxConnectionsPerUser int    `yaml:"max_connections_per_user"`
+}
+
+type MongoConfig struct {
+	URI      string `yaml:"uri"`
+	Database string `yaml:"database"`
+}
+
+type RedisConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Password string `yaml:"password"`
+	DB       int    `yaml:"db"`
+}
+
+type StorageConfig struct {
+	Dir     string `yaml:"dir"`      // Каталог с файлами на локальном диске
+	BaseURL string `yaml:"base_url"` // Адрес, по которому клиенты загружают файлы
+}
+
+type ClientConfig struct {
+	Address string `yaml:"address"`
 }
 
 type ChatServiceConfig struct {
 	DB        DBConfig        `yaml:"db"`
 	Server    ServerConfig    `yaml:"server"`
+	GRPC      ServerConfig    `yaml:"grpc"`
 	WebSocket WebSocketConfig `yaml:"websocket"`
+	Mongo     MongoConfig     `yaml:"mongo"`
+	Redis     RedisConfig     `yaml:"redis"`
+	Streaming ClientConfig    `yaml:"streaming_service"`
+	Storage   StorageConfig   `yaml:"storage"` // Файлы для клиентов: изображения эмоутов
+	Admins    []string        `yaml:"admins"`  // ID администраторов платформы
 }
 
 func LoadChatConfig() (*ChatServiceConfig, error) {
@@ -0,0 +1,17 @@
+package config
+
+// DBConfig — параметры подключения к PostgreSQL
+type DBConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"ssl_mode"`
+}
+
+// ServerConfig — адрес, на котором сервис принимает запросы
+type ServerConfig struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+}
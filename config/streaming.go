@@ -10,6 +10,7 @@ import (
 type StreamingServiceConfig struct {
 	DB     DBConfig     `yaml:"db"`
 	Server ServerConfig `yaml:"server"`
+	GRPC   ServerConfig `yaml:"grpc"`
 }
 
 // LoadStreamingConfig загружает конфигурацию streaming-service
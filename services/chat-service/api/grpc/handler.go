@@ -0,0 +1,252 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/auth"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/proto"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ChatService описывает бизнес-логику чата, которую использует gRPC хендлер
+type ChatService interface {
+	PrepareMessage(ctx context.Context, msg *entity.ChatMessage, replyTo uuid.UUID) error
+	SendMessage(ctx context.Context, msg *entity.ChatMessage) error
+	NotifyMentions(ctx context.Context, msg *entity.ChatMessage)
+	GetMessages(ctx context.Context, query entity.MessageQuery) (*entity.MessagePage, error)
+	ReplayMessages(ctx context.Context, streamID uuid.UUID, afterSeq int64) (*entity.MessageReplay, error)
+	ListChatters(ctx context.Context, streamID uuid.UUID, offset, limit int) (*entity.ChatterPage, error)
+}
+
+// LiveChat описывает подписку на живые сообщения комнат стримов
+type LiveChat interface {
+	Subscribe(streamID uuid.UUID) (<-chan *entity.ChatMessage, func())
+}
+
+// ChatHandler реализует gRPC интерфейс ChatService
+type ChatHandler struct {
+	proto.UnimplementedChatServiceServer
+	chatService ChatService
+	live        LiveChat
+	tokens      *auth.TokenValidator
+}
+
+// NewChatHandler создаёт новый gRPC хендлер
+func NewChatHandler(chatService ChatService, live LiveChat, tokens *auth.TokenValidator) *ChatHandler {
+	return &ChatHandler{chatService: chatService, live: live, tokens: tokens}
+}
+
+// SendMessage сохраняет сообщение и рассылает его зрителям стрима. Автор
+// сообщения — владелец access-токена из метаданных authorization; user_id
+// запроса, если задан, должен с ним совпадать
+func (h *ChatHandler) SendMessage(ctx context.Context, req *proto.ChatMessage) (*proto.ChatResponse, error) {
+	claims, err := h.authenticate(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid or missing access token")
+	}
+	if req.UserId != "" && req.UserId != claims.UserID.String() {
+		return nil, status.Error(codes.PermissionDenied, "user_id does not match access token")
+	}
+	streamID, err := uuid.Parse(req.StreamId)
+	if err != nil {
+		return errorResponse("invalid stream_id"), nil
+	}
+
+	// Сообщение проходит те же проверки, что и в WebSocket. Ответы через gRPC
+	// не поддерживаются, но упоминания разбираются так же
+	msg := entity.NewChatMessage(streamID, claims.UserID, claims.Username, req.Content)
+	if err := h.chatService.PrepareMessage(ctx, msg, uuid.Nil); err != nil {
+		return rejectResponse(err), nil
+	}
+
+	if err := h.chatService.SendMessage(ctx, msg); err != nil {
+		return errorResponse("failed to save message"), nil
+	}
+	h.chatService.NotifyMentions(ctx, msg)
+
+	return &proto.ChatResponse{Status: "success"}, nil
+}
+
+// GetChatHistory возвращает страницу истории сообщений стрима
+func (h *ChatHandler) GetChatHistory(ctx context.Context, req *proto.ChatHistoryRequest) (*proto.ChatHistoryResponse, error) {
+	streamID, err := uuid.Parse(req.StreamId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid stream_id")
+	}
+
+	query := entity.MessageQuery{StreamID: streamID, Limit: int(req.Limit)}
+	if query.Before, err = entity.ParseMessageCursor(req.Before); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid before cursor")
+	}
+	if query.After, err = entity.ParseMessageCursor(req.After); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid after cursor")
+	}
+
+	page, err := h.chatService.GetMessages(ctx, query)
+	if errors.Is(err, repository.ErrMessageNotFound) {
+		return nil, status.Error(codes.InvalidArgument, "cursor message not found")
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get chat history")
+	}
+
+	resp := &proto.ChatHistoryResponse{
+		Messages: make([]*proto.ChatMessage, 0, len(page.Messages)),
+		HasMore:  page.HasMore,
+	}
+	for _, msg := range page.Messages {
+		resp.Messages = append(resp.Messages, toProto(msg))
+	}
+	return resp, nil
+}
+
+// StreamMessages передает новые сообщения стрима, пока клиент не отключится.
+// Если указан last_seq, сначала повторяются сообщения, пропущенные после него.
+// Отстающий клиент отключается с ResourceExhausted, чтобы повторить пропущенное
+func (h *ChatHandler) StreamMessages(req *proto.StreamMessageRequest, stream proto.ChatService_StreamMessagesServer) error {
+	streamID, err := uuid.Parse(req.StreamId)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "invalid stream_id")
+	}
+	if req.LastSeq < 0 {
+		return status.Error(codes.InvalidArgument, "invalid last_seq")
+	}
+
+	// Подписка оформляется до повтора, чтобы не потерять сообщения между ними
+	messages, unsubscribe := h.live.Subscribe(streamID)
+	defer unsubscribe()
+
+	var replayed int64
+	if req.LastSeq > 0 {
+		replay, err := h.chatService.ReplayMessages(stream.Context(), streamID, req.LastSeq)
+		if err != nil {
+			return status.Error(codes.Internal, "failed to replay messages")
+		}
+		for _, msg := range replay.Messages {
+			if err := stream.Send(toProto(msg)); err != nil {
+				return err
+			}
+			replayed = msg.Seq
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				// Клиент не успевал забирать сообщения: он переподключается
+				// с last_seq и получает пропущенное повтором
+				return status.Error(codes.ResourceExhausted, "stream fell behind, resume from last seq")
+			}
+			// Уже повторенные сообщения не отправляются второй раз
+			if msg.Seq != 0 && msg.Seq <= replayed {
+				continue
+			}
+			if err := stream.Send(toProto(msg)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// GetChatters возвращает страницу пользователей в чате стрима
+func (h *ChatHandler) GetChatters(ctx context.Context, req *proto.ChattersRequest) (*proto.ChattersResponse, error) {
+	streamID, err := uuid.Parse(req.StreamId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid stream_id")
+	}
+	if req.Offset < 0 || req.Limit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid offset or limit")
+	}
+
+	page, err := h.chatService.ListChatters(ctx, streamID, int(req.Offset), int(req.Limit))
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get chatters")
+	}
+
+	resp := &proto.ChattersResponse{
+		Total:      int32(page.Total),
+		Counts:     make(map[string]int32, len(page.Counts)),
+		HasMore:    page.HasMore,
+		NextOffset: int32(page.NextOffset),
+	}
+	for role, count := range page.Counts {
+		resp.Counts[string(role)] = int32(count)
+	}
+	// Группы передаются подряд в порядке старшинства ролей
+	for _, role := range []entity.Role{entity.RoleBroadcaster, entity.RoleModerator, entity.RoleVIP, entity.RoleViewer} {
+		for _, chatter := range page.Chatters[role] {
+			resp.Chatters = append(resp.Chatters, &proto.Chatter{
+				UserId:   chatter.UserID.String(),
+				Username: chatter.Username,
+				Role:     string(chatter.Role),
+			})
+		}
+	}
+	return resp, nil
+}
+
+// authenticate возвращает пользователя по access-токену из метаданных запроса
+func (h *ChatHandler) authenticate(ctx context.Context) (*auth.Claims, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 || !strings.HasPrefix(values[0], "Bearer ") {
+		return nil, auth.ErrInvalidToken
+	}
+	return h.tokens.Validate(strings.TrimPrefix(values[0], "Bearer "))
+}
+
+// toProto конвертирует сообщение в gRPC представление
+func toProto(msg *entity.ChatMessage) *proto.ChatMessage {
+	return &proto.ChatMessage{
+		Id:        msg.ID.String(),
+		UserId:    msg.UserID.String(),
+		StreamId:  msg.StreamID.String(),
+		Username:  msg.Username,
+		Content:   msg.Content,
+		Badges:    msg.Badges,
+		Timestamp: msg.Timestamp.UnixMilli(),
+		Seq:       msg.Seq,
+	}
+}
+
+// rejections — причины отказа, которые передаются клиенту как есть
+var rejections = []error{
+	service.ErrInvalidMessage,
+	service.ErrRateLimited,
+	service.ErrBanned,
+	service.ErrTimedOut,
+	service.ErrBanCheckFailed,
+	service.ErrSlowMode,
+	service.ErrFollowersOnly,
+	service.ErrSubscribersOnly,
+	service.ErrEmoteOnly,
+	service.ErrMessageRejected,
+	service.ErrMessageHeld,
+}
+
+// rejectResponse формирует ответ на отклоненное сообщение. Текст внутренних
+// ошибок не передается клиенту
+func rejectResponse(err error) *proto.ChatResponse {
+	for _, rejection := range rejections {
+		if errors.Is(err, rejection) {
+			return errorResponse(err.Error())
+		}
+	}
+	return errorResponse("failed to process message")
+}
+
+// errorResponse формирует ответ с ошибкой
+func errorResponse(message string) *proto.ChatResponse {
+	return &proto.ChatResponse{Status: "error", ErrorMessage: message}
+}
@@ -0,0 +1,262 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	chatgrpc "github.com/exPriceD/Streaming-platform/services/chat-service/api/grpc"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/auth"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/proto"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testSecret = "test_secret"
+
+// newHandler создает хендлер, принимающий токены, подписанные testSecret
+func newHandler(chat chatgrpc.ChatService, live chatgrpc.LiveChat) *chatgrpc.ChatHandler {
+	return chatgrpc.NewChatHandler(chat, live, auth.NewTokenValidator(testSecret))
+}
+
+// withToken добавляет в контекст метаданные с access-токеном пользователя
+func withToken(t *testing.T, ctx context.Context, userID uuid.UUID, username string) context.Context {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id":  userID.String(),
+		"username": username,
+	}).SignedString([]byte(testSecret))
+	require.NoError(t, err)
+	return metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+token))
+}
+
+// stubChat отклоняет сообщения с ошибкой prepareErr, запоминает отправленные
+// сообщения, упоминания и запрос истории, повторяет replay и возвращает page как историю
+type stubChat struct {
+	chatgrpc.ChatService
+	prepareErr error
+	sent       []*entity.ChatMessage
+	notified   []*entity.ChatMessage
+	replay     *entity.MessageReplay
+	replayedAt int64
+	page       *entity.MessagePage
+	pageErr    error
+	query      *entity.MessageQuery
+}
+
+func (c *stubChat) PrepareMessage(_ context.Context, msg *entity.ChatMessage, _ uuid.UUID) error {
+	msg.Badges = []string{"vip"}
+	return c.prepareErr
+}
+
+func (c *stubChat) SendMessage(_ context.Context, msg *entity.ChatMessage) error {
+	c.sent = append(c.sent, msg)
+	return nil
+}
+
+func (c *stubChat) NotifyMentions(_ context.Context, msg *entity.ChatMessage) {
+	c.notified = append(c.notified, msg)
+}
+
+func (c *stubChat) GetMessages(_ context.Context, query entity.MessageQuery) (*entity.MessagePage, error) {
+	c.query = &query
+	return c.page, c.pageErr
+}
+
+func (c *stubChat) ReplayMessages(_ context.Context, _ uuid.UUID, afterSeq int64) (*entity.MessageReplay, error) {
+	c.replayedAt = afterSeq
+	return c.replay, nil
+}
+
+// stubLive отдает подписчику заранее заполненный канал живых сообщений
+type stubLive struct {
+	messages     chan *entity.ChatMessage
+	unsubscribed bool
+}
+
+func (l *stubLive) Subscribe(uuid.UUID) (<-chan *entity.ChatMessage, func()) {
+	return l.messages, func() { l.unsubscribed = true }
+}
+
+// recordingStream запоминает отправленные клиенту сообщения
+type recordingStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	mu   sync.Mutex
+	sent []*proto.ChatMessage
+}
+
+func (s *recordingStream) Context() context.Context { return s.ctx }
+
+func (s *recordingStream) Send(msg *proto.ChatMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func (s *recordingStream) seqs() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seqs := make([]int64, len(s.sent))
+	for i, msg := range s.sent {
+		seqs[i] = msg.Seq
+	}
+	return seqs
+}
+
+func TestSendMessage(t *testing.T) {
+	userID := uuid.New()
+	ctx := withToken(t, context.Background(), userID, "bot")
+	req := &proto.ChatMessage{StreamId: uuid.NewString(), Content: "hi @viewer"}
+
+	chat := &stubChat{}
+	h := newHandler(chat, nil)
+
+	// Автор определяется по токену, а не по полям запроса
+	_, err := h.SendMessage(context.Background(), req)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = h.SendMessage(metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Basic bot")), req)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = h.SendMessage(ctx, &proto.ChatMessage{StreamId: req.StreamId, UserId: uuid.NewString(), Content: "hi"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Empty(t, chat.sent)
+
+	resp, err := h.SendMessage(ctx, &proto.ChatMessage{StreamId: "stream", Content: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "error", resp.Status)
+	assert.Equal(t, "invalid stream_id", resp.ErrorMessage)
+
+	// Отказы проверок передаются клиенту, внутренние ошибки — нет
+	for prepareErr, message := range map[error]string{
+		fmt.Errorf("%w: 30s left", service.ErrSlowMode): "slow mode is enabled: 30s left",
+		service.ErrBanned:                       service.ErrBanned.Error(),
+		errors.New("redis: connection refused"): "failed to process message",
+	} {
+		chat.prepareErr = prepareErr
+		resp, err := h.SendMessage(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, "error", resp.Status)
+		assert.Equal(t, message, resp.ErrorMessage)
+	}
+	assert.Empty(t, chat.sent)
+
+	chat.prepareErr = nil
+	resp, err = h.SendMessage(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "success", resp.Status)
+	require.Len(t, chat.sent, 1)
+	assert.Equal(t, "hi @viewer", chat.sent[0].Content)
+	assert.Equal(t, userID, chat.sent[0].UserID)
+	assert.Equal(t, "bot", chat.sent[0].Username)
+	assert.Equal(t, []string{"vip"}, chat.sent[0].Badges)
+	assert.Equal(t, chat.sent, chat.notified)
+}
+
+func TestStreamMessagesReplaysThenDedupes(t *testing.T) {
+	streamID := uuid.New()
+	message := func(seq int64) *entity.ChatMessage {
+		msg := entity.NewChatMessage(streamID, uuid.New(), "viewer", fmt.Sprintf("message %d", seq))
+		msg.Seq = seq
+		return msg
+	}
+
+	// Сообщение 4 пришло живым, пока шел повтор: второй раз оно не отправляется
+	live := &stubLive{messages: make(chan *entity.ChatMessage, 2)}
+	live.messages <- message(4)
+	live.messages <- message(5)
+	chat := &stubChat{replay: &entity.MessageReplay{Messages: []*entity.ChatMessage{message(3), message(4)}, LastSeq: 4}}
+	h := newHandler(chat, live)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := &recordingStream{ctx: ctx}
+	done := make(chan error, 1)
+	go func() {
+		done <- h.StreamMessages(&proto.StreamMessageRequest{StreamId: streamID.String(), LastSeq: 2}, stream)
+	}()
+
+	require.Eventually(t, func() bool { return len(stream.seqs()) == 3 }, time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+	assert.Equal(t, []int64{3, 4, 5}, stream.seqs())
+	assert.Equal(t, int64(2), chat.replayedAt)
+	assert.True(t, live.unsubscribed)
+
+	err := h.StreamMessages(&proto.StreamMessageRequest{StreamId: streamID.String(), LastSeq: -1}, stream)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestStreamMessagesLagging(t *testing.T) {
+	// Закрытый канал означает, что подписчик отстал и подписка снята
+	live := &stubLive{messages: make(chan *entity.ChatMessage)}
+	close(live.messages)
+	h := newHandler(&stubChat{replay: &entity.MessageReplay{}}, live)
+
+	stream := &recordingStream{ctx: context.Background()}
+	err := h.StreamMessages(&proto.StreamMessageRequest{StreamId: uuid.NewString()}, stream)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.True(t, live.unsubscribed)
+}
+
+func TestGetChatHistoryCursors(t *testing.T) {
+	ctx := context.Background()
+	streamID, cursorID := uuid.New(), uuid.New()
+	msg := entity.NewChatMessage(streamID, uuid.New(), "viewer", "hello")
+	msg.Seq = 7
+
+	chat := &stubChat{page: &entity.MessagePage{Messages: []*entity.ChatMessage{msg}, HasMore: true}}
+	h := newHandler(chat, nil)
+
+	// before — ID сообщения, after — время в RFC 3339
+	resp, err := h.GetChatHistory(ctx, &proto.ChatHistoryRequest{
+		StreamId: streamID.String(),
+		Limit:    30,
+		Before:   cursorID.String(),
+		After:    "2024-05-01T12:00:00+03:00",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, chat.query)
+	assert.Equal(t, 30, chat.query.Limit)
+	assert.Equal(t, &entity.MessageCursor{MessageID: cursorID}, chat.query.Before)
+	assert.Equal(t, &entity.MessageCursor{Time: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)}, chat.query.After)
+	assert.True(t, resp.HasMore)
+	require.Len(t, resp.Messages, 1)
+	assert.Equal(t, msg.ID.String(), resp.Messages[0].Id)
+	assert.Equal(t, int64(7), resp.Messages[0].Seq)
+
+	// Без курсоров запрашивается последняя страница
+	_, err = h.GetChatHistory(ctx, &proto.ChatHistoryRequest{StreamId: streamID.String()})
+	require.NoError(t, err)
+	assert.Nil(t, chat.query.Before)
+	assert.Nil(t, chat.query.After)
+
+	for _, req := range []*proto.ChatHistoryRequest{
+		{StreamId: "stream"},
+		{StreamId: streamID.String(), Before: "yesterday"},
+		{StreamId: streamID.String(), After: "12345"},
+	} {
+		_, err := h.GetChatHistory(ctx, req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), req.String())
+	}
+
+	// Курсор на несуществующее сообщение — ошибка клиента, сбой хранилища — внутренняя
+	chat.pageErr = repository.ErrMessageNotFound
+	_, err = h.GetChatHistory(ctx, &proto.ChatHistoryRequest{StreamId: streamID.String(), Before: uuid.NewString()})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	chat.pageErr = errors.New("mongo: timeout")
+	_, err = h.GetChatHistory(ctx, &proto.ChatHistoryRequest{StreamId: streamID.String()})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
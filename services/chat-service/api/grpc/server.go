@@ -0,0 +1,54 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"net"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/proto"
+	"google.golang.org/grpc"
+)
+
+// Server обслуживает gRPC API чата
+type Server struct {
+	server *grpc.Server
+	logger *slog.Logger
+}
+
+// NewServer создаёт gRPC сервер и регистрирует в нём хендлер чата
+func NewServer(handler *ChatHandler, logger *slog.Logger) *Server {
+	srv := &Server{
+		server: grpc.NewServer(),
+		logger: logger,
+	}
+	proto.RegisterChatServiceServer(srv.server, handler)
+	return srv
+}
+
+// Run запускает gRPC сервер на указанном адресе
+func (s *Server) Run(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		s.logger.Error("Failed to listen", "error", err)
+		return err
+	}
+	s.logger.Info("gRPC server is running", "address", addr)
+	return s.server.Serve(lis)
+}
+
+// Shutdown останавливает сервер, дожидаясь завершения активных запросов
+func (s *Server) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
@@ -11,10 +11,18 @@ import (
 
 	"github.com/exPriceD/Streaming-platform/config"
 	"github.com/exPriceD/Streaming-platform/pkg/logger"
+	chatgrpc "github.com/exPriceD/Streaming-platform/services/chat-service/api/grpc"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/auth"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/cache"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/clients"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/commands"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/events"
 	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/handler"
 	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
 	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/storage"
 	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/websocket"
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -56,20 +64,108 @@ func main() {
 
 	// Инициализация репозитория
 	repo := repository.NewChatRepository(mongoDB, pgDB)
+	if err := repo.EnsureIndexes(context.Background()); err != nil {
+		log.Error("Failed to create MongoDB indexes", "error", err)
+		os.Exit(1)
+	}
+
+	// Клиент streaming-service: владелец стрима — бродкастер в чате
+	streamingClient, err := clients.NewStreamingClient(cfg.Streaming.Address)
+	if err != nil {
+		log.Error("Failed to create streaming-service client", "error", err)
+		os.Exit(1)
+	}
+	defer streamingClient.Close()
+
+	// Инициализация сервиса и фоновой записи сообщений
+	chatService := service.NewChatService(repo, cache.NewRedisCache(redisClient), events.NewPublisher(redisClient), streamingClient)
+	go chatService.Run()
+
+	// Администраторы платформы
+	admins := make([]uuid.UUID, 0, len(cfg.Admins))
+	for _, raw := range cfg.Admins {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			log.Error("Invalid admin user ID in config", "value", raw, "error", err)
+			os.Exit(1)
+		}
+		admins = append(admins, id)
+	}
+	chatService.SetAdmins(admins)
+
+	// Файловое хранилище изображений эмоутов
+	staticStorage, err := storage.NewLocalStorage(cfg.Storage.Dir, cfg.Storage.BaseURL)
+	if err != nil {
+		log.Error("Failed to initialize file storage", "dir", cfg.Storage.Dir, "error", err)
+		os.Exit(1)
+	}
+	chatService.SetEmoteStorage(staticStorage)
 
-	// Инициализация сервиса
-	chatService := service.NewChatService(repo)
+	// Восстановление кеша банов и таймаутов из PostgreSQL
+	if err := chatService.RestoreBans(context.Background()); err != nil {
+		log.Error("Failed to restore bans cache", "error", err)
+	}
+
+	// Опросы, итоги которых не подведены до перезапуска
+	if err := chatService.RestorePolls(context.Background()); err != nil {
+		log.Error("Failed to restore active polls", "error", err)
+	}
 
 	// Инициализация WebSocket сервера
-	wsServer := websocket.NewChatServer(cfg.WebSocket.JWTSecret, redisClient)
+	wsServer := websocket.NewChatServer(cfg.WebSocket, redisClient, chatService)
+	wsServer.SetCommands(commands.NewRegistry(chatService, commands.DefaultCommands()...))
 
 	// Инициализация HTTP обработчиков
-	chatHandler := handler.NewChatHandler(chatService)
+	chatHandler := handler.NewChatHandler(chatService, auth.NewTokenValidator(cfg.WebSocket.JWTSecret))
 
 	// Настройка маршрутов HTTP
 	http.HandleFunc("/messages", chatHandler.GetMessages)
+	http.HandleFunc("POST /bans", chatHandler.BanUser)
+	http.HandleFunc("GET /bans", chatHandler.ListBans)
+	http.HandleFunc("DELETE /bans", chatHandler.UnbanUser)
+	http.HandleFunc("GET /messages/vod", chatHandler.GetVODChat)
+	http.HandleFunc("GET /messages/export", chatHandler.ExportChat)
+	http.HandleFunc("GET /messages/search", chatHandler.SearchMessages)
+	http.HandleFunc("DELETE /messages/{message_id}", chatHandler.DeleteMessage)
+	http.HandleFunc("POST /messages/clear", chatHandler.ClearUserMessages)
+	http.HandleFunc("GET /messages/{message_id}/reactions", chatHandler.GetReactions)
+	http.HandleFunc("PUT /messages/{message_id}/reactions", chatHandler.React)
+	http.HandleFunc("DELETE /messages/{message_id}/reactions", chatHandler.RemoveReaction)
+	http.HandleFunc("POST /roles", chatHandler.GrantRole)
+	http.HandleFunc("GET /roles", chatHandler.ListRoles)
+	http.HandleFunc("DELETE /roles", chatHandler.RevokeRole)
+	http.HandleFunc("GET /modes", chatHandler.GetChatModes)
+	http.HandleFunc("PUT /modes", chatHandler.SetChatModes)
+	http.HandleFunc("GET /automod", chatHandler.GetAutoModRules)
+	http.HandleFunc("PUT /automod/settings", chatHandler.UpdateAutoModSettings)
+	http.HandleFunc("POST /automod/terms", chatHandler.AddBlockedTerm)
+	http.HandleFunc("DELETE /automod/terms/{term_id}", chatHandler.RemoveBlockedTerm)
+	http.HandleFunc("GET /automod/held", chatHandler.ListHeldMessages)
+	http.HandleFunc("POST /automod/held/{message_id}/approve", chatHandler.ApproveHeldMessage)
+	http.HandleFunc("POST /automod/held/{message_id}/deny", chatHandler.DenyHeldMessage)
+	http.HandleFunc("GET /chatters", chatHandler.ListChatters)
+	http.HandleFunc("POST /polls", chatHandler.CreatePoll)
+	http.HandleFunc("GET /polls", chatHandler.ListPolls)
+	http.HandleFunc("POST /polls/{poll_id}/votes", chatHandler.VotePoll)
+	http.HandleFunc("POST /polls/{poll_id}/end", chatHandler.EndPoll)
+	http.HandleFunc("GET /whispers", chatHandler.ListConversations)
+	http.HandleFunc("GET /whispers/{user_id}", chatHandler.GetWhispers)
+	http.HandleFunc("POST /blocks", chatHandler.BlockUser)
+	http.HandleFunc("GET /blocks", chatHandler.ListBlocks)
+	http.HandleFunc("DELETE /blocks/{user_id}", chatHandler.UnblockUser)
+	http.HandleFunc("GET /pin", chatHandler.GetPinnedMessage)
+	http.HandleFunc("PUT /pin", chatHandler.PinMessage)
+	http.HandleFunc("DELETE /pin", chatHandler.UnpinMessage)
+	http.HandleFunc("POST /announcements", chatHandler.Announce)
+	http.HandleFunc("GET /emotes", chatHandler.ListEmotes)
+	http.HandleFunc("POST /emotes", chatHandler.UploadEmote)
+	http.HandleFunc("DELETE /emotes/{emote_id}", chatHandler.DeleteEmote)
+	http.Handle("GET /static/", http.StripPrefix("/static", staticStorage.Handler()))
 	http.HandleFunc("/ws", wsServer.HandleConnection)
 
+	// Инициализация gRPC сервера
+	grpcServer := chatgrpc.NewServer(chatgrpc.NewChatHandler(chatService, wsServer, auth.NewTokenValidator(cfg.WebSocket.JWTSecret)), log)
+
 	// Запуск HTTP сервера
 	server := &http.Server{
 		Addr:    fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port),
@@ -84,8 +180,24 @@ func main() {
 		}
 	}()
 
+	// Запуск gRPC сервера
+	go func() {
+		if err := grpcServer.Run(fmt.Sprintf("%s:%d", cfg.GRPC.Host, cfg.GRPC.Port)); err != nil {
+			log.Error("Failed to start gRPC server", "error", err)
+			os.Exit(1)
+		}
+	}()
+
 	// Запуск рассылки сообщений через WebSocket
-	go wsServer.StartBroadcast(context.Background())
+	broadcastCtx, stopBroadcast := context.WithCancel(context.Background())
+	defer stopBroadcast()
+	go wsServer.StartBroadcast(broadcastCtx)
+
+	// Рассылка промежуточных итогов опросов и завершение истекших
+	go chatService.RunPolls(broadcastCtx)
+
+	// Рассылка счетчиков реакций и их запись в MongoDB
+	go chatService.RunReactions(broadcastCtx)
 
 	// Ожидание сигналов для graceful shutdown
 	quit := make(chan os.Signal, 1)
@@ -102,6 +214,16 @@ func main() {
 		log.Error("Server shutdown failed", "error", err)
 	}
 
+	if err := grpcServer.Shutdown(ctx); err != nil {
+		log.Error("gRPC server shutdown failed", "error", err)
+	}
+	stopBroadcast()
+
+	// Сброс в MongoDB сообщений, принятых до остановки
+	if err := chatService.Close(ctx); err != nil {
+		log.Error("Failed to flush pending messages", "error", err)
+	}
+
 	log.Info("Server exited properly")
 }
 
@@ -109,7 +231,7 @@ func main() {
 func connectPostgres(cfg config.DBConfig) (*gorm.DB, error) {
 	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode)
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
 	}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims содержит данные пользователя из access-токена auth-service
+type Claims struct {
+	UserID   uuid.UUID
+	Username string
+}
+
+// TokenValidator проверяет JWT токены, подписанные общим секретом
+type TokenValidator struct {
+	secret []byte
+}
+
+// NewTokenValidator создает валидатор токенов
+func NewTokenValidator(secret string) *TokenValidator {
+	return &TokenValidator{secret: []byte(secret)}
+}
+
+// Validate проверяет токен и извлекает из него данные пользователя
+func (v *TokenValidator) Validate(tokenString string) (*Claims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return v.secret, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mapClaims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	rawUserID, _ := mapClaims["user_id"].(string)
+	userID, err := uuid.Parse(rawUserID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	username, _ := mapClaims["username"].(string)
+	return &Claims{UserID: userID, Username: username}, nil
+}
+
+// TokenFromRequest извлекает токен из заголовка Authorization или параметра token
+func TokenFromRequest(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		return strings.TrimPrefix(header, "Bearer ")
+	}
+	return r.URL.Query().Get("token")
+}
@@ -0,0 +1,259 @@
+package automod
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/google/uuid"
+)
+
+// minCapsLetters — короткие сообщения вроде "GG" или "LOL" не считаются капсом
+const minCapsLetters = 8
+
+// DefaultFilters возвращает стандартную цепочку фильтров AutoMod
+func DefaultFilters(recent RecentMessages) []Filter {
+	return []Filter{
+		NewTermFilter(),
+		LinkFilter{},
+		CapsFilter{},
+		RepeatFilter{},
+		DuplicateFilter{Recent: recent},
+	}
+}
+
+// TermFilter проверяет сообщение по списку запрещенных выражений чата
+type TermFilter struct {
+	mu       sync.Mutex
+	compiled map[string]*regexp.Regexp
+}
+
+// NewTermFilter создает фильтр запрещенных выражений
+func NewTermFilter() *TermFilter {
+	return &TermFilter{compiled: make(map[string]*regexp.Regexp)}
+}
+
+func (f *TermFilter) Name() string { return "blocked_terms" }
+
+func (f *TermFilter) Check(_ context.Context, in *Input) (Verdict, error) {
+	var result Verdict
+	for _, term := range in.Rules.Terms {
+		re, err := f.regexp(term.Pattern)
+		if err != nil {
+			return Verdict{}, err
+		}
+
+		matches := wordMatches(re, in.Message.Content)
+		if len(matches) == 0 {
+			continue
+		}
+		if term.Action == entity.AutoModMask {
+			in.Message.Content = maskRanges(in.Message.Content, matches)
+		}
+		if severity[term.Action] > severity[result.Action] {
+			result = Verdict{Action: term.Action, Filter: f.Name(), Reason: fmt.Sprintf("blocked term %q", term.Pattern)}
+		}
+	}
+	return result, nil
+}
+
+// regexp компилирует шаблон с * в регулярное выражение и кеширует его
+func (f *TermFilter) regexp(pattern string) (*regexp.Regexp, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if re, ok := f.compiled[pattern]; ok {
+		return re, nil
+	}
+	expr := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, `[\p{L}\p{N}]*`)
+	re, err := regexp.Compile("(?i)" + expr)
+	if err != nil {
+		return nil, err
+	}
+	f.compiled[pattern] = re
+	return re, nil
+}
+
+// wordMatches возвращает совпадения, которые не являются частью более длинного слова
+func wordMatches(re *regexp.Regexp, content string) [][]int {
+	var result [][]int
+	for _, m := range re.FindAllStringIndex(content, -1) {
+		if m[0] == m[1] {
+			continue
+		}
+		if isWordRune(lastRune(content[:m[0]])) || isWordRune(firstRune(content[m[1]:])) {
+			continue
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+// LinkFilter блокирует ссылки на домены вне allowlist чата
+type LinkFilter struct{}
+
+// linkPattern находит ссылки с протоколом и без него, например example.com/path
+var linkPattern = regexp.MustCompile(`(?i)(https?://)?((?:[a-z0-9-]+\.)+[a-z]{2,})(?::\d+)?(/\S*)?`)
+
+func (LinkFilter) Name() string { return "links" }
+
+func (f LinkFilter) Check(_ context.Context, in *Input) (Verdict, error) {
+	settings := in.Rules.Settings
+	if !settings.BlockLinks {
+		return Verdict{}, nil
+	}
+
+	var blocked [][]int
+	for _, m := range linkPattern.FindAllStringSubmatchIndex(in.Message.Content, -1) {
+		host := strings.ToLower(in.Message.Content[m[4]:m[5]])
+		if !domainAllowed(host, settings.LinkAllowlist) {
+			blocked = append(blocked, m[:2])
+		}
+	}
+	if len(blocked) == 0 {
+		return Verdict{}, nil
+	}
+
+	if settings.LinkAction == entity.AutoModMask {
+		in.Message.Content = maskRanges(in.Message.Content, blocked)
+	}
+	return Verdict{Action: settings.LinkAction, Filter: f.Name(), Reason: "links are not allowed"}, nil
+}
+
+// domainAllowed проверяет домен и его родительские домены по allowlist
+func domainAllowed(host string, allowlist []string) bool {
+	for _, allowed := range allowlist {
+		allowed = strings.ToLower(strings.TrimPrefix(allowed, "."))
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// CapsFilter отклоняет сообщения, написанные преимущественно заглавными буквами
+type CapsFilter struct{}
+
+func (CapsFilter) Name() string { return "caps" }
+
+func (f CapsFilter) Check(_ context.Context, in *Input) (Verdict, error) {
+	limit := in.Rules.Settings.CapsPercent
+	if limit <= 0 {
+		return Verdict{}, nil
+	}
+
+	letters, upper := 0, 0
+	for _, r := range in.Message.Content {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	if letters < minCapsLetters || upper*100 <= letters*limit {
+		return Verdict{}, nil
+	}
+	return Verdict{Action: entity.AutoModReject, Filter: f.Name(), Reason: "too many capital letters"}, nil
+}
+
+// RepeatFilter отклоняет сообщения с длинными повторами одного символа
+type RepeatFilter struct{}
+
+func (RepeatFilter) Name() string { return "repeated_chars" }
+
+func (f RepeatFilter) Check(_ context.Context, in *Input) (Verdict, error) {
+	limit := in.Rules.Settings.MaxRepeatedChars
+	if limit <= 0 {
+		return Verdict{}, nil
+	}
+
+	var prev rune
+	run := 0
+	for _, r := range in.Message.Content {
+		if r == prev {
+			run++
+		} else {
+			prev, run = r, 1
+		}
+		if run > limit && !unicode.IsSpace(r) {
+			return Verdict{Action: entity.AutoModReject, Filter: f.Name(), Reason: "too many repeated characters"}, nil
+		}
+	}
+	return Verdict{}, nil
+}
+
+// RecentMessages запоминает недавние сообщения пользователей для поиска повторов
+type RecentMessages interface {
+	// SeenRecently запоминает отпечаток сообщения и сообщает, встречался ли он в окне window
+	SeenRecently(ctx context.Context, streamID, userID uuid.UUID, fingerprint string, window time.Duration) (bool, error)
+}
+
+// DuplicateFilter отклоняет повтор недавнего сообщения того же пользователя
+type DuplicateFilter struct {
+	Recent RecentMessages
+}
+
+func (DuplicateFilter) Name() string { return "duplicates" }
+
+func (f DuplicateFilter) Check(ctx context.Context, in *Input) (Verdict, error) {
+	window := time.Duration(in.Rules.Settings.DuplicateWindowSeconds) * time.Second
+	if window <= 0 || f.Recent == nil {
+		return Verdict{}, nil
+	}
+
+	msg := in.Message
+	seen, err := f.Recent.SeenRecently(ctx, msg.StreamID, msg.UserID, fingerprint(msg.Content), window)
+	if err != nil {
+		return Verdict{}, err
+	}
+	if !seen {
+		return Verdict{}, nil
+	}
+	return Verdict{Action: entity.AutoModReject, Filter: f.Name(), Reason: "duplicate message"}, nil
+}
+
+// fingerprint нормализует текст, чтобы повтор не обходился регистром и пробелами
+func fingerprint(content string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(content), " "))
+	sum := sha1.Sum([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// maskRanges заменяет указанные байтовые диапазоны звездочками по числу символов
+func maskRanges(content string, ranges [][]int) string {
+	var b strings.Builder
+	last := 0
+	for _, r := range ranges {
+		if r[0] < last {
+			continue
+		}
+		b.WriteString(content[last:r[0]])
+		b.WriteString(strings.Repeat("*", len([]rune(content[r[0]:r[1]]))))
+		last = r[1]
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+func lastRune(s string) rune {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r
+}
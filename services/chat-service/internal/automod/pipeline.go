@@ -0,0 +1,64 @@
+// Package automod проверяет сообщения чата цепочкой фильтров AutoMod
+package automod
+
+import (
+	"context"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+)
+
+// Verdict — решение фильтра. Пустое действие означает, что сообщение пропущено
+type Verdict struct {
+	Action entity.AutoModAction
+	Filter string // Фильтр, вынесший решение
+	Reason string
+}
+
+// severity упорядочивает действия: более строгое побеждает
+var severity = map[entity.AutoModAction]int{
+	"":                   0,
+	entity.AutoModMask:   1,
+	entity.AutoModHold:   2,
+	entity.AutoModReject: 3,
+}
+
+// Input — сообщение и правила чата, по которым оно проверяется
+type Input struct {
+	Message *entity.ChatMessage
+	Rules   *entity.AutoModRules
+}
+
+// Filter — звено цепочки AutoMod. Фильтр с действием mask сам изменяет
+// текст сообщения и возвращает вердикт для журнала
+type Filter interface {
+	Name() string
+	Check(ctx context.Context, in *Input) (Verdict, error)
+}
+
+// Pipeline последовательно применяет фильтры к сообщению
+type Pipeline struct {
+	filters []Filter
+}
+
+// NewPipeline создает цепочку из фильтров в порядке их применения
+func NewPipeline(filters ...Filter) *Pipeline {
+	return &Pipeline{filters: filters}
+}
+
+// Run возвращает самое строгое решение фильтров. Отклонение прерывает цепочку
+func (p *Pipeline) Run(ctx context.Context, in *Input) (Verdict, error) {
+	var result Verdict
+	for _, filter := range p.filters {
+		verdict, err := filter.Check(ctx, in)
+		if err != nil {
+			return Verdict{}, err
+		}
+		if severity[verdict.Action] > severity[result.Action] {
+			result = verdict
+		}
+		if result.Action == entity.AutoModReject {
+			break
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,72 @@
+package automod_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/automod"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRecent запоминает отпечатки сообщений без учета окна
+type memoryRecent map[string]bool
+
+func (m memoryRecent) SeenRecently(_ context.Context, _, _ uuid.UUID, fp string, _ time.Duration) (bool, error) {
+	seen := m[fp]
+	m[fp] = true
+	return seen, nil
+}
+
+func TestPipeline(t *testing.T) {
+	streamID := uuid.New()
+	rules := &entity.AutoModRules{
+		Settings: entity.DefaultAutoModSettings(streamID),
+		Terms: []*entity.BlockedTerm{
+			{Pattern: "darn*", Action: entity.AutoModMask},
+			{Pattern: "scam", Action: entity.AutoModHold},
+			{Pattern: "badword", Action: entity.AutoModReject},
+		},
+	}
+	rules.Settings.BlockLinks = true
+	rules.Settings.LinkAllowlist = []string{"example.com"}
+
+	pipeline := automod.NewPipeline(automod.DefaultFilters(memoryRecent{})...)
+
+	tests := []struct {
+		name    string
+		content string
+		action  entity.AutoModAction
+		result  string
+	}{
+		{"clean", "hello chat", "", "hello chat"},
+		{"wildcard mask", "Darnit, again", entity.AutoModMask, "******, again"},
+		{"not part of a word", "scammer-free stream", "", "scammer-free stream"},
+		{"hold", "is this a scam?", entity.AutoModHold, "is this a scam?"},
+		{"reject wins over mask", "darn badword", entity.AutoModReject, "**** badword"},
+		{"allowed link", "see clips.example.com/abc", "", "see clips.example.com/abc"},
+		{"blocked link", "visit evil.io now", entity.AutoModReject, "visit evil.io now"},
+		{"caps", "WHY IS EVERYONE SHOUTING", entity.AutoModReject, "WHY IS EVERYONE SHOUTING"},
+		{"short caps", "GG WP", "", "GG WP"},
+		{"repeated", "nooooooooooooo", entity.AutoModReject, "nooooooooooooo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := entity.NewChatMessage(streamID, uuid.New(), "viewer", tt.content)
+			verdict, err := pipeline.Run(context.Background(), &automod.Input{Message: msg, Rules: rules})
+			require.NoError(t, err)
+			assert.Equal(t, tt.action, verdict.Action)
+			assert.Equal(t, tt.result, msg.Content)
+		})
+	}
+
+	// Повтор того же текста отклоняется
+	msg := entity.NewChatMessage(streamID, uuid.New(), "viewer", "hello  CHAT")
+	verdict, err := pipeline.Run(context.Background(), &automod.Input{Message: msg, Rules: rules})
+	require.NoError(t, err)
+	assert.Equal(t, entity.AutoModReject, verdict.Action)
+	assert.Equal(t, "duplicates", verdict.Filter)
+}
@@ -3,7 +3,10 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
@@ -16,14 +19,8 @@ type RedisCache struct {
 	client *redis.Client
 }
 
-// NewRedisCache создает новый объект кеша
-func NewRedisCache(addr, password string, db int) *RedisCache {
-	client := redis.NewClient(&redis.Options{
-		Addr:     addr,
-		Password: password,
-		DB:       db,
-	})
-
+// NewRedisCache создает новый объект кеша поверх существующего клиента
+func NewRedisCache(client *redis.Client) *RedisCache {
 	return &RedisCache{client: client}
 }
 
@@ -54,14 +51,943 @@ func (r *RedisCache) GetMessages(ctx context.Context, streamID uuid.UUID) ([]*en
 	return messages, nil
 }
 
-// BanUser кеширует информацию о бане пользователя
-func (r *RedisCache) BanUser(ctx context.Context, streamID, userID uuid.UUID) error {
-	key := fmt.Sprintf("chat:%s:banned", streamID)
-	return r.client.SAdd(ctx, key, userID.String()).Err()
+// banKey возвращает ключ блокировки пользователя в чате стрима
+func banKey(streamID, userID uuid.UUID) string {
+	return fmt.Sprintf("chat:%s:ban:%s", streamID, userID)
+}
+
+// BanUser кеширует бан или таймаут пользователя. Таймаут хранится с TTL
+// и снимается Redis автоматически
+func (r *RedisCache) BanUser(ctx context.Context, ban *entity.ChatBan) error {
+	var ttl time.Duration
+	if ban.ExpiresAt != nil {
+		ttl = time.Until(*ban.ExpiresAt)
+		if ttl <= 0 {
+			return nil
+		}
+	}
+
+	data, err := json.Marshal(ban)
+	if err != nil {
+		return err
+	}
+
+	return r.client.Set(ctx, banKey(ban.StreamID, ban.UserID), data, ttl).Err()
+}
+
+// UnbanUser удаляет блокировку пользователя из кеша
+func (r *RedisCache) UnbanUser(ctx context.Context, streamID, userID uuid.UUID) error {
+	return r.client.Del(ctx, banKey(streamID, userID)).Err()
+}
+
+// GetBan возвращает действующую блокировку пользователя или nil
+func (r *RedisCache) GetBan(ctx context.Context, streamID, userID uuid.UUID) (*entity.ChatBan, error) {
+	data, err := r.client.Get(ctx, banKey(streamID, userID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ban entity.ChatBan
+	if err := json.Unmarshal(data, &ban); err != nil {
+		return nil, err
+	}
+	return &ban, nil
 }
 
-// IsUserBanned проверяет, забанен ли пользователь
+// IsUserBanned проверяет, забанен ли пользователь или находится ли он в таймауте
 func (r *RedisCache) IsUserBanned(ctx context.Context, streamID, userID uuid.UUID) (bool, error) {
-	key := fmt.Sprintf("chat:%s:banned", streamID)
-	return r.client.SIsMember(ctx, key, userID.String()).Result()
+	n, err := r.client.Exists(ctx, banKey(streamID, userID)).Result()
+	return n > 0, err
+}
+
+// roleTTL ограничивает время жизни закешированных ролей, чтобы смена
+// владельца стрима подхватывалась без явной инвалидации
+const roleTTL = 5 * time.Minute
+
+// roleKey возвращает ключ роли пользователя в чате стрима
+func roleKey(streamID, userID uuid.UUID) string {
+	return fmt.Sprintf("chat:%s:role:%s", streamID, userID)
+}
+
+// SetRole кеширует итоговую роль пользователя в чате стрима
+func (r *RedisCache) SetRole(ctx context.Context, streamID, userID uuid.UUID, role entity.Role) error {
+	return r.client.Set(ctx, roleKey(streamID, userID), string(role), roleTTL).Err()
+}
+
+// GetRole возвращает закешированную роль пользователя или пустую строку
+func (r *RedisCache) GetRole(ctx context.Context, streamID, userID uuid.UUID) (entity.Role, error) {
+	role, err := r.client.Get(ctx, roleKey(streamID, userID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return entity.Role(role), nil
+}
+
+// InvalidateRole удаляет роль пользователя из кеша
+func (r *RedisCache) InvalidateRole(ctx context.Context, streamID, userID uuid.UUID) error {
+	return r.client.Del(ctx, roleKey(streamID, userID)).Err()
+}
+
+// modesKey возвращает ключ режимов чата стрима
+func modesKey(streamID uuid.UUID) string {
+	return fmt.Sprintf("chat:%s:modes", streamID)
+}
+
+// SetModes кеширует режимы чата стрима
+func (r *RedisCache) SetModes(ctx context.Context, streamID uuid.UUID, modes entity.ChatModes) error {
+	data, err := json.Marshal(modes)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, modesKey(streamID), data, 0).Err()
+}
+
+// GetModes возвращает закешированные режимы чата или nil, если их нет в кеше
+func (r *RedisCache) GetModes(ctx context.Context, streamID uuid.UUID) (*entity.ChatModes, error) {
+	data, err := r.client.Get(ctx, modesKey(streamID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var modes entity.ChatModes
+	if err := json.Unmarshal(data, &modes); err != nil {
+		return nil, err
+	}
+	return &modes, nil
+}
+
+// pinKey возвращает ключ закрепленного сообщения чата стрима
+func pinKey(streamID uuid.UUID) string {
+	return fmt.Sprintf("chat:%s:pin", streamID)
+}
+
+// SetPin кеширует закрепленное сообщение до истечения его срока. Отсутствие
+// закрепленного сообщения тоже кешируется, чтобы не читать PostgreSQL
+// при каждом подключении
+func (r *RedisCache) SetPin(ctx context.Context, streamID uuid.UUID, pin *entity.PinnedMessage) error {
+	var ttl time.Duration
+	if pin != nil && pin.ExpiresAt != nil {
+		ttl = time.Until(*pin.ExpiresAt)
+		if ttl <= 0 {
+			return r.client.Del(ctx, pinKey(streamID)).Err()
+		}
+	}
+
+	data, err := json.Marshal(pin)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, pinKey(streamID), data, ttl).Err()
+}
+
+// GetPin возвращает закешированное закрепленное сообщение. Флаг cached
+// равен false, если кеш не заполнен; nil при cached = true означает,
+// что закрепленного сообщения нет
+func (r *RedisCache) GetPin(ctx context.Context, streamID uuid.UUID) (pin *entity.PinnedMessage, cached bool, err error) {
+	data, err := r.client.Get(ctx, pinKey(streamID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := json.Unmarshal(data, &pin); err != nil {
+		return nil, false, err
+	}
+	return pin, true, nil
+}
+
+// emotesKey возвращает ключ эмоутов канала; для нулевого streamID — глобального набора
+func emotesKey(streamID uuid.UUID) string {
+	return fmt.Sprintf("chat:%s:emotes", streamID)
+}
+
+// SetEmotes кеширует эмоуты канала или глобального набора. Пустой набор
+// тоже кешируется: он проверяется при каждом сообщении
+func (r *RedisCache) SetEmotes(ctx context.Context, streamID uuid.UUID, emotes []*entity.Emote) error {
+	data, err := json.Marshal(emotes)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, emotesKey(streamID), data, time.Hour).Err()
+}
+
+// GetEmotes возвращает закешированные эмоуты. Флаг cached равен false,
+// если кеш не заполнен
+func (r *RedisCache) GetEmotes(ctx context.Context, streamID uuid.UUID) (emotes []*entity.Emote, cached bool, err error) {
+	data, err := r.client.Get(ctx, emotesKey(streamID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := json.Unmarshal(data, &emotes); err != nil {
+		return nil, false, err
+	}
+	return emotes, true, nil
+}
+
+// InvalidateEmotes удаляет эмоуты из кеша после изменения набора
+func (r *RedisCache) InvalidateEmotes(ctx context.Context, streamID uuid.UUID) error {
+	return r.client.Del(ctx, emotesKey(streamID)).Err()
+}
+
+// slowModeKey возвращает ключ интервала slow mode пользователя в чате стрима
+func slowModeKey(streamID, userID uuid.UUID) string {
+	return fmt.Sprintf("chat:%s:slow:%s", streamID, userID)
+}
+
+// SlowModeRemaining возвращает, сколько пользователю осталось ждать до
+// следующего сообщения в slow mode, не начиная новый интервал
+func (r *RedisCache) SlowModeRemaining(ctx context.Context, streamID, userID uuid.UUID) (time.Duration, error) {
+	remaining, err := r.client.PTTL(ctx, slowModeKey(streamID, userID)).Result()
+	if err != nil || remaining < 0 {
+		return 0, err
+	}
+	return remaining, nil
+}
+
+// TouchSlowMode отмечает сообщение пользователя в slow mode. Если интервал
+// с прошлого сообщения еще не истек, возвращает оставшееся время ожидания
+func (r *RedisCache) TouchSlowMode(ctx context.Context, streamID, userID uuid.UUID, interval time.Duration) (time.Duration, error) {
+	key := slowModeKey(streamID, userID)
+	ok, err := r.client.SetNX(ctx, key, 1, interval).Result()
+	if err != nil || ok {
+		return 0, err
+	}
+
+	remaining, err := r.client.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if remaining <= 0 {
+		// Ключ истек между SETNX и PTTL
+		return 0, r.client.Set(ctx, key, 1, interval).Err()
+	}
+	return remaining, nil
+}
+
+// automodKey возвращает ключ правил AutoMod чата стрима
+func automodKey(streamID uuid.UUID) string {
+	return fmt.Sprintf("chat:%s:automod", streamID)
+}
+
+// SetAutoModRules кеширует правила AutoMod чата стрима
+func (r *RedisCache) SetAutoModRules(ctx context.Context, streamID uuid.UUID, rules *entity.AutoModRules) error {
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, automodKey(streamID), data, time.Hour).Err()
+}
+
+// GetAutoModRules возвращает закешированные правила AutoMod или nil
+func (r *RedisCache) GetAutoModRules(ctx context.Context, streamID uuid.UUID) (*entity.AutoModRules, error) {
+	data, err := r.client.Get(ctx, automodKey(streamID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rules entity.AutoModRules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+// InvalidateAutoModRules удаляет правила AutoMod из кеша после их изменения
+func (r *RedisCache) InvalidateAutoModRules(ctx context.Context, streamID uuid.UUID) error {
+	return r.client.Del(ctx, automodKey(streamID)).Err()
+}
+
+// SeenRecently запоминает отпечаток сообщения пользователя на время window
+// и сообщает, было ли такое же сообщение в этом окне
+func (r *RedisCache) SeenRecently(ctx context.Context, streamID, userID uuid.UUID, fingerprint string, window time.Duration) (bool, error) {
+	key := fmt.Sprintf("chat:%s:recent:%s:%s", streamID, userID, fingerprint)
+	ok, err := r.client.SetNX(ctx, key, 1, window).Result()
+	return !ok, err
+}
+
+// heldKey возвращает ключ сообщений, задержанных AutoMod в чате стрима
+func heldKey(streamID uuid.UUID) string {
+	return fmt.Sprintf("chat:%s:held", streamID)
+}
+
+// heldTTL — сколько задержанные сообщения ждут решения модератора
+const heldTTL = 24 * time.Hour
+
+// HoldMessage сохраняет сообщение до решения модератора
+func (r *RedisCache) HoldMessage(ctx context.Context, msg *entity.HeldMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	key := heldKey(msg.Message.StreamID)
+	pipe := r.client.TxPipeline()
+	pipe.HSet(ctx, key, msg.Message.ID.String(), data)
+	pipe.Expire(ctx, key, heldTTL)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// ListHeldMessages возвращает сообщения, ожидающие решения модератора
+func (r *RedisCache) ListHeldMessages(ctx context.Context, streamID uuid.UUID) ([]*entity.HeldMessage, error) {
+	values, err := r.client.HVals(ctx, heldKey(streamID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	held := make([]*entity.HeldMessage, 0, len(values))
+	for _, value := range values {
+		var msg entity.HeldMessage
+		if err := json.Unmarshal([]byte(value), &msg); err != nil {
+			return nil, err
+		}
+		held = append(held, &msg)
+	}
+	return held, nil
+}
+
+// TakeHeldMessage извлекает задержанное сообщение, чтобы решение по нему
+// принял только один модератор. Возвращает nil, если сообщения нет
+func (r *RedisCache) TakeHeldMessage(ctx context.Context, streamID, messageID uuid.UUID) (*entity.HeldMessage, error) {
+	key := heldKey(streamID)
+	field := messageID.String()
+
+	var get *redis.StringCmd
+	var del *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.HGet(ctx, key, field)
+		del = pipe.HDel(ctx, key, field)
+		return nil
+	})
+	if errors.Is(err, redis.Nil) || (err == nil && del.Val() == 0) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var msg entity.HeldMessage
+	if err := json.Unmarshal([]byte(get.Val()), &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// seqKey возвращает ключ счетчика номеров сообщений в чате стрима
+func seqKey(streamID uuid.UUID) string {
+	return fmt.Sprintf("chat:%s:seq", streamID)
+}
+
+// replayKey возвращает ключ буфера последних сообщений чата стрима
+func replayKey(streamID uuid.UUID) string {
+	return fmt.Sprintf("chat:%s:replay", streamID)
+}
+
+const (
+	ReplayBufferSize = 500              // Сколько последних сообщений комнаты хранится для повтора
+	replayTTL        = 30 * time.Minute // Буфер комнаты без новых сообщений удаляется
+)
+
+// publishMessageScript выдает сообщению следующий номер в комнате, кладет его
+// в буфер повтора и публикует событие о нем. Номер подставляется первым полем
+// в JSON сообщения и события, поэтому они передаются без поля seq. Если счетчик
+// потерян, а последний сохраненный номер неизвестен (ARGV[1] < 0), возвращает 0
+var publishMessageScript = redis.NewScript(`
+local seq
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	seq = redis.call('INCR', KEYS[1])
+else
+	local last = tonumber(ARGV[1])
+	if last < 0 then
+		return 0
+	end
+	seq = last + 1
+	redis.call('SET', KEYS[1], seq)
+end
+local prefix = string.format('{"seq":%d,', seq)
+redis.call('ZADD', KEYS[2], seq, prefix .. string.sub(ARGV[2], 2))
+redis.call('ZREMRANGEBYRANK', KEYS[2], 0, -tonumber(ARGV[5]) - 1)
+redis.call('PEXPIRE', KEYS[2], ARGV[6])
+redis.call('PUBLISH', ARGV[3], prefix .. string.sub(ARGV[4], 2))
+return seq
+`)
+
+// PublishMessage присваивает сообщению следующий номер в комнате, добавляет его
+// в буфер повтора и публикует event в канал channel одной атомарной операцией,
+// поэтому события о сообщениях комнаты публикуются строго по возрастанию номера.
+// Номер передается в событии полем seq верхнего уровня. Сообщение и событие
+// должны быть без номера. Если счетчик номеров потерян, а lastSaved < 0,
+// ничего не делает и возвращает 0: нужно повторить вызов с последним
+// сохраненным номером комнаты
+func (r *RedisCache) PublishMessage(ctx context.Context, msg *entity.ChatMessage, lastSaved int64, channel string, event []byte) (int64, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return 0, err
+	}
+	return publishMessageScript.Run(ctx, r.client,
+		[]string{seqKey(msg.StreamID), replayKey(msg.StreamID)},
+		lastSaved, data, channel, event, ReplayBufferSize, replayTTL.Milliseconds(),
+	).Int64()
+}
+
+// LastSeq возвращает последний выданный номер сообщения или 0
+func (r *RedisCache) LastSeq(ctx context.Context, streamID uuid.UUID) (int64, error) {
+	seq, err := r.client.Get(ctx, seqKey(streamID)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return seq, err
+}
+
+// deletionKey возвращает ключ отметки об удалении сообщения
+func deletionKey(messageID uuid.UUID) string {
+	return fmt.Sprintf("chat:deleted:%s", messageID)
+}
+
+// deletionTTL — сколько хранится отметка об удалении: за это время очередь
+// записи успевает сохранить сообщение
+const deletionTTL = 10 * time.Minute
+
+// MarkMessageDeleted запоминает удаление сообщения, которое могло еще не попасть в MongoDB
+func (r *RedisCache) MarkMessageDeleted(ctx context.Context, deletion *entity.MessageDeletion) error {
+	data, err := json.Marshal(deletion)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, deletionKey(deletion.MessageID), data, deletionTTL).Err()
+}
+
+// MessageDeletions возвращает отметки об удалении сообщений из ids
+func (r *RedisCache) MessageDeletions(ctx context.Context, ids []uuid.UUID) ([]*entity.MessageDeletion, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = deletionKey(id)
+	}
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var deletions []*entity.MessageDeletion
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var deletion entity.MessageDeletion
+		if err := json.Unmarshal([]byte(data), &deletion); err != nil {
+			return nil, err
+		}
+		deletions = append(deletions, &deletion)
+	}
+	return deletions, nil
+}
+
+// clearKey возвращает ключ отметки об очистке сообщений пользователя
+// или, при нулевом userID, всего чата стрима
+func clearKey(streamID, userID uuid.UUID) string {
+	return fmt.Sprintf("chat:%s:cleared:%s", streamID, userID)
+}
+
+// MarkMessagesCleared запоминает очистку чата или сообщений пользователя,
+// чтобы применить ее к сообщениям, которые еще не попали в MongoDB
+func (r *RedisCache) MarkMessagesCleared(ctx context.Context, clear *entity.MessageClear) error {
+	data, err := json.Marshal(clear)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, clearKey(clear.StreamID, clear.UserID), data, deletionTTL).Err()
+}
+
+// MessageClears возвращает отметки об очистке чата стрима и сообщений пользователей userIDs
+func (r *RedisCache) MessageClears(ctx context.Context, streamID uuid.UUID, userIDs []uuid.UUID) ([]*entity.MessageClear, error) {
+	keys := []string{clearKey(streamID, uuid.Nil)}
+	for _, userID := range userIDs {
+		keys = append(keys, clearKey(streamID, userID))
+	}
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var clears []*entity.MessageClear
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var clear entity.MessageClear
+		if err := json.Unmarshal([]byte(data), &clear); err != nil {
+			return nil, err
+		}
+		clears = append(clears, &clear)
+	}
+	return clears, nil
+}
+
+// BufferedMessagesAfter возвращает не более limit последних сообщений из буфера
+// с номером больше afterSeq по возрастанию номера
+func (r *RedisCache) BufferedMessagesAfter(ctx context.Context, streamID uuid.UUID, afterSeq int64, limit int) ([]*entity.ChatMessage, error) {
+	values, err := r.client.ZRevRangeByScore(ctx, replayKey(streamID), &redis.ZRangeBy{
+		Min:   fmt.Sprintf("(%d", afterSeq),
+		Max:   "+inf",
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*entity.ChatMessage, len(values))
+	for i, value := range values {
+		var msg entity.ChatMessage
+		if err := json.Unmarshal([]byte(value), &msg); err != nil {
+			return nil, err
+		}
+		messages[len(values)-1-i] = &msg
+	}
+	return messages, nil
+}
+
+// RemoveBufferedMessages удаляет из буфера повтора сообщения, для которых
+// match возвращает true, например удаленные модератором
+func (r *RedisCache) RemoveBufferedMessages(ctx context.Context, streamID uuid.UUID, match func(*entity.ChatMessage) bool) error {
+	key := replayKey(streamID)
+	values, err := r.client.ZRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return err
+	}
+
+	var stale []interface{}
+	for _, value := range values {
+		var msg entity.ChatMessage
+		if err := json.Unmarshal([]byte(value), &msg); err != nil {
+			return err
+		}
+		if match(&msg) {
+			stale = append(stale, value)
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	return r.client.ZRem(ctx, key, stale...).Err()
+}
+
+// presenceKey возвращает ключ времени последнего heartbeat пользователей в чате стрима
+func presenceKey(streamID uuid.UUID) string {
+	return fmt.Sprintf("chat:%s:presence", streamID)
+}
+
+// chattersKey возвращает ключ сведений о пользователях в чате стрима
+func chattersKey(streamID uuid.UUID) string {
+	return fmt.Sprintf("chat:%s:chatters", streamID)
+}
+
+// PresenceTTL — через сколько после последнего heartbeat пользователь считается вышедшим
+const PresenceTTL = 90 * time.Second
+
+// TouchPresence отмечает пользователей присутствующими в чате стрима
+func (r *RedisCache) TouchPresence(ctx context.Context, streamID uuid.UUID, chatters []entity.Chatter) error {
+	if len(chatters) == 0 {
+		return nil
+	}
+
+	score := float64(time.Now().UnixMilli())
+	members := make([]redis.Z, 0, len(chatters))
+	fields := make([]interface{}, 0, 2*len(chatters))
+	for _, chatter := range chatters {
+		data, err := json.Marshal(chatter)
+		if err != nil {
+			return err
+		}
+		members = append(members, redis.Z{Score: score, Member: chatter.UserID.String()})
+		fields = append(fields, chatter.UserID.String(), data)
+	}
+
+	pipe := r.client.TxPipeline()
+	pipe.ZAdd(ctx, presenceKey(streamID), members...)
+	pipe.HSet(ctx, chattersKey(streamID), fields...)
+	pipe.Expire(ctx, presenceKey(streamID), PresenceTTL)
+	pipe.Expire(ctx, chattersKey(streamID), PresenceTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// RemovePresence отмечает, что пользователь вышел из чата стрима
+func (r *RedisCache) RemovePresence(ctx context.Context, streamID, userID uuid.UUID) error {
+	pipe := r.client.TxPipeline()
+	pipe.ZRem(ctx, presenceKey(streamID), userID.String())
+	pipe.HDel(ctx, chattersKey(streamID), userID.String())
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// SetPresenceRole обновляет роль пользователя, если он сейчас в чате
+func (r *RedisCache) SetPresenceRole(ctx context.Context, streamID, userID uuid.UUID, role entity.Role) error {
+	data, err := r.client.HGet(ctx, chattersKey(streamID), userID.String()).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var chatter entity.Chatter
+	if err := json.Unmarshal(data, &chatter); err != nil {
+		return err
+	}
+	chatter.Role = role
+	if data, err = json.Marshal(chatter); err != nil {
+		return err
+	}
+	return r.client.HSet(ctx, chattersKey(streamID), userID.String(), data).Err()
+}
+
+// CountChatters возвращает число пользователей в чате стрима
+func (r *RedisCache) CountChatters(ctx context.Context, streamID uuid.UUID) (int, error) {
+	if err := r.prunePresence(ctx, streamID); err != nil {
+		return 0, err
+	}
+	count, err := r.client.ZCard(ctx, presenceKey(streamID)).Result()
+	return int(count), err
+}
+
+// ListChatters возвращает всех пользователей в чате стрима
+func (r *RedisCache) ListChatters(ctx context.Context, streamID uuid.UUID) ([]entity.Chatter, error) {
+	if err := r.prunePresence(ctx, streamID); err != nil {
+		return nil, err
+	}
+	ids, err := r.client.ZRange(ctx, presenceKey(streamID), 0, -1).Result()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	values, err := r.client.HMGet(ctx, chattersKey(streamID), ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	chatters := make([]entity.Chatter, 0, len(values))
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			// Сведения уже удалены вместе с выходом пользователя
+			continue
+		}
+		var chatter entity.Chatter
+		if err := json.Unmarshal([]byte(data), &chatter); err != nil {
+			return nil, err
+		}
+		chatters = append(chatters, chatter)
+	}
+	return chatters, nil
+}
+
+// prunePresence удаляет пользователей, не присылавших heartbeat дольше PresenceTTL
+func (r *RedisCache) prunePresence(ctx context.Context, streamID uuid.UUID) error {
+	cutoff := time.Now().Add(-PresenceTTL).UnixMilli()
+	stale, err := r.client.ZRangeByScore(ctx, presenceKey(streamID), &redis.ZRangeBy{
+		Min: "-inf",
+		Max: fmt.Sprintf("(%d", cutoff),
+	}).Result()
+	if err != nil || len(stale) == 0 {
+		return err
+	}
+
+	members := make([]interface{}, len(stale))
+	for i, id := range stale {
+		members[i] = id
+	}
+	pipe := r.client.TxPipeline()
+	pipe.ZRem(ctx, presenceKey(streamID), members...)
+	pipe.HDel(ctx, chattersKey(streamID), stale...)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// pollKey возвращает ключ ID активного опроса в чате стрима
+func pollKey(streamID uuid.UUID) string {
+	return fmt.Sprintf("chat:%s:poll", streamID)
+}
+
+// pollVotersKey возвращает ключ проголосовавших в опросе и их выбора
+func pollVotersKey(streamID, pollID uuid.UUID) string {
+	return fmt.Sprintf("chat:%s:poll:%s:voters", streamID, pollID)
+}
+
+// pollTallyKey возвращает ключ числа голосов по вариантам ответа опроса
+func pollTallyKey(streamID, pollID uuid.UUID) string {
+	return fmt.Sprintf("chat:%s:poll:%s:tally", streamID, pollID)
+}
+
+// pollResultsTTL — сколько голоса хранятся после окончания опроса, чтобы
+// итоги успел записать в PostgreSQL любой экземпляр сервиса
+const pollResultsTTL = time.Hour
+
+var (
+	ErrPollClosed   = errors.New("poll is not active")
+	ErrAlreadyVoted = errors.New("user has already voted in this poll")
+)
+
+// castVoteScript засчитывает голос, только если опрос еще активен
+// и пользователь в нем не голосовал
+var castVoteScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return -1
+end
+if redis.call('HSETNX', KEYS[2], ARGV[2], ARGV[3]) == 0 then
+	return 0
+end
+redis.call('HINCRBY', KEYS[3], ARGV[3], 1)
+redis.call('PEXPIRE', KEYS[2], ARGV[4])
+redis.call('PEXPIRE', KEYS[3], ARGV[4])
+return 1
+`)
+
+// endPollScript снимает отметку активного опроса, только если она указывает на этот опрос
+var endPollScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	redis.call('DEL', KEYS[1])
+end
+redis.call('PEXPIRE', KEYS[2], ARGV[2])
+redis.call('PEXPIRE', KEYS[3], ARGV[2])
+return 1
+`)
+
+// StartPoll отмечает опрос активным в чате стрима на время ttl.
+// Возвращает false, если в чате уже идет другой опрос
+func (r *RedisCache) StartPoll(ctx context.Context, streamID, pollID uuid.UUID, ttl time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, pollKey(streamID), pollID.String(), ttl).Result()
+}
+
+// ActivePoll возвращает ID активного опроса в чате стрима или uuid.Nil
+func (r *RedisCache) ActivePoll(ctx context.Context, streamID uuid.UUID) (uuid.UUID, error) {
+	value, err := r.client.Get(ctx, pollKey(streamID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return uuid.Nil, nil
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return uuid.Parse(value)
+}
+
+// CastVote засчитывает голос пользователя за вариант option. Голоса хранятся
+// не меньше ttl. Возвращает ErrPollClosed, если опрос не активен,
+// и ErrAlreadyVoted при повторном голосовании
+func (r *RedisCache) CastVote(ctx context.Context, streamID, pollID, userID uuid.UUID, option int, ttl time.Duration) error {
+	keys := []string{pollKey(streamID), pollVotersKey(streamID, pollID), pollTallyKey(streamID, pollID)}
+	result, err := castVoteScript.Run(ctx, r.client, keys, pollID.String(), userID.String(), option, (ttl + pollResultsTTL).Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	switch result {
+	case -1:
+		return ErrPollClosed
+	case 0:
+		return ErrAlreadyVoted
+	}
+	return nil
+}
+
+// PollTally возвращает число голосов по каждому из options вариантов ответа
+func (r *RedisCache) PollTally(ctx context.Context, streamID, pollID uuid.UUID, options int) ([]int, error) {
+	values, err := r.client.HGetAll(ctx, pollTallyKey(streamID, pollID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	votes := make([]int, options)
+	for field, value := range values {
+		option, err := strconv.Atoi(field)
+		if err != nil || option < 0 || option >= options {
+			continue
+		}
+		if votes[option], err = strconv.Atoi(value); err != nil {
+			return nil, err
+		}
+	}
+	return votes, nil
+}
+
+// EndPoll снимает отметку активного опроса. Голоса хранятся еще pollResultsTTL
+func (r *RedisCache) EndPoll(ctx context.Context, streamID, pollID uuid.UUID) error {
+	keys := []string{pollKey(streamID), pollVotersKey(streamID, pollID), pollTallyKey(streamID, pollID)}
+	return endPollScript.Run(ctx, r.client, keys, pollID.String(), pollResultsTTL.Milliseconds()).Err()
+}
+
+// reactionsKey возвращает ключ числа реакций на сообщение по видам
+func reactionsKey(streamID, messageID uuid.UUID) string {
+	return fmt.Sprintf("chat:%s:reactions:%s", streamID, messageID)
+}
+
+// reactorsKey возвращает ключ реакций пользователей на сообщение
+func reactorsKey(streamID, messageID uuid.UUID) string {
+	return fmt.Sprintf("chat:%s:reactions:%s:users", streamID, messageID)
+}
+
+// reactionsDirtyKey — сообщения, счетчики реакций которых еще не записаны
+// в MongoDB, в виде "<stream_id>:<message_id>". Множество общее для всех
+// экземпляров сервиса, поэтому несохраненные счетчики переживают перезапуск
+const reactionsDirtyKey = "chat:reactions:dirty"
+
+// reactionsTTL — сколько реакции хранятся в Redis: дольше, чем на сообщение
+// можно реагировать, чтобы счетчики не начались заново
+const reactionsTTL = entity.MaxReactionAge + time.Hour
+
+// setReactionScript заменяет реакцию пользователя на сообщение (пустая
+// строка снимает ее) и пересчитывает счетчики. Возвращает 0, если реакция
+// не изменилась
+var setReactionScript = redis.NewScript(`
+local old = redis.call('HGET', KEYS[1], ARGV[1]) or ''
+if old == ARGV[2] then
+	return 0
+end
+if old ~= '' and redis.call('HINCRBY', KEYS[2], old, -1) <= 0 then
+	redis.call('HDEL', KEYS[2], old)
+end
+if ARGV[2] == '' then
+	redis.call('HDEL', KEYS[1], ARGV[1])
+else
+	redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+	redis.call('HINCRBY', KEYS[2], ARGV[2], 1)
+end
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+redis.call('PEXPIRE', KEYS[2], ARGV[3])
+redis.call('SADD', KEYS[3], ARGV[4])
+return 1
+`)
+
+// ReactionRef — сообщение, счетчики реакций которого нужно записать в MongoDB
+type ReactionRef struct {
+	StreamID  uuid.UUID
+	MessageID uuid.UUID
+}
+
+func (ref ReactionRef) String() string {
+	return ref.StreamID.String() + ":" + ref.MessageID.String()
+}
+
+// SetReaction заменяет реакцию пользователя на сообщение; пустая reaction
+// снимает реакцию. Возвращает false, если реакция не изменилась
+func (r *RedisCache) SetReaction(ctx context.Context, streamID, messageID, userID uuid.UUID, reaction string) (bool, error) {
+	keys := []string{reactorsKey(streamID, messageID), reactionsKey(streamID, messageID), reactionsDirtyKey}
+	ref := ReactionRef{StreamID: streamID, MessageID: messageID}
+	changed, err := setReactionScript.Run(ctx, r.client, keys, userID.String(), reaction, reactionsTTL.Milliseconds(), ref.String()).Int()
+	return changed == 1, err
+}
+
+// UserReaction возвращает реакцию пользователя на сообщение или пустую строку
+func (r *RedisCache) UserReaction(ctx context.Context, streamID, messageID, userID uuid.UUID) (string, error) {
+	reaction, err := r.client.HGet(ctx, reactorsKey(streamID, messageID), userID.String()).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return reaction, err
+}
+
+// ReactionCounts возвращает число реакций на сообщение по видам
+func (r *RedisCache) ReactionCounts(ctx context.Context, streamID, messageID uuid.UUID) (map[string]int, error) {
+	values, err := r.client.HGetAll(ctx, reactionsKey(streamID, messageID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(values))
+	for reaction, value := range values {
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			counts[reaction] = count
+		}
+	}
+	return counts, nil
+}
+
+// TakeDirtyReactions извлекает до limit сообщений, счетчики реакций которых
+// нужно записать в MongoDB. Каждое сообщение достается одному экземпляру сервиса
+func (r *RedisCache) TakeDirtyReactions(ctx context.Context, limit int) ([]ReactionRef, error) {
+	values, err := r.client.SPopN(ctx, reactionsDirtyKey, int64(limit)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]ReactionRef, 0, len(values))
+	for _, value := range values {
+		streamID, messageID, ok := strings.Cut(value, ":")
+		if !ok {
+			continue
+		}
+		var ref ReactionRef
+		if ref.StreamID, err = uuid.Parse(streamID); err != nil {
+			continue
+		}
+		if ref.MessageID, err = uuid.Parse(messageID); err != nil {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// MarkReactionsDirty возвращает сообщения в очередь на запись счетчиков,
+// например после ошибки MongoDB
+func (r *RedisCache) MarkReactionsDirty(ctx context.Context, refs ...ReactionRef) error {
+	if len(refs) == 0 {
+		return nil
+	}
+	members := make([]interface{}, len(refs))
+	for i, ref := range refs {
+		members[i] = ref.String()
+	}
+	return r.client.SAdd(ctx, reactionsDirtyKey, members...).Err()
+}
+
+// TouchMessageRate учитывает сообщение пользователя в чатах и возвращает
+// число его сообщений в текущем окне window
+func (r *RedisCache) TouchMessageRate(ctx context.Context, userID uuid.UUID, window time.Duration) (int64, error) {
+	return r.touchRate(ctx, fmt.Sprintf("chat:messages:%s:rate", userID), window)
+}
+
+// TouchWhisperRate учитывает личное сообщение пользователя и возвращает
+// число его личных сообщений в текущем окне window
+func (r *RedisCache) TouchWhisperRate(ctx context.Context, userID uuid.UUID, window time.Duration) (int64, error) {
+	return r.touchRate(ctx, fmt.Sprintf("chat:whispers:%s:rate", userID), window)
+}
+
+// touchRate увеличивает счетчик key, который живет window с первого увеличения
+func (r *RedisCache) touchRate(ctx context.Context, key string, window time.Duration) (int64, error) {
+	count, err := r.client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 {
+		if err := r.client.Expire(ctx, key, window).Err(); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
 }
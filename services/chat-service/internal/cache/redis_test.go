@@ -0,0 +1,185 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/cache"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCache создает кеш поверх miniredis
+func newCache(t *testing.T) (*cache.RedisCache, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return cache.NewRedisCache(client), mr
+}
+
+// TestTimeoutExpires проверяет, что таймаут снимается автоматически, а бан — нет
+func TestTimeoutExpires(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newCache(t)
+
+	streamID := uuid.New()
+	timedOut := entity.NewChatBan(streamID, uuid.New(), uuid.New(), 10*time.Minute, "spam")
+	banned := entity.NewChatBan(streamID, uuid.New(), uuid.New(), 0, "abuse")
+	require.NoError(t, c.BanUser(ctx, timedOut))
+	require.NoError(t, c.BanUser(ctx, banned))
+
+	ban, err := c.GetBan(ctx, streamID, timedOut.UserID)
+	require.NoError(t, err)
+	require.NotNil(t, ban)
+	assert.False(t, ban.IsPermanent())
+	assert.Equal(t, "spam", ban.Reason)
+
+	mr.FastForward(11 * time.Minute)
+
+	ban, err = c.GetBan(ctx, streamID, timedOut.UserID)
+	require.NoError(t, err)
+	assert.Nil(t, ban)
+
+	isBanned, err := c.IsUserBanned(ctx, streamID, banned.UserID)
+	require.NoError(t, err)
+	assert.True(t, isBanned)
+
+	require.NoError(t, c.UnbanUser(ctx, streamID, banned.UserID))
+	isBanned, err = c.IsUserBanned(ctx, streamID, banned.UserID)
+	require.NoError(t, err)
+	assert.False(t, isBanned)
+}
+
+// TestSlowModeInterval проверяет, что второе сообщение в интервале slow mode
+// получает оставшееся время ожидания
+func TestSlowModeInterval(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newCache(t)
+	streamID, userID := uuid.New(), uuid.New()
+
+	remaining, err := c.SlowModeRemaining(ctx, streamID, userID)
+	require.NoError(t, err)
+	assert.Zero(t, remaining)
+
+	remaining, err = c.TouchSlowMode(ctx, streamID, userID, 30*time.Second)
+	require.NoError(t, err)
+	assert.Zero(t, remaining)
+
+	mr.FastForward(10 * time.Second)
+	remaining, err = c.SlowModeRemaining(ctx, streamID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 20*time.Second, remaining)
+	remaining, err = c.TouchSlowMode(ctx, streamID, userID, 30*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 20*time.Second, remaining)
+
+	mr.FastForward(21 * time.Second)
+	remaining, err = c.TouchSlowMode(ctx, streamID, userID, 30*time.Second)
+	require.NoError(t, err)
+	assert.Zero(t, remaining)
+}
+
+// TestReplayBuffer проверяет нумерацию сообщений, их публикацию и выборку из буфера повтора
+func TestReplayBuffer(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newCache(t)
+	streamID := uuid.New()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	room := client.Subscribe(ctx, "room")
+	t.Cleanup(func() { room.Close() })
+	_, err := room.Receive(ctx)
+	require.NoError(t, err)
+
+	// Без счетчика номер не выдается, пока не передан последний сохраненный номер
+	first := entity.NewChatMessage(streamID, uuid.New(), "user", "hello")
+	seq, err := c.PublishMessage(ctx, first, -1, "room", []byte(`{"type":"message"}`))
+	require.NoError(t, err)
+	assert.Zero(t, seq)
+
+	spammer := uuid.New()
+	for i := 0; i < 5; i++ {
+		userID := uuid.New()
+		if i%2 == 1 {
+			userID = spammer
+		}
+		lastSaved := int64(-1)
+		if i == 0 {
+			lastSaved = 0
+		}
+		msg := entity.NewChatMessage(streamID, userID, "user", "hello")
+		seq, err := c.PublishMessage(ctx, msg, lastSaved, "room", []byte(`{"type":"message"}`))
+		require.NoError(t, err)
+		assert.Equal(t, int64(i+1), seq)
+
+		// Номер подставляется в опубликованное событие
+		published, err := room.ReceiveMessage(ctx)
+		require.NoError(t, err)
+		assert.JSONEq(t, fmt.Sprintf(`{"seq":%d,"type":"message"}`, seq), published.Payload)
+	}
+
+	last, err := c.LastSeq(ctx, streamID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), last)
+
+	// Возвращаются последние limit сообщений после номера по возрастанию
+	messages, err := c.BufferedMessagesAfter(ctx, streamID, 1, 3)
+	require.NoError(t, err)
+	require.Len(t, messages, 3)
+	assert.Equal(t, []int64{3, 4, 5}, []int64{messages[0].Seq, messages[1].Seq, messages[2].Seq})
+
+	require.NoError(t, c.RemoveBufferedMessages(ctx, streamID, func(msg *entity.ChatMessage) bool {
+		return msg.UserID == spammer
+	}))
+	messages, err = c.BufferedMessagesAfter(ctx, streamID, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, messages, 3)
+	assert.Equal(t, []int64{1, 3, 5}, []int64{messages[0].Seq, messages[1].Seq, messages[2].Seq})
+
+	// Потерянный счетчик продолжается после последнего сохраненного номера
+	mr.Del("chat:" + streamID.String() + ":seq")
+	seq, err = c.PublishMessage(ctx, first, 5, "room", []byte(`{"type":"message"}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), seq)
+}
+
+// TestPresenceExpires проверяет, что пользователь без heartbeat пропадает из чата
+func TestPresenceExpires(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newCache(t)
+	streamID := uuid.New()
+
+	active := entity.Chatter{UserID: uuid.New(), Username: "active", Role: entity.RoleViewer}
+	gone := entity.Chatter{UserID: uuid.New(), Username: "gone", Role: entity.RoleVIP}
+	require.NoError(t, c.TouchPresence(ctx, streamID, []entity.Chatter{active, gone}))
+
+	// Последний heartbeat пользователя был раньше PresenceTTL
+	stale := time.Now().Add(-cache.PresenceTTL - time.Second).UnixMilli()
+	_, err := mr.ZAdd("chat:"+streamID.String()+":presence", float64(stale), gone.UserID.String())
+	require.NoError(t, err)
+
+	count, err := c.CountChatters(ctx, streamID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	require.NoError(t, c.SetPresenceRole(ctx, streamID, active.UserID, entity.RoleModerator))
+	chatters, err := c.ListChatters(ctx, streamID)
+	require.NoError(t, err)
+	require.Len(t, chatters, 1)
+	assert.Equal(t, active.UserID, chatters[0].UserID)
+	assert.Equal(t, entity.RoleModerator, chatters[0].Role)
+
+	require.NoError(t, c.RemovePresence(ctx, streamID, active.UserID))
+	count, err = c.CountChatters(ctx, streamID)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
@@ -0,0 +1,73 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/clients/streamingpb"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// StreamingClient получает сведения о стримах из streaming-service
+type StreamingClient struct {
+	conn   *grpc.ClientConn
+	client streamingpb.StreamingServiceClient
+}
+
+// NewStreamingClient создает клиент streaming-service
+func NewStreamingClient(address string, opts ...grpc.DialOption) (*StreamingClient, error) {
+	defaultOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}
+
+	conn, err := grpc.NewClient(address, append(defaultOpts, opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("dial streaming-service at %s: %w", address, err)
+	}
+
+	return &StreamingClient{
+		conn:   conn,
+		client: streamingpb.NewStreamingServiceClient(conn),
+	}, nil
+}
+
+// GetStream вызывает gRPC-метод получения стрима
+func (c *StreamingClient) GetStream(ctx context.Context, streamID uuid.UUID) (*entity.StreamInfo, error) {
+	resp, err := c.client.GetStream(ctx, &streamingpb.GetStreamRequest{StreamId: streamID.String()})
+	if err != nil {
+		return nil, fmt.Errorf("get stream: %w", err)
+	}
+
+	ownerID, err := uuid.Parse(resp.UserId)
+	if err != nil {
+		return nil, fmt.Errorf("get stream: invalid owner id %q: %w", resp.UserId, err)
+	}
+
+	info := &entity.StreamInfo{
+		ID:      streamID,
+		OwnerID: ownerID,
+		Title:   resp.Title,
+		Status:  resp.Status,
+	}
+	if resp.StartTime != nil {
+		info.StartTime = resp.StartTime.AsTime()
+	}
+	if resp.EndTime != nil {
+		info.EndTime = resp.EndTime.AsTime()
+	}
+	return info, nil
+}
+
+// Close закрывает gRPC-соединение
+func (c *StreamingClient) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+
+	return err
+}
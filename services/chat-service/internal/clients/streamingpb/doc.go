@@ -0,0 +1,9 @@
+// Package streamingpb содержит gRPC-клиент streaming-service, сгенерированный
+// из services/streaming-service/proto/streaming.proto:
+//
+//	protoc -I ../streaming-service/proto streaming.proto \
+//	  --go_out=internal/clients/streamingpb --go_opt=paths=source_relative \
+//	  --go_opt=Mstreaming.proto="github.com/exPriceD/Streaming-platform/services/chat-service/internal/clients/streamingpb;streamingpb" \
+//	  --go-grpc_out=internal/clients/streamingpb --go-grpc_opt=paths=source_relative \
+//	  --go-grpc_opt=Mstreaming.proto="github.com/exPriceD/Streaming-platform/services/chat-service/internal/clients/streamingpb;streamingpb"
+package streamingpb
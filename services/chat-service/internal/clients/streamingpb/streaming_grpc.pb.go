@@ -0,0 +1,317 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v3.20.3
+// source: streaming.proto
+
+package streamingpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	StreamingService_StartStream_FullMethodName         = "/proto.StreamingService/StartStream"
+	StreamingService_StopStream_FullMethodName          = "/proto.StreamingService/StopStream"
+	StreamingService_GetStream_FullMethodName           = "/proto.StreamingService/GetStream"
+	StreamingService_GenerateStreamKey_FullMethodName   = "/proto.StreamingService/GenerateStreamKey"
+	StreamingService_GetStreamKey_FullMethodName        = "/proto.StreamingService/GetStreamKey"
+	StreamingService_RegenerateStreamKey_FullMethodName = "/proto.StreamingService/RegenerateStreamKey"
+)
+
+// StreamingServiceClient is the client API for StreamingService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type StreamingServiceClient interface {
+	StartStream(ctx context.Context, in *StartStreamRequest, opts ...grpc.CallOption) (*StreamResponse, error)
+	StopStream(ctx context.Context, in *StopStreamRequest, opts ...grpc.CallOption) (*StreamResponse, error)
+	GetStream(ctx context.Context, in *GetStreamRequest, opts ...grpc.CallOption) (*StreamResponse, error)
+	// Генерация stream-key для нового пользователя
+	GenerateStreamKey(ctx context.Context, in *GenerateStreamKeyRequest, opts ...grpc.CallOption) (*GenerateStreamKeyResponse, error)
+	// Получение stream-key по user_id
+	GetStreamKey(ctx context.Context, in *GetStreamKeyRequest, opts ...grpc.CallOption) (*GetStreamKeyResponse, error)
+	// Перегенерация stream-key по user_id
+	RegenerateStreamKey(ctx context.Context, in *RegenerateStreamKeyRequest, opts ...grpc.CallOption) (*RegenerateStreamKeyResponse, error)
+}
+
+type streamingServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewStreamingServiceClient(cc grpc.ClientConnInterface) StreamingServiceClient {
+	return &streamingServiceClient{cc}
+}
+
+func (c *streamingServiceClient) StartStream(ctx context.Context, in *StartStreamRequest, opts ...grpc.CallOption) (*StreamResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StreamResponse)
+	err := c.cc.Invoke(ctx, StreamingService_StartStream_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *streamingServiceClient) StopStream(ctx context.Context, in *StopStreamRequest, opts ...grpc.CallOption) (*StreamResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StreamResponse)
+	err := c.cc.Invoke(ctx, StreamingService_StopStream_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *streamingServiceClient) GetStream(ctx context.Context, in *GetStreamRequest, opts ...grpc.CallOption) (*StreamResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StreamResponse)
+	err := c.cc.Invoke(ctx, StreamingService_GetStream_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *streamingServiceClient) GenerateStreamKey(ctx context.Context, in *GenerateStreamKeyRequest, opts ...grpc.CallOption) (*GenerateStreamKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GenerateStreamKeyResponse)
+	err := c.cc.Invoke(ctx, StreamingService_GenerateStreamKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *streamingServiceClient) GetStreamKey(ctx context.Context, in *GetStreamKeyRequest, opts ...grpc.CallOption) (*GetStreamKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStreamKeyResponse)
+	err := c.cc.Invoke(ctx, StreamingService_GetStreamKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *streamingServiceClient) RegenerateStreamKey(ctx context.Context, in *RegenerateStreamKeyRequest, opts ...grpc.CallOption) (*RegenerateStreamKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegenerateStreamKeyResponse)
+	err := c.cc.Invoke(ctx, StreamingService_RegenerateStreamKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StreamingServiceServer is the server API for StreamingService service.
+// All implementations must embed UnimplementedStreamingServiceServer
+// for forward compatibility.
+type StreamingServiceServer interface {
+	StartStream(context.Context, *StartStreamRequest) (*StreamResponse, error)
+	StopStream(context.Context, *StopStreamRequest) (*StreamResponse, error)
+	GetStream(context.Context, *GetStreamRequest) (*StreamResponse, error)
+	// Генерация stream-key для нового пользователя
+	GenerateStreamKey(context.Context, *GenerateStreamKeyRequest) (*GenerateStreamKeyResponse, error)
+	// Получение stream-key по user_id
+	GetStreamKey(context.Context, *GetStreamKeyRequest) (*GetStreamKeyResponse, error)
+	// Перегенерация stream-key по user_id
+	RegenerateStreamKey(context.Context, *RegenerateStreamKeyRequest) (*RegenerateStreamKeyResponse, error)
+	mustEmbedUnimplementedStreamingServiceServer()
+}
+
+// UnimplementedStreamingServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedStreamingServiceServer struct{}
+
+func (UnimplementedStreamingServiceServer) StartStream(context.Context, *StartStreamRequest) (*StreamResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartStream not implemented")
+}
+func (UnimplementedStreamingServiceServer) StopStream(context.Context, *StopStreamRequest) (*StreamResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopStream not implemented")
+}
+func (UnimplementedStreamingServiceServer) GetStream(context.Context, *GetStreamRequest) (*StreamResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStream not implemented")
+}
+func (UnimplementedStreamingServiceServer) GenerateStreamKey(context.Context, *GenerateStreamKeyRequest) (*GenerateStreamKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GenerateStreamKey not implemented")
+}
+func (UnimplementedStreamingServiceServer) GetStreamKey(context.Context, *GetStreamKeyRequest) (*GetStreamKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStreamKey not implemented")
+}
+func (UnimplementedStreamingServiceServer) RegenerateStreamKey(context.Context, *RegenerateStreamKeyRequest) (*RegenerateStreamKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegenerateStreamKey not implemented")
+}
+func (UnimplementedStreamingServiceServer) mustEmbedUnimplementedStreamingServiceServer() {}
+func (UnimplementedStreamingServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeStreamingServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to StreamingServiceServer will
+// result in compilation errors.
+type UnsafeStreamingServiceServer interface {
+	mustEmbedUnimplementedStreamingServiceServer()
+}
+
+func RegisterStreamingServiceServer(s grpc.ServiceRegistrar, srv StreamingServiceServer) {
+	// If the following call pancis, it indicates UnimplementedStreamingServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&StreamingService_ServiceDesc, srv)
+}
+
+func _StreamingService_StartStream_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartStreamRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StreamingServiceServer).StartStream(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StreamingService_StartStream_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StreamingServiceServer).StartStream(ctx, req.(*StartStreamRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StreamingService_StopStream_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopStreamRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StreamingServiceServer).StopStream(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StreamingService_StopStream_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StreamingServiceServer).StopStream(ctx, req.(*StopStreamRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StreamingService_GetStream_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStreamRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StreamingServiceServer).GetStream(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StreamingService_GetStream_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StreamingServiceServer).GetStream(ctx, req.(*GetStreamRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StreamingService_GenerateStreamKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenerateStreamKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StreamingServiceServer).GenerateStreamKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StreamingService_GenerateStreamKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StreamingServiceServer).GenerateStreamKey(ctx, req.(*GenerateStreamKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StreamingService_GetStreamKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStreamKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StreamingServiceServer).GetStreamKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StreamingService_GetStreamKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StreamingServiceServer).GetStreamKey(ctx, req.(*GetStreamKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StreamingService_RegenerateStreamKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegenerateStreamKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StreamingServiceServer).RegenerateStreamKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StreamingService_RegenerateStreamKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StreamingServiceServer).RegenerateStreamKey(ctx, req.(*RegenerateStreamKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// StreamingService_ServiceDesc is the grpc.ServiceDesc for StreamingService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var StreamingService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.StreamingService",
+	HandlerType: (*StreamingServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "StartStream",
+			Handler:    _StreamingService_StartStream_Handler,
+		},
+		{
+			MethodName: "StopStream",
+			Handler:    _StreamingService_StopStream_Handler,
+		},
+		{
+			MethodName: "GetStream",
+			Handler:    _StreamingService_GetStream_Handler,
+		},
+		{
+			MethodName: "GenerateStreamKey",
+			Handler:    _StreamingService_GenerateStreamKey_Handler,
+		},
+		{
+			MethodName: "GetStreamKey",
+			Handler:    _StreamingService_GetStreamKey_Handler,
+		},
+		{
+			MethodName: "RegenerateStreamKey",
+			Handler:    _StreamingService_RegenerateStreamKey_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "streaming.proto",
+}
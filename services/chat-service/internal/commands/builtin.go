@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultTimeout  = 10 * time.Minute // Длительность /timeout без явного срока
+	DefaultSlowMode = 30               // Интервал /slow без аргумента в секундах
+)
+
+// DefaultCommands возвращает встроенные команды модерации
+func DefaultCommands() []Command {
+	return []Command{
+		{Name: "ban", Usage: "/ban <user> [reason]", Role: entity.RoleModerator, Run: runBan},
+		{Name: "unban", Usage: "/unban <user>", Role: entity.RoleModerator, Run: runUnban},
+		{Name: "timeout", Usage: "/timeout <user> [duration] [reason]", Role: entity.RoleModerator, Run: runTimeout},
+		{Name: "slow", Usage: "/slow [seconds|off]", Role: entity.RoleModerator, Run: runSlow},
+		{Name: "clear", Usage: "/clear [user]", Role: entity.RoleModerator, Run: runClear},
+		{Name: "pin", Usage: "/pin [duration] <message id or text>", Role: entity.RoleModerator, Run: runPin},
+		{Name: "unpin", Usage: "/unpin", Role: entity.RoleModerator, Run: runUnpin},
+	}
+}
+
+// parseDuration разбирает срок в формате Go ("10m", "1h30m") или число секунд
+func parseDuration(value string) (time.Duration, bool) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	d, err := time.ParseDuration(value)
+	return d, err == nil
+}
+
+// displayName возвращает имя пользователя из аргумента команды для ответа
+func displayName(arg string) string {
+	return strings.TrimPrefix(arg, "@")
+}
+
+func runBan(ctx context.Context, svc Service, inv *Invocation) (string, error) {
+	if len(inv.Args) == 0 {
+		return "", ErrUsage
+	}
+	userID, err := svc.ResolveChatter(ctx, inv.StreamID, inv.Args[0])
+	if err != nil {
+		return "", err
+	}
+	if _, err := svc.BanUser(ctx, inv.StreamID, userID, inv.UserID, 0, inv.Rest(1)); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s is banned", displayName(inv.Args[0])), nil
+}
+
+func runUnban(ctx context.Context, svc Service, inv *Invocation) (string, error) {
+	if len(inv.Args) != 1 {
+		return "", ErrUsage
+	}
+	userID, err := svc.ResolveChatter(ctx, inv.StreamID, inv.Args[0])
+	if err != nil {
+		return "", err
+	}
+	if err := svc.UnbanUser(ctx, inv.StreamID, userID, inv.UserID); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s is unbanned", displayName(inv.Args[0])), nil
+}
+
+func runTimeout(ctx context.Context, svc Service, inv *Invocation) (string, error) {
+	if len(inv.Args) == 0 {
+		return "", ErrUsage
+	}
+	userID, err := svc.ResolveChatter(ctx, inv.StreamID, inv.Args[0])
+	if err != nil {
+		return "", err
+	}
+
+	// Срок можно не указывать: тогда второй аргумент — уже начало причины.
+	// Нулевой срок BanUser понял бы как бессрочный бан, поэтому он запрещен
+	duration, reasonFrom := DefaultTimeout, 1
+	if len(inv.Args) > 1 {
+		if d, ok := parseDuration(inv.Args[1]); ok {
+			if d <= 0 {
+				return "", ErrUsage
+			}
+			duration, reasonFrom = d, 2
+		}
+	}
+
+	ban, err := svc.BanUser(ctx, inv.StreamID, userID, inv.UserID, duration, inv.Rest(reasonFrom))
+	if err != nil {
+		return "", err
+	}
+	if ban.ExpiresAt == nil {
+		return fmt.Sprintf("%s is banned", displayName(inv.Args[0])), nil
+	}
+	return fmt.Sprintf("%s is timed out until %s", displayName(inv.Args[0]), ban.ExpiresAt.Format(time.RFC3339)), nil
+}
+
+func runSlow(ctx context.Context, svc Service, inv *Invocation) (string, error) {
+	if len(inv.Args) > 1 {
+		return "", ErrUsage
+	}
+	seconds := DefaultSlowMode
+	if len(inv.Args) == 1 {
+		if strings.EqualFold(inv.Args[0], "off") {
+			seconds = 0
+		} else {
+			d, ok := parseDuration(inv.Args[0])
+			if !ok || d%time.Second != 0 {
+				return "", ErrUsage
+			}
+			seconds = int(d / time.Second)
+		}
+	}
+
+	modes, err := svc.GetChatModes(ctx, inv.StreamID)
+	if err != nil {
+		return "", err
+	}
+	modes.SlowModeSeconds = seconds
+	if err := svc.SetChatModes(ctx, inv.StreamID, inv.UserID, modes); err != nil {
+		return "", err
+	}
+
+	if seconds == 0 {
+		return "Slow mode is off", nil
+	}
+	return fmt.Sprintf("Slow mode is on: one message every %ds", seconds), nil
+}
+
+func runClear(ctx context.Context, svc Service, inv *Invocation) (string, error) {
+	switch len(inv.Args) {
+	case 0:
+		ids, err := svc.ClearChat(ctx, inv.StreamID, inv.UserID)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Chat cleared, %d messages removed", len(ids)), nil
+	case 1:
+		userID, err := svc.ResolveChatter(ctx, inv.StreamID, inv.Args[0])
+		if err != nil {
+			return "", err
+		}
+		ids, err := svc.ClearUserMessages(ctx, inv.StreamID, userID, inv.UserID, "")
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Removed %d messages from %s", len(ids), displayName(inv.Args[0])), nil
+	default:
+		return "", ErrUsage
+	}
+}
+
+func runPin(ctx context.Context, svc Service, inv *Invocation) (string, error) {
+	// Первый аргумент считается сроком закрепления, только если за ним что-то есть
+	var duration time.Duration
+	args := inv.Args
+	if len(args) > 1 {
+		if d, ok := parseDuration(args[0]); ok {
+			duration, args = d, args[1:]
+		}
+	}
+	if len(args) == 0 {
+		return "", ErrUsage
+	}
+
+	if len(args) == 1 {
+		if messageID, err := uuid.Parse(args[0]); err == nil {
+			if _, err := svc.PinMessage(ctx, inv.StreamID, inv.UserID, messageID, duration); err != nil {
+				return "", err
+			}
+			return "Message pinned", nil
+		}
+	}
+
+	if _, err := svc.PinText(ctx, inv.StreamID, inv.UserID, inv.Username, strings.Join(args, " "), duration); err != nil {
+		return "", err
+	}
+	return "Message pinned", nil
+}
+
+func runUnpin(ctx context.Context, svc Service, inv *Invocation) (string, error) {
+	if len(inv.Args) != 0 {
+		return "", ErrUsage
+	}
+	if err := svc.UnpinMessage(ctx, inv.StreamID, inv.UserID); err != nil {
+		return "", err
+	}
+	return "Message unpinned", nil
+}
@@ -0,0 +1,118 @@
+// Package commands выполняет команды, которые модераторы вводят прямо в чат:
+// /ban, /timeout, /slow, /clear, /pin и другие
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+)
+
+// Prefix — признак команды в начале сообщения
+const Prefix = "/"
+
+var (
+	ErrUnknownCommand = errors.New("unknown command")
+	ErrUsage          = errors.New("invalid command arguments")
+)
+
+// Service — операции чата, через которые команды работают с хранилищем и кешем.
+// Права и старшинство ролей дополнительно проверяет сам сервис
+type Service interface {
+	ResolveChatter(ctx context.Context, streamID uuid.UUID, name string) (uuid.UUID, error)
+	BanUser(ctx context.Context, streamID, userID, moderatorID uuid.UUID, duration time.Duration, reason string) (*entity.ChatBan, error)
+	UnbanUser(ctx context.Context, streamID, userID, moderatorID uuid.UUID) error
+	GetChatModes(ctx context.Context, streamID uuid.UUID) (entity.ChatModes, error)
+	SetChatModes(ctx context.Context, streamID, actorID uuid.UUID, modes entity.ChatModes) error
+	ClearChat(ctx context.Context, streamID, moderatorID uuid.UUID) ([]uuid.UUID, error)
+	ClearUserMessages(ctx context.Context, streamID, userID, moderatorID uuid.UUID, reason string) ([]uuid.UUID, error)
+	PinMessage(ctx context.Context, streamID, actorID, messageID uuid.UUID, duration time.Duration) (*entity.PinnedMessage, error)
+	PinText(ctx context.Context, streamID, actorID uuid.UUID, actorName, content string, duration time.Duration) (*entity.PinnedMessage, error)
+	UnpinMessage(ctx context.Context, streamID, actorID uuid.UUID) error
+}
+
+// Invocation — вызов команды пользователем в чате стрима
+type Invocation struct {
+	StreamID uuid.UUID
+	UserID   uuid.UUID
+	Username string
+	Role     entity.Role // Роль отправителя в комнате на момент вызова
+	Name     string      // Имя команды без префикса в нижнем регистре
+	Args     []string    // Аргументы, разделенные пробелами
+}
+
+// Rest возвращает аргументы, начиная с i-го, одной строкой
+func (inv *Invocation) Rest(i int) string {
+	if i >= len(inv.Args) {
+		return ""
+	}
+	return strings.Join(inv.Args[i:], " ")
+}
+
+// Command — команда чата
+type Command struct {
+	Name  string
+	Usage string      // Подсказка по аргументам, возвращается при ошибке в них
+	Role  entity.Role // Минимальная роль, которой доступна команда
+	// Run выполняет команду и возвращает ответ, который увидит только отправитель.
+	// При неверных аргументах Run возвращает ErrUsage
+	Run func(ctx context.Context, svc Service, inv *Invocation) (string, error)
+}
+
+// Registry хранит известные команды и выполняет их
+type Registry struct {
+	svc      Service
+	commands map[string]Command
+}
+
+// NewRegistry создает реестр с набором команд
+func NewRegistry(svc Service, commands ...Command) *Registry {
+	r := &Registry{svc: svc, commands: make(map[string]Command, len(commands))}
+	for _, cmd := range commands {
+		r.Register(cmd)
+	}
+	return r
+}
+
+// Register добавляет команду, заменяя команду с тем же именем
+func (r *Registry) Register(cmd Command) {
+	r.commands[cmd.Name] = cmd
+}
+
+// Parse разбирает сообщение как команду. Возвращает false, если сообщение
+// не начинается с префикса команды
+func Parse(content string) (name string, args []string, ok bool) {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, Prefix) {
+		return "", nil, false
+	}
+	fields := strings.Fields(strings.TrimPrefix(content, Prefix))
+	if len(fields) == 0 {
+		return "", nil, false
+	}
+	return strings.ToLower(fields[0]), fields[1:], true
+}
+
+// Dispatch проверяет роль отправителя и выполняет команду
+func (r *Registry) Dispatch(ctx context.Context, inv *Invocation) (string, error) {
+	cmd, ok := r.commands[inv.Name]
+	if !ok {
+		return "", fmt.Errorf("%w %s%s", ErrUnknownCommand, Prefix, inv.Name)
+	}
+	if cmd.Role.Outranks(inv.Role) {
+		return "", service.ErrForbidden
+	}
+
+	reply, err := cmd.Run(ctx, r.svc, inv)
+	if errors.Is(err, ErrUsage) {
+		// Отправитель узнает, как правильно вызвать команду
+		return "", fmt.Errorf("%w, usage: %s", ErrUsage, cmd.Usage)
+	}
+	return reply, err
+}
@@ -0,0 +1,170 @@
+package commands_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/commands"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeService запоминает вызовы команд и знает одного пользователя в чате
+type fakeService struct {
+	commands.Service
+	chatter   uuid.UUID
+	ban       *entity.ChatBan
+	modes     entity.ChatModes
+	cleared   uuid.UUID // Чьи сообщения очищены, uuid.Nil — весь чат
+	pinnedID  uuid.UUID
+	pinnedTxt string
+	pinFor    time.Duration
+}
+
+func (f *fakeService) ResolveChatter(_ context.Context, _ uuid.UUID, name string) (uuid.UUID, error) {
+	if name != "@spammer" && name != "spammer" {
+		return uuid.Nil, service.ErrUnknownChatter
+	}
+	return f.chatter, nil
+}
+
+func (f *fakeService) BanUser(_ context.Context, streamID, userID, moderatorID uuid.UUID, duration time.Duration, reason string) (*entity.ChatBan, error) {
+	f.ban = entity.NewChatBan(streamID, userID, moderatorID, duration, reason)
+	return f.ban, nil
+}
+
+func (f *fakeService) GetChatModes(context.Context, uuid.UUID) (entity.ChatModes, error) {
+	return f.modes, nil
+}
+
+func (f *fakeService) SetChatModes(_ context.Context, _, _ uuid.UUID, modes entity.ChatModes) error {
+	f.modes = modes
+	return nil
+}
+
+func (f *fakeService) ClearChat(context.Context, uuid.UUID, uuid.UUID) ([]uuid.UUID, error) {
+	f.cleared = uuid.Nil
+	return []uuid.UUID{uuid.New(), uuid.New()}, nil
+}
+
+func (f *fakeService) ClearUserMessages(_ context.Context, _, userID, _ uuid.UUID, _ string) ([]uuid.UUID, error) {
+	f.cleared = userID
+	return []uuid.UUID{uuid.New()}, nil
+}
+
+func (f *fakeService) PinMessage(_ context.Context, _, _, messageID uuid.UUID, duration time.Duration) (*entity.PinnedMessage, error) {
+	f.pinnedID, f.pinFor = messageID, duration
+	return &entity.PinnedMessage{MessageID: messageID}, nil
+}
+
+func (f *fakeService) PinText(_ context.Context, _, _ uuid.UUID, _, content string, duration time.Duration) (*entity.PinnedMessage, error) {
+	f.pinnedTxt, f.pinFor = content, duration
+	return &entity.PinnedMessage{Content: content}, nil
+}
+
+// run разбирает и выполняет команду от имени модератора
+func run(t *testing.T, registry *commands.Registry, role entity.Role, content string) (string, error) {
+	t.Helper()
+
+	name, args, ok := commands.Parse(content)
+	require.True(t, ok, content)
+	return registry.Dispatch(context.Background(), &commands.Invocation{
+		StreamID: uuid.New(),
+		UserID:   uuid.New(),
+		Username: "mod",
+		Role:     role,
+		Name:     name,
+		Args:     args,
+	})
+}
+
+func TestParse(t *testing.T) {
+	name, args, ok := commands.Parse("  /Timeout  @spammer 5m  stop it ")
+	require.True(t, ok)
+	assert.Equal(t, "timeout", name)
+	assert.Equal(t, []string{"@spammer", "5m", "stop", "it"}, args)
+
+	for _, content := range []string{"hello /ban", "/", "/  ", ""} {
+		_, _, ok := commands.Parse(content)
+		assert.False(t, ok, content)
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	svc := &fakeService{chatter: uuid.New()}
+	registry := commands.NewRegistry(svc, commands.DefaultCommands()...)
+
+	// Роль проверяется до выполнения команды
+	_, err := run(t, registry, entity.RoleVIP, "/ban spammer")
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	assert.Nil(t, svc.ban)
+	_, err = run(t, registry, entity.RoleModerator, "/shrug")
+	assert.ErrorIs(t, err, commands.ErrUnknownCommand)
+
+	// Ошибка в аргументах возвращается с подсказкой
+	_, err = run(t, registry, entity.RoleModerator, "/ban")
+	assert.ErrorIs(t, err, commands.ErrUsage)
+	assert.Contains(t, err.Error(), "/ban <user> [reason]")
+	_, err = run(t, registry, entity.RoleModerator, "/ban nobody")
+	assert.ErrorIs(t, err, service.ErrUnknownChatter)
+
+	reply, err := run(t, registry, entity.RoleModerator, "/ban @spammer posting links")
+	require.NoError(t, err)
+	assert.Equal(t, "spammer is banned", reply)
+	assert.True(t, svc.ban.IsPermanent())
+	assert.Equal(t, svc.chatter, svc.ban.UserID)
+	assert.Equal(t, "posting links", svc.ban.Reason)
+
+	// Срок таймаута необязателен и принимается в секундах или формате Go
+	_, err = run(t, registry, entity.RoleModerator, "/timeout spammer calm down")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(commands.DefaultTimeout), *svc.ban.ExpiresAt, time.Minute)
+	assert.Equal(t, "calm down", svc.ban.Reason)
+	_, err = run(t, registry, entity.RoleModerator, "/timeout spammer 90")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(90*time.Second), *svc.ban.ExpiresAt, time.Minute)
+	for _, command := range []string{"/timeout spammer 0", "/timeout spammer 0s", "/timeout spammer -5m"} {
+		_, err = run(t, registry, entity.RoleModerator, command)
+		assert.ErrorIs(t, err, commands.ErrUsage, command)
+	}
+	assert.NotNil(t, svc.ban.ExpiresAt)
+
+	// Slow mode меняется, остальные режимы сохраняются
+	svc.modes.EmoteOnly = true
+	_, err = run(t, registry, entity.RoleModerator, "/slow")
+	require.NoError(t, err)
+	assert.Equal(t, entity.ChatModes{SlowModeSeconds: commands.DefaultSlowMode, EmoteOnly: true}, svc.modes)
+	_, err = run(t, registry, entity.RoleBroadcaster, "/slow 2m")
+	require.NoError(t, err)
+	assert.Equal(t, 120, svc.modes.SlowModeSeconds)
+	reply, err = run(t, registry, entity.RoleModerator, "/slow off")
+	require.NoError(t, err)
+	assert.Equal(t, "Slow mode is off", reply)
+	assert.Zero(t, svc.modes.SlowModeSeconds)
+	_, err = run(t, registry, entity.RoleModerator, "/slow fast")
+	assert.ErrorIs(t, err, commands.ErrUsage)
+
+	reply, err = run(t, registry, entity.RoleModerator, "/clear spammer")
+	require.NoError(t, err)
+	assert.Equal(t, svc.chatter, svc.cleared)
+	assert.Equal(t, "Removed 1 messages from spammer", reply)
+	reply, err = run(t, registry, entity.RoleModerator, "/clear")
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, svc.cleared)
+	assert.Equal(t, "Chat cleared, 2 messages removed", reply)
+
+	// /pin закрепляет сообщение по ID или отправляет и закрепляет текст
+	messageID := uuid.New()
+	_, err = run(t, registry, entity.RoleModerator, "/pin 10m "+messageID.String())
+	require.NoError(t, err)
+	assert.Equal(t, messageID, svc.pinnedID)
+	assert.Equal(t, 10*time.Minute, svc.pinFor)
+	_, err = run(t, registry, entity.RoleModerator, "/pin giveaway at 9pm")
+	require.NoError(t, err)
+	assert.Equal(t, "giveaway at 9pm", svc.pinnedTxt)
+	assert.Zero(t, svc.pinFor)
+}
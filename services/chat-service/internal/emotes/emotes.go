@@ -0,0 +1,246 @@
+// Package emotes проверяет загруженные изображения эмоутов и готовит их
+// в стандартных размерах. PNG уменьшается с усреднением пикселей, кадры GIF —
+// по ближайшему пикселю, чтобы сохранить палитру и прозрачность
+package emotes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/google/uuid"
+)
+
+const (
+	MaxUploadSize = 1 << 20 // Максимальный размер загружаемого файла (1 МиБ)
+	MinDimension  = 112     // Минимальная сторона исходного изображения
+	MaxDimension  = 512     // Максимальная сторона исходного изображения
+	MaxFrames     = 100     // Максимальное число кадров анимированного эмоута
+)
+
+// Size — стандартный размер эмоута
+type Size struct {
+	Scale  string // Масштаб для клиента: 1x, 2x, 4x
+	Pixels int    // Сторона квадрата в пикселях
+}
+
+// Sizes — размеры, в которых хранится каждый эмоут
+var Sizes = []Size{{"1x", 28}, {"2x", 56}, {"4x", 112}}
+
+var (
+	ErrTooLarge          = errors.New("emote image must not exceed 1 MiB")
+	ErrUnsupportedFormat = errors.New("emote image must be PNG or GIF")
+	ErrInvalidImage      = errors.New("emote image is corrupted")
+	ErrInvalidDimensions = errors.New("emote image must be square, from 112x112 to 512x512 pixels")
+	ErrTooManyFrames     = errors.New("animated emote must not exceed 100 frames")
+)
+
+// Image — изображение эмоута одного размера
+type Image struct {
+	Size Size
+	Data []byte
+}
+
+// Result — подготовленные изображения эмоута во всех размерах
+type Result struct {
+	Format string // entity.EmoteFormatPNG или entity.EmoteFormatGIF
+	Images []Image
+}
+
+// Key возвращает ключ изображения эмоута в файловом хранилище
+func Key(emoteID uuid.UUID, size Size, format string) string {
+	return fmt.Sprintf("emotes/%s/%d.%s", emoteID, size.Pixels, format)
+}
+
+// Process проверяет загруженное изображение и уменьшает его до всех
+// стандартных размеров. Формат определяется по содержимому, а не по имени файла
+func Process(data []byte) (*Result, error) {
+	if len(data) > MaxUploadSize {
+		return nil, ErrTooLarge
+	}
+
+	// Размеры проверяются по заголовку до декодирования всего файла
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if cfg.Width != cfg.Height || cfg.Width < MinDimension || cfg.Width > MaxDimension {
+		return nil, ErrInvalidDimensions
+	}
+
+	switch format {
+	case entity.EmoteFormatPNG:
+		return processPNG(data)
+	case entity.EmoteFormatGIF:
+		return processGIF(data)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+func processPNG(data []byte) (*Result, error) {
+	src, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	result := &Result{Format: entity.EmoteFormatPNG}
+	for _, size := range Sizes {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, resample(src, size.Pixels)); err != nil {
+			return nil, err
+		}
+		result.Images = append(result.Images, Image{Size: size, Data: buf.Bytes()})
+	}
+	return result, nil
+}
+
+func processGIF(data []byte) (*Result, error) {
+	// Кадры считаются без распаковки: небольшой файл может содержать
+	// тысячи кадров, которые не поместятся в память
+	if countGIFFrames(data) > MaxFrames {
+		return nil, ErrTooManyFrames
+	}
+	src, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	result := &Result{Format: entity.EmoteFormatGIF}
+	for _, size := range Sizes {
+		var buf bytes.Buffer
+		if err := gif.EncodeAll(&buf, resizeGIF(src, size.Pixels)); err != nil {
+			return nil, err
+		}
+		result.Images = append(result.Images, Image{Size: size, Data: buf.Bytes()})
+	}
+	return result, nil
+}
+
+// resample уменьшает изображение до квадрата size×size: каждый пиксель
+// результата — среднее пикселей исходного участка с учетом прозрачности
+func resample(src image.Image, size int) *image.NRGBA {
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		y0 := b.Min.Y + y*b.Dy()/size
+		y1 := max(b.Min.Y+(y+1)*b.Dy()/size, y0+1)
+		for x := 0; x < size; x++ {
+			x0 := b.Min.X + x*b.Dx()/size
+			x1 := max(b.Min.X+(x+1)*b.Dx()/size, x0+1)
+
+			// RGBA возвращает цвета, уже умноженные на альфу
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			if a == 0 {
+				continue
+			}
+			dst.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(r * 0xff / a),
+				G: uint8(g * 0xff / a),
+				B: uint8(bl * 0xff / a),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+	return dst
+}
+
+// resizeGIF масштабирует все кадры анимации до квадрата size×size,
+// сохраняя палитры, задержки и способы смены кадров
+func resizeGIF(src *gif.GIF, size int) *gif.GIF {
+	w, h := src.Config.Width, src.Config.Height
+	dst := &gif.GIF{
+		Delay:           src.Delay,
+		Disposal:        src.Disposal,
+		LoopCount:       src.LoopCount,
+		BackgroundIndex: src.BackgroundIndex,
+		Config:          image.Config{ColorModel: src.Config.ColorModel, Width: size, Height: size},
+	}
+
+	for _, frame := range src.Image {
+		// Кадр может занимать только часть холста: его границы масштабируются вместе с ним
+		b := frame.Bounds()
+		r := image.Rect(b.Min.X*size/w, b.Min.Y*size/h, ceilDiv(b.Max.X*size, w), ceilDiv(b.Max.Y*size, h))
+		scaled := image.NewPaletted(r, frame.Palette)
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			sy := min(max((2*y+1)*h/(2*size), b.Min.Y), b.Max.Y-1)
+			for x := r.Min.X; x < r.Max.X; x++ {
+				sx := min(max((2*x+1)*w/(2*size), b.Min.X), b.Max.X-1)
+				scaled.SetColorIndex(x, y, frame.ColorIndexAt(sx, sy))
+			}
+		}
+		dst.Image = append(dst.Image, scaled)
+	}
+	return dst
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
+
+// countGIFFrames считает кадры GIF по структуре блоков файла. Для
+// обрезанного файла возвращает число кадров до места обрыва
+func countGIFFrames(data []byte) int {
+	const headerSize = 13 // Сигнатура и логический экран
+	if len(data) < headerSize {
+		return 0
+	}
+	i := headerSize
+	if flags := data[10]; flags&0x80 != 0 {
+		i += 3 << ((flags & 0x07) + 1) // Глобальная палитра
+	}
+
+	frames := 0
+	for i < len(data) {
+		switch data[i] {
+		case 0x21: // Расширение: метка и подблоки
+			i = skipSubBlocks(data, i+2)
+		case 0x2C: // Кадр: дескриптор, локальная палитра, размер кода LZW и данные
+			if i+10 > len(data) {
+				return frames
+			}
+			flags := data[i+9]
+			i += 10
+			if flags&0x80 != 0 {
+				i += 3 << ((flags & 0x07) + 1)
+			}
+			i = skipSubBlocks(data, i+1)
+			frames++
+			if frames > MaxFrames {
+				return frames
+			}
+		default: // Конец файла (0x3B) или поврежденные данные
+			return frames
+		}
+	}
+	return frames
+}
+
+// skipSubBlocks возвращает позицию после последовательности подблоков,
+// которая заканчивается блоком нулевой длины
+func skipSubBlocks(data []byte, i int) int {
+	for i < len(data) {
+		n := int(data[i])
+		i++
+		if n == 0 {
+			break
+		}
+		i += n
+	}
+	return i
+}
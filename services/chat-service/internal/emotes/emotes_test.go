@@ -0,0 +1,110 @@
+package emotes_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/emotes"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodePNG возвращает PNG w×h: левая половина непрозрачная красная, правая прозрачная
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w/2; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: 0xff, A: 0xff})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// encodeGIF возвращает анимацию size×size из frames кадров; второй и
+// следующие кадры занимают только правый нижний угол холста
+func encodeGIF(t *testing.T, size, frames int) []byte {
+	t.Helper()
+	anim := &gif.GIF{Config: image.Config{ColorModel: color.Palette(palette.Plan9), Width: size, Height: size}}
+	for i := 0; i < frames; i++ {
+		bounds := image.Rect(0, 0, size, size)
+		if i > 0 {
+			bounds = image.Rect(size/2, size/2, size, size)
+		}
+		frame := image.NewPaletted(bounds, palette.Plan9)
+		for j := range frame.Pix {
+			frame.Pix[j] = uint8(i)
+		}
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, 10)
+	}
+	var buf bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&buf, anim))
+	return buf.Bytes()
+}
+
+func TestProcessPNG(t *testing.T) {
+	result, err := emotes.Process(encodePNG(t, 128, 128))
+	require.NoError(t, err)
+	assert.Equal(t, entity.EmoteFormatPNG, result.Format)
+	require.Len(t, result.Images, len(emotes.Sizes))
+
+	for i, size := range emotes.Sizes {
+		img, err := png.Decode(bytes.NewReader(result.Images[i].Data))
+		require.NoError(t, err)
+		assert.Equal(t, size, result.Images[i].Size)
+		assert.Equal(t, image.Rect(0, 0, size.Pixels, size.Pixels), img.Bounds())
+
+		// Прозрачная половина не темнеет при усреднении
+		_, _, _, a := img.At(size.Pixels-1, 0).RGBA()
+		assert.Zero(t, a)
+		r, _, _, a := img.At(0, 0).RGBA()
+		assert.Equal(t, uint32(0xffff), r)
+		assert.Equal(t, uint32(0xffff), a)
+	}
+}
+
+func TestProcessGIF(t *testing.T) {
+	result, err := emotes.Process(encodeGIF(t, 224, 3))
+	require.NoError(t, err)
+	assert.Equal(t, entity.EmoteFormatGIF, result.Format)
+
+	anim, err := gif.DecodeAll(bytes.NewReader(result.Images[0].Data))
+	require.NoError(t, err)
+	assert.Equal(t, 28, anim.Config.Width)
+	require.Len(t, anim.Image, 3)
+	assert.Equal(t, []int{10, 10, 10}, anim.Delay)
+	assert.Equal(t, image.Rect(14, 14, 28, 28), anim.Image[1].Bounds())
+	assert.Equal(t, uint8(2), anim.Image[2].ColorIndexAt(20, 20))
+}
+
+func TestProcessRejectsInvalidImages(t *testing.T) {
+	var jpg bytes.Buffer
+	require.NoError(t, jpeg.Encode(&jpg, image.NewRGBA(image.Rect(0, 0, 128, 128)), nil))
+
+	for name, tc := range map[string]struct {
+		data []byte
+		err  error
+	}{
+		"jpeg":        {jpg.Bytes(), emotes.ErrUnsupportedFormat},
+		"text":        {[]byte("not an image"), emotes.ErrUnsupportedFormat},
+		"truncated":   {encodePNG(t, 128, 128)[:40], emotes.ErrInvalidImage},
+		"not square":  {encodePNG(t, 128, 112), emotes.ErrInvalidDimensions},
+		"too small":   {encodePNG(t, 64, 64), emotes.ErrInvalidDimensions},
+		"too big":     {encodePNG(t, 1024, 1024), emotes.ErrInvalidDimensions},
+		"many frames": {encodeGIF(t, 112, emotes.MaxFrames+1), emotes.ErrTooManyFrames},
+		"oversized":   {make([]byte, emotes.MaxUploadSize+1), emotes.ErrTooLarge},
+	} {
+		_, err := emotes.Process(tc.data)
+		assert.ErrorIs(t, err, tc.err, name)
+	}
+}
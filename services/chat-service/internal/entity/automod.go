@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AutoModAction — действие AutoMod над сообщением, нарушившим правило
+type AutoModAction string
+
+const (
+	AutoModMask   AutoModAction = "mask"   // Скрыть совпадение звездочками и пропустить сообщение
+	AutoModHold   AutoModAction = "hold"   // Задержать сообщение до решения модератора
+	AutoModReject AutoModAction = "reject" // Отклонить сообщение
+)
+
+// IsValid проверяет, что действие известно
+func (a AutoModAction) IsValid() bool {
+	return a == AutoModMask || a == AutoModHold || a == AutoModReject
+}
+
+// BlockedTerm — запрещенное выражение в чате стрима. Символ * в шаблоне
+// совпадает с любой последовательностью букв, например "spam*"
+type BlockedTerm struct {
+	ID        uuid.UUID     `json:"id"`
+	StreamID  uuid.UUID     `json:"stream_id"`
+	Pattern   string        `json:"pattern"`
+	Action    AutoModAction `json:"action"`
+	CreatedBy uuid.UUID     `json:"created_by"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// AutoModSettings — настройки эвристических фильтров AutoMod для чата стрима.
+// Нулевые значения выключают соответствующий фильтр
+type AutoModSettings struct {
+	StreamID               uuid.UUID     `json:"stream_id"`
+	CapsPercent            int           `json:"caps_percent"`             // Допустимая доля заглавных букв, %
+	MaxRepeatedChars       int           `json:"max_repeated_chars"`       // Максимум одинаковых символов подряд
+	DuplicateWindowSeconds int           `json:"duplicate_window_seconds"` // Окно поиска повторов сообщения
+	BlockLinks             bool          `json:"block_links"`              // Блокировать ссылки
+	LinkAction             AutoModAction `json:"link_action"`              // Действие со ссылкой вне allowlist
+	LinkAllowlist          []string      `json:"link_allowlist"`           // Разрешенные домены, включая поддомены
+}
+
+// DefaultAutoModSettings возвращает настройки для чата, в котором они не заданы
+func DefaultAutoModSettings(streamID uuid.UUID) *AutoModSettings {
+	return &AutoModSettings{
+		StreamID:               streamID,
+		CapsPercent:            70,
+		MaxRepeatedChars:       10,
+		DuplicateWindowSeconds: 30,
+		LinkAction:             AutoModReject,
+	}
+}
+
+// Validate проверяет параметры настроек
+func (s *AutoModSettings) Validate() bool {
+	return s.CapsPercent >= 0 && s.CapsPercent <= 100 &&
+		s.MaxRepeatedChars >= 0 &&
+		s.DuplicateWindowSeconds >= 0 && s.DuplicateWindowSeconds <= 3600 &&
+		s.LinkAction.IsValid()
+}
+
+// AutoModRules — все правила AutoMod чата стрима
+type AutoModRules struct {
+	Settings *AutoModSettings `json:"settings"`
+	Terms    []*BlockedTerm   `json:"terms"`
+}
+
+// HeldMessage — сообщение, задержанное AutoMod до решения модератора
+type HeldMessage struct {
+	Message *ChatMessage `json:"message"`
+	Filter  string       `json:"filter"`
+	Reason  string       `json:"reason"`
+	HeldAt  time.Time    `json:"held_at"`
+}
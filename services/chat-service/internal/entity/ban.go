@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChatBan описывает бан или таймаут пользователя в чате стрима
+type ChatBan struct {
+	ID          uuid.UUID  `json:"id"`
+	StreamID    uuid.UUID  `json:"stream_id"`    // Ссылка на streams.id
+	UserID      uuid.UUID  `json:"user_id"`      // Заблокированный пользователь
+	ModeratorID uuid.UUID  `json:"moderator_id"` // Кто выдал блокировку
+	Reason      string     `json:"reason,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // nil для постоянного бана
+}
+
+// NewChatBan создает блокировку. Нулевая длительность означает постоянный бан,
+// положительная — таймаут
+func NewChatBan(streamID, userID, moderatorID uuid.UUID, duration time.Duration, reason string) *ChatBan {
+	now := time.Now().UTC()
+	ban := &ChatBan{
+		ID:          uuid.New(),
+		StreamID:    streamID,
+		UserID:      userID,
+		ModeratorID: moderatorID,
+		Reason:      reason,
+		CreatedAt:   now,
+	}
+	if duration > 0 {
+		expiresAt := now.Add(duration)
+		ban.ExpiresAt = &expiresAt
+	}
+	return ban
+}
+
+// IsPermanent сообщает, является ли блокировка постоянным баном
+func (b *ChatBan) IsPermanent() bool {
+	return b.ExpiresAt == nil
+}
+
+// IsActive проверяет, действует ли блокировка в указанный момент
+func (b *ChatBan) IsActive(now time.Time) bool {
+	return b.ExpiresAt == nil || b.ExpiresAt.After(now)
+}
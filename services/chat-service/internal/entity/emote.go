@@ -0,0 +1,129 @@
+package entity
+
+import (
+	"regexp"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// Форматы изображений эмоутов
+const (
+	EmoteFormatPNG = "png"
+	EmoteFormatGIF = "gif" // Анимированный эмоут
+)
+
+// emoteCodePattern — код эмоута, который пишут в чате, например PogChamp
+var emoteCodePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{1,24}$`)
+
+// ValidEmoteCode проверяет код эмоута: от 2 до 25 латинских букв, цифр
+// и подчеркиваний, первая — буква
+func ValidEmoteCode(code string) bool {
+	return emoteCodePattern.MatchString(code)
+}
+
+// Emote — пользовательский эмоут. Эмоуты с нулевым StreamID входят в
+// глобальный набор и доступны во всех чатах
+type Emote struct {
+	ID        uuid.UUID         `json:"id"`
+	StreamID  uuid.UUID         `json:"stream_id"`
+	Code      string            `json:"code"`
+	Format    string            `json:"format"`
+	URLs      map[string]string `json:"urls,omitempty"` // Ссылки на изображения по масштабу: 1x, 2x, 4x
+	CreatedBy uuid.UUID         `json:"created_by"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// IsGlobal сообщает, входит ли эмоут в глобальный набор
+func (e *Emote) IsGlobal() bool {
+	return e.StreamID == uuid.Nil
+}
+
+// EmoteSet — эмоуты, доступные в чате, по коду
+type EmoteSet map[string]*Emote
+
+// NewEmoteSet объединяет глобальные эмоуты и эмоуты канала. При совпадении
+// кодов побеждает эмоут канала
+func NewEmoteSet(global, channel []*Emote) EmoteSet {
+	set := make(EmoteSet, len(global)+len(channel))
+	for _, emote := range global {
+		set[emote.Code] = emote
+	}
+	for _, emote := range channel {
+		set[emote.Code] = emote
+	}
+	return set
+}
+
+// FragmentType — вид фрагмента текста сообщения
+type FragmentType string
+
+const (
+	FragmentText  FragmentType = "text"
+	FragmentEmote FragmentType = "emote"
+)
+
+// MessageFragment — участок текста сообщения. Границы Start и End отсчитываются
+// в символах Unicode (code points) от начала content, End не включается
+type MessageFragment struct {
+	Type    FragmentType `json:"type"`
+	Text    string       `json:"text"`
+	Start   int          `json:"start"`
+	End     int          `json:"end"`
+	EmoteID *uuid.UUID   `json:"emote_id,omitempty"` // Только для эмоутов
+}
+
+// Tokenize разбивает текст на фрагменты текста и эмоутов. Эмоутом считается
+// слово, отделенное пробелами и полностью совпадающее с кодом эмоута с учетом
+// регистра. Если эмоутов в тексте нет, возвращает nil
+func Tokenize(content string, set EmoteSet) []MessageFragment {
+	if len(set) == 0 {
+		return nil
+	}
+
+	runes := []rune(content)
+	var fragments []MessageFragment
+	textStart, hasEmotes := 0, false
+	appendText := func(end int) {
+		if end > textStart {
+			fragments = append(fragments, MessageFragment{
+				Type:  FragmentText,
+				Text:  string(runes[textStart:end]),
+				Start: textStart,
+				End:   end,
+			})
+		}
+	}
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(runes) && !unicode.IsSpace(runes[i]) {
+			i++
+		}
+		emote, ok := set[string(runes[start:i])]
+		if !ok {
+			continue
+		}
+
+		appendText(start)
+		id := emote.ID
+		fragments = append(fragments, MessageFragment{
+			Type:    FragmentEmote,
+			Text:    emote.Code,
+			Start:   start,
+			End:     i,
+			EmoteID: &id,
+		})
+		textStart, hasEmotes = i, true
+	}
+	if !hasEmotes {
+		return nil
+	}
+	appendText(len(runes))
+	return fragments
+}
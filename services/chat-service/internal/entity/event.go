@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Служебные события комнаты. Тип события задается кадром протокола WebSocket
+
+// MessageDeletedEvent сообщает клиентам, что сообщения удалены модератором.
+// Если заполнен UserID, клиенты скрывают все сообщения этого пользователя,
+// а при ChatCleared — все сообщения чата
+type MessageDeletedEvent struct {
+	StreamID    uuid.UUID   `json:"stream_id"`
+	MessageIDs  []uuid.UUID `json:"message_ids"`
+	UserID      uuid.UUID   `json:"user_id,omitempty"`
+	ChatCleared bool        `json:"chat_cleared,omitempty"`
+	ModeratorID uuid.UUID   `json:"moderator_id"`
+	Reason      string      `json:"reason,omitempty"`
+}
+
+// NewMessageDeletedEvent создает событие удаления сообщений
+func NewMessageDeletedEvent(streamID, moderatorID uuid.UUID, messageIDs []uuid.UUID, reason string) *MessageDeletedEvent {
+	return &MessageDeletedEvent{
+		StreamID:    streamID,
+		MessageIDs:  messageIDs,
+		ModeratorID: moderatorID,
+		Reason:      reason,
+	}
+}
+
+// RoomStateEvent сообщает клиентам текущие режимы чата
+type RoomStateEvent struct {
+	StreamID  uuid.UUID `json:"stream_id"`
+	Modes     ChatModes `json:"modes"`
+	ChangedBy uuid.UUID `json:"changed_by,omitempty"`
+}
+
+// NewRoomStateEvent создает событие изменения режимов чата
+func NewRoomStateEvent(streamID, changedBy uuid.UUID, modes ChatModes) *RoomStateEvent {
+	return &RoomStateEvent{
+		StreamID:  streamID,
+		Modes:     modes,
+		ChangedBy: changedBy,
+	}
+}
+
+// UserBannedEvent сообщает клиентам о бане или таймауте пользователя
+type UserBannedEvent struct {
+	StreamID    uuid.UUID  `json:"stream_id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	ModeratorID uuid.UUID  `json:"moderator_id"`
+	Reason      string     `json:"reason,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // Пусто для постоянного бана
+}
+
+// NewUserBannedEvent создает событие бана или таймаута
+func NewUserBannedEvent(ban *ChatBan) *UserBannedEvent {
+	return &UserBannedEvent{
+		StreamID:    ban.StreamID,
+		UserID:      ban.UserID,
+		ModeratorID: ban.ModeratorID,
+		Reason:      ban.Reason,
+		ExpiresAt:   ban.ExpiresAt,
+	}
+}
+
+// PresenceEvent сообщает клиентам, кто вошел в чат и вышел из него за
+// последний интервал. В больших комнатах списки пользователей не передаются,
+// только счетчики
+type PresenceEvent struct {
+	StreamID    uuid.UUID `json:"stream_id"`
+	Joined      []Chatter `json:"joined,omitempty"`
+	Left        []Chatter `json:"left,omitempty"`
+	JoinedCount int       `json:"joined_count"`
+	LeftCount   int       `json:"left_count"`
+	Chatters    int       `json:"chatters"` // Всего пользователей в чате
+}
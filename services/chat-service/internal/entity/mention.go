@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// MaxMentions — сколько упоминаний в одном сообщении разрешается и уведомляется
+const MaxMentions = 10
+
+// mentionPattern — упоминание пользователя, например @streamer_fan
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@(\w{1,25})`)
+
+// Mention — пользователь, упомянутый в сообщении
+type Mention struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+}
+
+// ParseMentions возвращает имена пользователей, упомянутых в тексте через @,
+// без повторов и не больше MaxMentions
+func ParseMentions(content string) []string {
+	var names []string
+	seen := make(map[string]struct{})
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		key := strings.ToLower(match[1])
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		names = append(names, match[1])
+		if len(names) == MaxMentions {
+			break
+		}
+	}
+	return names
+}
+
+// MentionEvent уведомляет пользователя, что его упомянули или ответили
+// на его сообщение. Доставляется на все подключения пользователя
+type MentionEvent struct {
+	StreamID  uuid.UUID `json:"stream_id"`
+	MessageID uuid.UUID `json:"message_id"`
+	UserID    uuid.UUID `json:"user_id"` // Автор сообщения
+	Username  string    `json:"username"`
+	Content   string    `json:"content"`
+	Reply     bool      `json:"reply"` // Ответ на сообщение пользователя, а не упоминание
+}
+
+// NewMentionEvent создает уведомление об упоминании в сообщении
+func NewMentionEvent(msg *ChatMessage, reply bool) *MentionEvent {
+	return &MentionEvent{
+		StreamID:  msg.StreamID,
+		MessageID: msg.ID,
+		UserID:    msg.UserID,
+		Username:  msg.Username,
+		Content:   msg.Content,
+		Reply:     reply,
+	}
+}
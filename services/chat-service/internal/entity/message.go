@@ -1,20 +1,84 @@
 package entity
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// MessageKind — вид сообщения чата
+type MessageKind string
+
+const (
+	KindMessage      MessageKind = ""             // Обычное сообщение
+	KindAnnouncement MessageKind = "announcement" // Объявление бродкастера или модератора
+)
+
+// Цвета оформления объявлений
+const (
+	AnnouncementPrimary = "primary" // Основной цвет канала
+	AnnouncementBlue    = "blue"
+	AnnouncementGreen   = "green"
+	AnnouncementOrange  = "orange"
+	AnnouncementPurple  = "purple"
+)
+
+// ValidAnnouncementColor проверяет, что цвет объявления поддерживается клиентами
+func ValidAnnouncementColor(color string) bool {
+	switch color {
+	case AnnouncementPrimary, AnnouncementBlue, AnnouncementGreen, AnnouncementOrange, AnnouncementPurple:
+		return true
+	}
+	return false
+}
+
 // ChatMessage представляет сообщение в чате стрима
 type ChatMessage struct {
-	ID        uuid.UUID `json:"id"`         // Уникальный ID сообщения
-	StreamID  uuid.UUID `json:"stream_id"`  // Ссылка на streams.id
-	UserID    uuid.UUID `json:"user_id"`    // Ссылка на users.id
-	Username  string    `json:"username"`   // Дублирование из users.username
-	Content   string    `json:"content"`    // Текст сообщения
-	Timestamp time.Time `json:"timestamp"`  // Время отправки
-	IsDeleted bool      `json:"is_deleted"` // Флаг удаления
+	ID        uuid.UUID         `json:"id"`                  // Уникальный ID сообщения
+	StreamID  uuid.UUID         `json:"stream_id"`           // Ссылка на streams.id
+	UserID    uuid.UUID         `json:"user_id"`             // Ссылка на users.id
+	Username  string            `json:"username"`            // Дублирование из users.username
+	Content   string            `json:"content"`             // Текст сообщения
+	Timestamp time.Time         `json:"timestamp"`           // Время отправки
+	Seq       int64             `json:"seq,omitempty"`       // Порядковый номер в комнате, растет монотонно
+	Badges    []string          `json:"badges,omitempty"`    // Значки ролей отправителя
+	Kind      MessageKind       `json:"kind,omitempty"`      // Вид сообщения, пусто для обычного
+	Color     string            `json:"color,omitempty"`     // Цвет оформления объявления
+	ReplyTo   *ReplyContext     `json:"reply_to,omitempty"`  // Сообщение, на которое дан ответ
+	Mentions  []Mention         `json:"mentions,omitempty"`  // Упомянутые через @ пользователи
+	Fragments []MessageFragment `json:"fragments,omitempty"` // Разбиение текста на текст и эмоуты, пусто без эмоутов
+	Reactions map[string]int    `json:"reactions,omitempty"` // Число реакций по видам, сохраненное в MongoDB
+	IsDeleted bool              `json:"is_deleted"`          // Флаг удаления
+	DeletedBy uuid.UUID         `json:"-"`                   // Модератор, удаливший сообщение
+	ModReason string            `json:"-"`                   // Причина удаления
+}
+
+// MessageDeletion — отметка об удалении сообщения, которое модератор удалил,
+// пока оно ждало записи в MongoDB
+type MessageDeletion struct {
+	MessageID uuid.UUID `json:"message_id"`
+	DeletedBy uuid.UUID `json:"deleted_by"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// MessageClear — отметка об очистке чата или сообщений пользователя. Удаленными
+// считаются сообщения с номером не больше Seq, отправленные не раньше Since,
+// в том числе те, что еще ждали записи в MongoDB
+type MessageClear struct {
+	StreamID  uuid.UUID `json:"stream_id"`
+	UserID    uuid.UUID `json:"user_id"` // Нулевой UUID — очищен весь чат
+	DeletedBy uuid.UUID `json:"deleted_by"`
+	Reason    string    `json:"reason,omitempty"`
+	Seq       int64     `json:"seq"`
+	Since     time.Time `json:"since"`
+}
+
+// Covers сообщает, удалено ли сообщение этой очисткой
+func (c *MessageClear) Covers(msg *ChatMessage) bool {
+	return msg.StreamID == c.StreamID &&
+		(c.UserID == uuid.Nil || msg.UserID == c.UserID) &&
+		msg.Seq <= c.Seq && !msg.Timestamp.Before(c.Since)
 }
 
 // NewChatMessage создает новое сообщение
@@ -29,7 +93,88 @@ func NewChatMessage(streamID, userID uuid.UUID, username, content string) *ChatM
 	}
 }
 
+// ReplyContext — снимок сообщения, на которое отвечает пользователь. Снимок
+// сохраняется вместе с ответом, чтобы клиент показал контекст, даже если
+// исходное сообщение уже не загружено
+type ReplyContext struct {
+	MessageID uuid.UUID `json:"message_id"`
+	ThreadID  uuid.UUID `json:"thread_id"` // Первое сообщение ветки ответов
+	UserID    uuid.UUID `json:"user_id"`   // Автор исходного сообщения
+	Username  string    `json:"username"`
+	Content   string    `json:"content"`
+}
+
+// NewReplyContext создает снимок исходного сообщения для ответа на него
+func NewReplyContext(parent *ChatMessage) *ReplyContext {
+	threadID := parent.ID
+	if parent.ReplyTo != nil {
+		threadID = parent.ReplyTo.ThreadID
+	}
+	return &ReplyContext{
+		MessageID: parent.ID,
+		ThreadID:  threadID,
+		UserID:    parent.UserID,
+		Username:  parent.Username,
+		Content:   parent.Content,
+	}
+}
+
+// NewAnnouncement создает объявление в чате стрима
+func NewAnnouncement(streamID, userID uuid.UUID, username, content, color string) *ChatMessage {
+	msg := NewChatMessage(streamID, userID, username, content)
+	msg.Kind = KindAnnouncement
+	msg.Color = color
+	return msg
+}
+
+// IsAnnouncement сообщает, является ли сообщение объявлением
+func (m *ChatMessage) IsAnnouncement() bool {
+	return m.Kind == KindAnnouncement
+}
+
 // Validate проверяет валидность сообщения
 func (m *ChatMessage) Validate() bool {
 	return len(m.Content) > 0 && len(m.Username) > 0 && m.StreamID != uuid.Nil
 }
+
+// MessageCursor указывает позицию в истории чата: по ID сообщения или по времени
+type MessageCursor struct {
+	MessageID uuid.UUID // Позиция относительно конкретного сообщения
+	Time      time.Time // Позиция относительно момента времени, если MessageID не задан
+}
+
+// ParseMessageCursor разбирает курсор из ID сообщения или времени в формате RFC 3339
+func ParseMessageCursor(value string) (*MessageCursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if id, err := uuid.Parse(value); err == nil {
+		return &MessageCursor{MessageID: id}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor %q: expected message ID or RFC 3339 timestamp", value)
+	}
+	return &MessageCursor{Time: t.UTC()}, nil
+}
+
+// MessageQuery описывает запрос страницы истории сообщений
+type MessageQuery struct {
+	StreamID uuid.UUID
+	Before   *MessageCursor // Сообщения строго раньше курсора
+	After    *MessageCursor // Сообщения строго позже курсора
+	Limit    int
+}
+
+// MessagePage — страница истории сообщений в хронологическом порядке
+type MessagePage struct {
+	Messages []*ChatMessage `json:"messages"`
+	HasMore  bool           `json:"has_more"` // В направлении прокрутки есть еще сообщения
+}
+
+// MessageReplay — сообщения комнаты, пропущенные клиентом после отключения
+type MessageReplay struct {
+	Messages  []*ChatMessage // Пропущенные сообщения по возрастанию номера
+	LastSeq   int64          // Последний выданный номер в комнате
+	Truncated bool           // Пропущено больше, чем повторено: более ранние сообщения доступны в истории
+}
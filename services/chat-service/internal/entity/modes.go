@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	MaxSlowModeSeconds     = 1800   // Максимальный интервал slow mode (30 минут)
+	MaxFollowersMinMinutes = 129600 // Максимальный стаж подписки для followers-only (90 дней)
+)
+
+// ChatModes — режимы чата стрима, которые включает бродкастер или модератор
+type ChatModes struct {
+	SlowModeSeconds     int  `json:"slow_mode"`             // Интервал между сообщениями пользователя, 0 — выключен
+	FollowersOnly       bool `json:"followers_only"`        // Писать могут только фолловеры
+	FollowersMinMinutes int  `json:"followers_min_minutes"` // Минимальный стаж фолловера в минутах
+	EmoteOnly           bool `json:"emote_only"`            // Сообщения только из эмоутов
+	SubscribersOnly     bool `json:"subscribers_only"`      // Писать могут только подписчики
+}
+
+// Validate проверяет, что параметры режимов в допустимых пределах
+func (m ChatModes) Validate() bool {
+	return m.SlowModeSeconds >= 0 && m.SlowModeSeconds <= MaxSlowModeSeconds &&
+		m.FollowersMinMinutes >= 0 && m.FollowersMinMinutes <= MaxFollowersMinMinutes
+}
+
+// IsEmoteOnly проверяет, что сообщение состоит только из эмоутов набора и эмодзи
+func IsEmoteOnly(content string, set EmoteSet) bool {
+	words := strings.Fields(content)
+	if len(words) == 0 {
+		return false
+	}
+	for _, word := range words {
+		if _, ok := set[word]; ok {
+			continue
+		}
+		if !IsEmoji(word) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsEmoji проверяет, что слово состоит только из эмодзи, включая составные
+func IsEmoji(word string) bool {
+	if word == "" {
+		return false
+	}
+	for _, r := range word {
+		if !unicode.Is(unicode.So, r) && !unicode.Is(unicode.Sk, r) && r != '‍' && r != '️' {
+			return false
+		}
+	}
+	return true
+}
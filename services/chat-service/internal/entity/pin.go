@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxPinDuration — максимальный срок закрепления сообщения с истечением
+const MaxPinDuration = 24 * time.Hour
+
+// PinnedMessage — сообщение, закрепленное над чатом стрима. В комнате может
+// быть только одно закрепленное сообщение, новое закрепление заменяет прежнее
+type PinnedMessage struct {
+	StreamID  uuid.UUID   `json:"stream_id"`
+	MessageID uuid.UUID   `json:"message_id"`
+	UserID    uuid.UUID   `json:"user_id"`  // Автор сообщения
+	Username  string      `json:"username"` // Имя автора на момент отправки
+	Content   string      `json:"content"`
+	Kind      MessageKind `json:"kind,omitempty"`
+	Color     string      `json:"color,omitempty"`
+	PinnedBy  uuid.UUID   `json:"pinned_by"`
+	PinnedAt  time.Time   `json:"pinned_at"`
+	ExpiresAt *time.Time  `json:"expires_at,omitempty"` // Пусто, если сообщение закреплено без срока
+}
+
+// NewPinnedMessage закрепляет сообщение чата. При duration = 0 закрепление бессрочное
+func NewPinnedMessage(msg *ChatMessage, pinnedBy uuid.UUID, duration time.Duration) *PinnedMessage {
+	pin := &PinnedMessage{
+		StreamID:  msg.StreamID,
+		MessageID: msg.ID,
+		UserID:    msg.UserID,
+		Username:  msg.Username,
+		Content:   msg.Content,
+		Kind:      msg.Kind,
+		Color:     msg.Color,
+		PinnedBy:  pinnedBy,
+		PinnedAt:  time.Now().UTC(),
+	}
+	if duration > 0 {
+		expiresAt := pin.PinnedAt.Add(duration)
+		pin.ExpiresAt = &expiresAt
+	}
+	return pin
+}
+
+// IsActive проверяет, не истек ли срок закрепления на момент now
+func (p *PinnedMessage) IsActive(now time.Time) bool {
+	return p.ExpiresAt == nil || now.Before(*p.ExpiresAt)
+}
+
+// MessageUnpinnedEvent сообщает клиентам, что закрепленное сообщение снято
+type MessageUnpinnedEvent struct {
+	StreamID   uuid.UUID `json:"stream_id"`
+	MessageID  uuid.UUID `json:"message_id"`
+	UnpinnedBy uuid.UUID `json:"unpinned_by"`
+}
@@ -0,0 +1,108 @@
+package entity
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Ограничения опроса
+const (
+	MinPollOptions        = 2
+	MaxPollOptions        = 5
+	MaxPollQuestionLength = 200 // Символов
+	MaxPollOptionLength   = 50  // Символов
+	MinPollDuration       = 15 * time.Second
+	MaxPollDuration       = 30 * time.Minute
+)
+
+// PollStatus — состояние опроса
+type PollStatus string
+
+const (
+	PollActive PollStatus = "active" // Идет голосование
+	PollEnded  PollStatus = "ended"  // Итоги подведены
+)
+
+// PollOption — вариант ответа и число голосов за него
+type PollOption struct {
+	Text  string `json:"text"`
+	Votes int    `json:"votes"`
+}
+
+// Poll — опрос в чате стрима. Варианты ответа нумеруются с нуля
+type Poll struct {
+	ID         uuid.UUID    `json:"id"`
+	StreamID   uuid.UUID    `json:"stream_id"`
+	Question   string       `json:"question"`
+	Options    []PollOption `json:"options"`
+	TotalVotes int          `json:"total_votes"`
+	Status     PollStatus   `json:"status"`
+	CreatedBy  uuid.UUID    `json:"created_by"`
+	CreatedAt  time.Time    `json:"created_at"`
+	EndsAt     time.Time    `json:"ends_at"`            // Плановое окончание голосования
+	EndedAt    *time.Time   `json:"ended_at,omitempty"` // Фактическое окончание, если опрос завершен досрочно — раньше EndsAt
+}
+
+// NewPoll создает опрос с вариантами options, который длится duration
+func NewPoll(streamID, createdBy uuid.UUID, question string, options []string, duration time.Duration) *Poll {
+	now := time.Now().UTC()
+	poll := &Poll{
+		ID:        uuid.New(),
+		StreamID:  streamID,
+		Question:  strings.TrimSpace(question),
+		Options:   make([]PollOption, 0, len(options)),
+		Status:    PollActive,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		EndsAt:    now.Add(duration),
+	}
+	for _, option := range options {
+		poll.Options = append(poll.Options, PollOption{Text: strings.TrimSpace(option)})
+	}
+	return poll
+}
+
+// Validate проверяет вопрос, варианты ответа и длительность опроса
+func (p *Poll) Validate() bool {
+	if p.Question == "" || utf8.RuneCountInString(p.Question) > MaxPollQuestionLength {
+		return false
+	}
+	if len(p.Options) < MinPollOptions || len(p.Options) > MaxPollOptions {
+		return false
+	}
+	for _, option := range p.Options {
+		if option.Text == "" || utf8.RuneCountInString(option.Text) > MaxPollOptionLength {
+			return false
+		}
+	}
+	duration := p.EndsAt.Sub(p.CreatedAt)
+	return duration >= MinPollDuration && duration <= MaxPollDuration
+}
+
+// IsOpen сообщает, принимает ли опрос голоса в момент now
+func (p *Poll) IsOpen(now time.Time) bool {
+	return p.Status == PollActive && now.Before(p.EndsAt)
+}
+
+// SetTally записывает число голосов по вариантам ответа
+func (p *Poll) SetTally(votes []int) {
+	p.TotalVotes = 0
+	for i := range p.Options {
+		p.Options[i].Votes = 0
+		if i < len(votes) {
+			p.Options[i].Votes = votes[i]
+			p.TotalVotes += votes[i]
+		}
+	}
+}
+
+// PollTallyEvent сообщает клиентам промежуточные итоги опроса
+type PollTallyEvent struct {
+	StreamID   uuid.UUID `json:"stream_id"`
+	PollID     uuid.UUID `json:"poll_id"`
+	Votes      []int     `json:"votes"` // Голоса по вариантам ответа в порядке вариантов
+	TotalVotes int       `json:"total_votes"`
+}
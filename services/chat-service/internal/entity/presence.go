@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+)
+
+// Chatter — пользователь, находящийся в чате стрима
+type Chatter struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Role     Role      `json:"role"`
+}
+
+// ChatterPage — страница списка пользователей в чате. Пользователи упорядочены
+// от старших ролей к младшим, внутри роли — по имени, и сгруппированы по ролям
+type ChatterPage struct {
+	Total      int                `json:"total"`  // Всего пользователей в чате
+	Counts     map[Role]int       `json:"counts"` // Число пользователей по ролям
+	Chatters   map[Role][]Chatter `json:"chatters"`
+	HasMore    bool               `json:"has_more"`
+	NextOffset int                `json:"next_offset,omitempty"`
+}
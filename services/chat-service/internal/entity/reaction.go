@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxReactionAge    = 24 * time.Hour // На более старые сообщения реагировать нельзя
+	MaxReactionLength = 25             // Максимальная длина реакции: код эмоута или эмодзи
+)
+
+// MessageReactions — число реакций на сообщение по видам реакции (код
+// эмоута или эмодзи). Рассылается участникам комнаты кадром reactions_updated
+type MessageReactions struct {
+	StreamID  uuid.UUID      `json:"stream_id"`
+	MessageID uuid.UUID      `json:"message_id"`
+	Counts    map[string]int `json:"counts"`
+	Reaction  string         `json:"reaction,omitempty"` // Реакция пользователя, запросившего счетчики
+}
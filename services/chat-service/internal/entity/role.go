@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role — роль пользователя в чате конкретного стрима
+type Role string
+
+const (
+	RoleViewer      Role = "viewer"      // Обычный зритель
+	RoleVIP         Role = "vip"         // VIP, выданный бродкастером
+	RoleModerator   Role = "moderator"   // Модератор, выданный бродкастером
+	RoleBroadcaster Role = "broadcaster" // Владелец стрима
+)
+
+// rank задает старшинство ролей
+var rank = map[Role]int{
+	RoleViewer:      0,
+	RoleVIP:         1,
+	RoleModerator:   2,
+	RoleBroadcaster: 3,
+}
+
+// IsValid проверяет, что роль известна
+func (r Role) IsValid() bool {
+	_, ok := rank[r]
+	return ok
+}
+
+// IsGrantable сообщает, может ли бродкастер выдать эту роль
+func (r Role) IsGrantable() bool {
+	return r == RoleModerator || r == RoleVIP
+}
+
+// CanModerate сообщает, может ли роль выполнять действия модерации
+func (r Role) CanModerate() bool {
+	return rank[r] >= rank[RoleModerator]
+}
+
+// Outranks сообщает, старше ли роль другой роли
+func (r Role) Outranks(other Role) bool {
+	return rank[r] > rank[other]
+}
+
+// Badges возвращает значки, которые отображаются рядом с сообщениями
+func (r Role) Badges() []string {
+	if r == RoleViewer || !r.IsValid() {
+		return nil
+	}
+	return []string{string(r)}
+}
+
+// ChatRole — роль, выданная пользователю в чате стрима
+type ChatRole struct {
+	StreamID  uuid.UUID `json:"stream_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Role      Role      `json:"role"`
+	GrantedBy uuid.UUID `json:"granted_by"`
+	GrantedAt time.Time `json:"granted_at"`
+}
@@ -8,10 +8,12 @@ import (
 
 // ChatRoom управляет подключениями пользователей для стрима
 type ChatRoom struct {
-	ID          uuid.UUID                     // Уникальный ID комнаты
-	StreamID    uuid.UUID                     // Ссылка на streams.id
-	Connections map[uuid.UUID]*UserConnection // Активные подключения
-	mu          sync.RWMutex                  // Для конкурентного доступа
+	ID            uuid.UUID                     // Уникальный ID комнаты
+	StreamID      uuid.UUID                     // Ссылка на streams.id
+	Modes         ChatModes                     // Режимы чата
+	PinnedMessage *PinnedMessage                // Закрепленное сообщение, nil — ничего не закреплено
+	Connections   map[uuid.UUID]*UserConnection // Активные подключения по ID подключения
+	mu            sync.RWMutex                  // Для конкурентного доступа
 }
 
 // NewChatRoom создает новую комнату для стрима
@@ -23,34 +25,74 @@ func NewChatRoom(streamID uuid.UUID) *ChatRoom {
 	}
 }
 
-// AddConnection добавляет пользователя в комнату
+// AddConnection добавляет подключение в комнату. Один пользователь может
+// находиться в комнате с нескольких подключений, например из разных вкладок
 func (cr *ChatRoom) AddConnection(uc *UserConnection) {
 	cr.mu.Lock()
 	defer cr.mu.Unlock()
-	cr.Connections[uc.UserID] = uc
+	cr.Connections[uc.ID] = uc
 	uc.StreamID = cr.StreamID
 }
 
 // RemoveConnection удаляет подключение
-func (cr *ChatRoom) RemoveConnection(userID uuid.UUID) {
+func (cr *ChatRoom) RemoveConnection(connID uuid.UUID) {
 	cr.mu.Lock()
 	defer cr.mu.Unlock()
-	if conn, exists := cr.Connections[userID]; exists {
+	if conn, exists := cr.Connections[connID]; exists {
 		conn.Close()
-		delete(cr.Connections, userID)
+		delete(cr.Connections, connID)
 	}
 }
 
-// Broadcast отправляет сообщение всем участникам
-func (cr *ChatRoom) Broadcast(message []byte) {
+// HasUser проверяет, остались ли у пользователя подключения в комнате
+func (cr *ChatRoom) HasUser(userID uuid.UUID) bool {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	for _, conn := range cr.Connections {
+		if conn.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Chatters возвращает пользователей комнаты, по одному на пользователя
+func (cr *ChatRoom) Chatters() []Chatter {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+
+	seen := make(map[uuid.UUID]struct{}, len(cr.Connections))
+	chatters := make([]Chatter, 0, len(cr.Connections))
+	for _, conn := range cr.Connections {
+		if _, ok := seen[conn.UserID]; ok {
+			continue
+		}
+		seen[conn.UserID] = struct{}{}
+		chatters = append(chatters, conn.Chatter())
+	}
+	return chatters
+}
+
+// Len возвращает количество подключений в комнате
+func (cr *ChatRoom) Len() int {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	return len(cr.Connections)
+}
+
+// Broadcast ставит сообщение в очереди всех участников, не дожидаясь записи.
+// Участник с переполненной очередью не успевает читать чат и отключается,
+// чтобы не задерживать остальных. Возвращает число отключенных участников
+func (cr *ChatRoom) Broadcast(message []byte) int {
 	cr.mu.RLock()
 	defer cr.mu.RUnlock()
 
+	dropped := 0
 	for _, conn := range cr.Connections {
-		select {
-		case conn.SendChan <- message:
-		default:
+		if !conn.SendMessage(message) {
 			conn.Close()
+			dropped++
 		}
 	}
+	return dropped
 }
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MessageSearch описывает поиск сообщений модератором. Пустые поля не ограничивают выборку
+type MessageSearch struct {
+	Text     string         // Слова для полнотекстового поиска по тексту сообщения
+	StreamID uuid.UUID      // Чат стрима; без него поиск идет по всем чатам
+	UserID   uuid.UUID      // Автор сообщений
+	Since    time.Time      // Сообщения, отправленные не раньше
+	Until    time.Time      // Сообщения, отправленные раньше
+	Deleted  *bool          // true — только удаленные, false — только видимые, nil — все
+	Before   *MessageCursor // Продолжение выдачи: сообщения раньше курсора
+	Limit    int
+}
+
+// ModeratedMessage — сообщение вместе со сведениями об удалении, которые видят только модераторы
+type ModeratedMessage struct {
+	*ChatMessage
+	DeletedBy *uuid.UUID `json:"deleted_by,omitempty"` // Модератор, удаливший сообщение
+	ModReason string     `json:"mod_reason,omitempty"` // Причина удаления
+}
+
+// Moderated раскрывает сведения об удалении сообщения
+func Moderated(msg *ChatMessage) *ModeratedMessage {
+	moderated := &ModeratedMessage{ChatMessage: msg}
+	if msg.IsDeleted {
+		deletedBy := msg.DeletedBy
+		moderated.DeletedBy = &deletedBy
+		moderated.ModReason = msg.ModReason
+	}
+	return moderated
+}
+
+// MessageSearchPage — страница результатов поиска от новых сообщений к старым
+type MessageSearchPage struct {
+	Messages   []*ModeratedMessage `json:"messages"`
+	HasMore    bool                `json:"has_more"`
+	NextCursor string              `json:"next_cursor,omitempty"` // Значение before для следующей страницы
+}
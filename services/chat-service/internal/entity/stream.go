@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StreamInfo — сведения о стриме, получаемые из streaming-service
+type StreamInfo struct {
+	ID        uuid.UUID
+	OwnerID   uuid.UUID // Владелец стрима, он же бродкастер в чате
+	Title     string
+	Status    string
+	StartTime time.Time // Начало трансляции, нулевое, если стрим не запускался
+	EndTime   time.Time // Окончание трансляции, нулевое для идущего стрима
+}
@@ -2,6 +2,7 @@ package entity
 
 import (
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
@@ -12,9 +13,11 @@ type UserConnection struct {
 	ID       uuid.UUID       // Уникальный ID подключения
 	UserID   uuid.UUID       // Ссылка на users.id
 	Username string          // Дублирование из таблицы users
+	Role     Role            // Роль в чате на момент подключения
 	Conn     *websocket.Conn // WebSocket соединение
 	SendChan chan []byte     // Канал для исходящих сообщений
 	StreamID uuid.UUID       // Идентификатор текущего стрима
+	closed   bool            // Соединение уже закрыто
 	mu       sync.Mutex      // Для потокобезопасной работы
 }
 
@@ -29,17 +32,47 @@ func NewUserConnection(userID uuid.UUID, username string, conn *websocket.Conn)
 	}
 }
 
-// SendMessage безопасно отправляет сообщение
-func (uc *UserConnection) SendMessage(msg []byte) {
+// SendMessage ставит сообщение в очередь на отправку.
+// Возвращает false, если соединение закрыто или очередь переполнена
+func (uc *UserConnection) SendMessage(msg []byte) bool {
 	uc.mu.Lock()
 	defer uc.mu.Unlock()
-	uc.Conn.WriteMessage(websocket.TextMessage, msg)
+	if uc.closed {
+		return false
+	}
+
+	select {
+	case uc.SendChan <- msg:
+		return true
+	default:
+		return false
+	}
 }
 
-// Close аккуратно закрывает соединение
+// closeWait — сколько ждать отправки кадра закрытия клиенту
+const closeWait = time.Second
+
+// Close аккуратно закрывает соединение: отправляет клиенту кадр закрытия
+// и закрывает сокет. Повторные вызовы игнорируются
 func (uc *UserConnection) Close() {
 	uc.mu.Lock()
-	defer uc.mu.Unlock()
+	if uc.closed {
+		uc.mu.Unlock()
+		return
+	}
+	uc.closed = true
 	close(uc.SendChan)
+	uc.mu.Unlock()
+
+	// WriteControl можно вызывать параллельно с writePump. Кадр отправляется
+	// вне блокировки, чтобы медленный клиент не задерживал SendMessage
+	uc.Conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(closeWait))
 	uc.Conn.Close()
 }
+
+// Chatter возвращает сведения о пользователе подключения для списка присутствия
+func (uc *UserConnection) Chatter() Chatter {
+	return Chatter{UserID: uc.UserID, Username: uc.Username, Role: uc.Role}
+}
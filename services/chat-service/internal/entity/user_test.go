@@ -0,0 +1,39 @@
+package entity_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUserConnectionClose проверяет, что при закрытии сервером клиент
+// получает кадр закрытия, а не обрыв соединения
+func TestUserConnectionClose(t *testing.T) {
+	closed := make(chan *entity.UserConnection, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		uc := entity.NewUserConnection(uuid.New(), "viewer", conn)
+		uc.Close()
+		uc.Close()
+		closed <- uc
+	}))
+	t.Cleanup(ts.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure), "unexpected error: %v", err)
+	assert.False(t, (<-closed).SendMessage([]byte("late")))
+}
@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VODChatMessage — сообщение чата с отступом от начала трансляции
+type VODChatMessage struct {
+	OffsetMs int64 `json:"offset_ms"` // Миллисекунды от начала трансляции
+	*ChatMessage
+}
+
+// VODChatPage — сообщения чата для отрезка записи трансляции
+type VODChatPage struct {
+	StreamID  uuid.UUID        `json:"stream_id"`
+	StartTime time.Time        `json:"start_time"` // Начало трансляции, от которого считаются отступы
+	Messages  []VODChatMessage `json:"messages"`
+	HasMore   bool             `json:"has_more"` // В отрезке есть еще сообщения: следующая страница запрашивается с after = ID последнего
+}
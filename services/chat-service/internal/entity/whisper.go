@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Whisper — личное сообщение между двумя пользователями вне комнат стримов
+type Whisper struct {
+	ID             uuid.UUID `json:"id"`
+	ConversationID string    `json:"conversation_id"`
+	FromUserID     uuid.UUID `json:"from_user_id"`
+	FromUsername   string    `json:"from_username"`
+	ToUserID       uuid.UUID `json:"to_user_id"`
+	Content        string    `json:"content"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// NewWhisper создает личное сообщение от fromUserID к toUserID
+func NewWhisper(fromUserID uuid.UUID, fromUsername string, toUserID uuid.UUID, content string) *Whisper {
+	return &Whisper{
+		ID:             uuid.New(),
+		ConversationID: ConversationID(fromUserID, toUserID),
+		FromUserID:     fromUserID,
+		FromUsername:   fromUsername,
+		ToUserID:       toUserID,
+		Content:        content,
+		Timestamp:      time.Now().UTC(),
+	}
+}
+
+// ConversationID возвращает ID переписки двух пользователей.
+// Он не зависит от того, кто из них написал первым
+func ConversationID(a, b uuid.UUID) string {
+	if bytes.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+	return a.String() + ":" + b.String()
+}
+
+// Conversation — переписка пользователя с собеседником для списка входящих
+type Conversation struct {
+	ID          string    `json:"id"`
+	UserID      uuid.UUID `json:"user_id"` // Собеседник
+	LastWhisper *Whisper  `json:"last_whisper"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ConversationPage — страница переписок, начиная с самых свежих
+type ConversationPage struct {
+	Conversations []*Conversation `json:"conversations"`
+	HasMore       bool            `json:"has_more"`
+}
+
+// WhisperPage — страница переписки в хронологическом порядке
+type WhisperPage struct {
+	Whispers []*Whisper `json:"whispers"`
+	HasMore  bool       `json:"has_more"` // Есть более ранние сообщения
+}
+
+// UserBlock — блокировка пользователя: заблокированный не может писать
+// заблокировавшему в личные сообщения, и наоборот
+type UserBlock struct {
+	BlockerID uuid.UUID `json:"blocker_id"`
+	BlockedID uuid.UUID `json:"blocked_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
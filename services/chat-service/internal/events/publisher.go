@@ -0,0 +1,124 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/protocol"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// roomChannelPrefix — префикс Redis-каналов комнат чата
+const roomChannelPrefix = "chat:room:"
+
+// RoomChannel возвращает имя Redis-канала комнаты стрима
+func RoomChannel(streamID uuid.UUID) string {
+	return roomChannelPrefix + streamID.String()
+}
+
+// userChannelPrefix — префикс Redis-каналов пользователей для личных кадров
+const userChannelPrefix = "chat:user:"
+
+// UserChannel возвращает имя Redis-канала пользователя
+func UserChannel(userID uuid.UUID) string {
+	return userChannelPrefix + userID.String()
+}
+
+// UserFromChannel возвращает ID пользователя по имени его канала.
+// Для каналов комнат возвращает false
+func UserFromChannel(channel string) (uuid.UUID, bool) {
+	if !strings.HasPrefix(channel, userChannelPrefix) {
+		return uuid.Nil, false
+	}
+	userID, err := uuid.Parse(strings.TrimPrefix(channel, userChannelPrefix))
+	return userID, err == nil
+}
+
+// RoomEvent — событие комнаты, передаваемое между экземплярами сервиса через Redis
+type RoomEvent struct {
+	Seq      int64               `json:"seq,omitempty"` // Номер сообщения, выданный при публикации
+	StreamID uuid.UUID           `json:"stream_id"`
+	Type     string              `json:"type"`              // Тип кадра протокола WebSocket
+	Message  *entity.ChatMessage `json:"message,omitempty"` // Новое сообщение чата
+	Data     json.RawMessage     `json:"data,omitempty"`    // Данные служебного события для клиентов
+}
+
+// NewMessageEvent создает событие о новом сообщении чата
+func NewMessageEvent(msg *entity.ChatMessage) *RoomEvent {
+	return &RoomEvent{StreamID: msg.StreamID, Type: MessageFrameType(msg), Message: msg}
+}
+
+// MessageFrameType возвращает тип кадра, которым сообщение доставляется клиентам
+func MessageFrameType(msg *entity.ChatMessage) string {
+	if msg.IsAnnouncement() {
+		return protocol.TypeAnnouncement
+	}
+	return protocol.TypeMessage
+}
+
+// NewRoomEvent создает служебное событие комнаты с типом кадра eventType
+func NewRoomEvent(streamID uuid.UUID, eventType string, event interface{}) (*RoomEvent, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	return &RoomEvent{StreamID: streamID, Type: eventType, Data: data}, nil
+}
+
+// DecodeRoomEvent разбирает событие комнаты, полученное из Redis. Номер,
+// выданный сообщению при публикации, переносится в само сообщение
+func DecodeRoomEvent(data []byte) (*RoomEvent, error) {
+	var event RoomEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, err
+	}
+	if event.Message != nil && event.Seq != 0 {
+		event.Message.Seq = event.Seq
+	}
+	return &event, nil
+}
+
+// Payload возвращает кадр протокола, отправляемый клиентам WebSocket
+func (e *RoomEvent) Payload() ([]byte, error) {
+	if e.Message != nil {
+		return protocol.Encode(e.Type, "", e.Message)
+	}
+	return protocol.EncodeRaw(e.Type, "", e.Data)
+}
+
+// Publisher публикует события в комнаты чата на всех экземплярах сервиса
+type Publisher struct {
+	client *redis.Client
+}
+
+// NewPublisher создает издателя событий комнат
+func NewPublisher(client *redis.Client) *Publisher {
+	return &Publisher{client: client}
+}
+
+// Publish публикует событие в канал комнаты
+func (p *Publisher) Publish(ctx context.Context, event *RoomEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return p.client.Publish(ctx, RoomChannel(event.StreamID), data).Err()
+}
+
+// PublishEvent публикует служебное событие, например удаление сообщения
+func (p *Publisher) PublishEvent(ctx context.Context, streamID uuid.UUID, eventType string, event interface{}) error {
+	roomEvent, err := NewRoomEvent(streamID, eventType, event)
+	if err != nil {
+		return err
+	}
+	return p.Publish(ctx, roomEvent)
+}
+
+// PublishToUser публикует готовый кадр протокола на все подключения
+// пользователя на всех экземплярах сервиса
+func (p *Publisher) PublishToUser(ctx context.Context, userID uuid.UUID, frame []byte) error {
+	return p.client.Publish(ctx, UserChannel(userID), frame).Err()
+}
@@ -0,0 +1,177 @@
+// Package export сериализует лог чата в форматы для выгрузки: JSON Lines,
+// CSV и текст в стиле IRC. Сообщения пишутся по одному, поэтому выгрузка
+// не требует держать лог целиком в памяти
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+)
+
+// Format — формат выгрузки
+type Format string
+
+const (
+	FormatJSONLines Format = "jsonl" // Один JSON-объект на строку
+	FormatCSV       Format = "csv"   // Таблица с заголовком
+	FormatIRC       Format = "irc"   // [время] <пользователь> текст
+)
+
+// ErrUnknownFormat возвращается для неподдерживаемого формата выгрузки
+var ErrUnknownFormat = errors.New("unknown export format, expected jsonl, csv or irc")
+
+// ParseFormat разбирает формат выгрузки. Пустое значение означает JSON Lines
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(strings.ToLower(value)); format {
+	case "":
+		return FormatJSONLines, nil
+	case FormatJSONLines, FormatCSV, FormatIRC:
+		return format, nil
+	default:
+		return "", ErrUnknownFormat
+	}
+}
+
+// ContentType возвращает MIME-тип выгрузки
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatIRC:
+		return "text/plain; charset=utf-8"
+	default:
+		return "application/x-ndjson"
+	}
+}
+
+// Extension возвращает расширение файла выгрузки
+func (f Format) Extension() string {
+	if f == FormatIRC {
+		return "log"
+	}
+	return string(f)
+}
+
+// Writer пишет сообщения в выбранном формате
+type Writer interface {
+	Write(msg *entity.ChatMessage) error
+	Close() error // Дописывает буферизованные данные
+}
+
+// NewWriter создает Writer формата format. С moderation в выгрузку попадают
+// сведения об удалении: кто удалил сообщение и по какой причине
+func NewWriter(format Format, w io.Writer, moderation bool) (Writer, error) {
+	switch format {
+	case FormatJSONLines:
+		buf := bufio.NewWriter(w)
+		return &jsonLinesWriter{buf: buf, enc: json.NewEncoder(buf), moderation: moderation}, nil
+	case FormatCSV:
+		return &csvWriter{csv: csv.NewWriter(w), moderation: moderation}, nil
+	case FormatIRC:
+		return &ircWriter{buf: bufio.NewWriter(w), moderation: moderation}, nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+type jsonLinesWriter struct {
+	buf        *bufio.Writer
+	enc        *json.Encoder
+	moderation bool
+}
+
+func (w *jsonLinesWriter) Write(msg *entity.ChatMessage) error {
+	if w.moderation {
+		return w.enc.Encode(entity.Moderated(msg))
+	}
+	return w.enc.Encode(msg)
+}
+
+func (w *jsonLinesWriter) Close() error {
+	return w.buf.Flush()
+}
+
+type csvWriter struct {
+	csv        *csv.Writer
+	moderation bool
+	started    bool
+}
+
+func (w *csvWriter) writeHeader() error {
+	w.started = true
+	header := []string{"sent_at", "seq", "message_id", "user_id", "username", "badges", "content"}
+	if w.moderation {
+		header = append(header, "is_deleted", "deleted_by", "mod_reason")
+	}
+	return w.csv.Write(header)
+}
+
+func (w *csvWriter) Write(msg *entity.ChatMessage) error {
+	if !w.started {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+	}
+
+	row := []string{
+		msg.Timestamp.UTC().Format(time.RFC3339Nano),
+		strconv.FormatInt(msg.Seq, 10),
+		msg.ID.String(),
+		msg.UserID.String(),
+		msg.Username,
+		strings.Join(msg.Badges, ";"),
+		msg.Content,
+	}
+	if w.moderation {
+		deletedBy := ""
+		if msg.IsDeleted {
+			deletedBy = msg.DeletedBy.String()
+		}
+		row = append(row, strconv.FormatBool(msg.IsDeleted), deletedBy, msg.ModReason)
+	}
+	return w.csv.Write(row)
+}
+
+func (w *csvWriter) Close() error {
+	// Пустая выгрузка все равно содержит заголовок
+	if !w.started {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+	}
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+type ircWriter struct {
+	buf        *bufio.Writer
+	moderation bool
+}
+
+// lineBreaks заменяет переводы строк, чтобы сообщение занимало одну строку лога
+var lineBreaks = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+func (w *ircWriter) Write(msg *entity.ChatMessage) error {
+	w.buf.WriteString("[" + msg.Timestamp.UTC().Format(time.DateTime) + "] <" + msg.Username + "> ")
+	w.buf.WriteString(lineBreaks.Replace(msg.Content))
+	if w.moderation && msg.IsDeleted {
+		w.buf.WriteString(" [deleted by " + msg.DeletedBy.String())
+		if msg.ModReason != "" {
+			w.buf.WriteString(": " + lineBreaks.Replace(msg.ModReason))
+		}
+		w.buf.WriteString("]")
+	}
+	return w.buf.WriteByte('\n')
+}
+
+func (w *ircWriter) Close() error {
+	return w.buf.Flush()
+}
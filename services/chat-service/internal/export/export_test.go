@@ -0,0 +1,103 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/export"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleLog возвращает обычное и удаленное модератором сообщения
+func sampleLog() []*entity.ChatMessage {
+	streamID := uuid.New()
+	sent := time.Date(2025, 3, 1, 18, 4, 5, 0, time.UTC)
+
+	first := entity.NewChatMessage(streamID, uuid.New(), "viewer", "hello,\n\"chat\"")
+	first.Timestamp = sent
+	first.Seq = 1
+	first.Badges = []string{"vip", "moderator"}
+
+	second := entity.NewChatMessage(streamID, uuid.New(), "troll", "spam")
+	second.Timestamp = sent.Add(time.Second)
+	second.Seq = 2
+	second.IsDeleted = true
+	second.DeletedBy = uuid.New()
+	second.ModReason = "spam"
+	return []*entity.ChatMessage{first, second}
+}
+
+func render(t *testing.T, format export.Format, moderation bool, messages []*entity.ChatMessage) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := export.NewWriter(format, &buf, moderation)
+	require.NoError(t, err)
+	for _, msg := range messages {
+		require.NoError(t, w.Write(msg))
+	}
+	require.NoError(t, w.Close())
+	return buf.String()
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := export.ParseFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, export.FormatJSONLines, format)
+
+	format, err = export.ParseFormat("CSV")
+	require.NoError(t, err)
+	assert.Equal(t, export.FormatCSV, format)
+
+	_, err = export.ParseFormat("xml")
+	assert.ErrorIs(t, err, export.ErrUnknownFormat)
+}
+
+func TestJSONLines(t *testing.T) {
+	messages := sampleLog()
+
+	lines := strings.Split(strings.TrimSuffix(render(t, export.FormatJSONLines, true, messages), "\n"), "\n")
+	require.Len(t, lines, 2)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	assert.Equal(t, messages[1].ID.String(), record["id"])
+	assert.Equal(t, messages[1].DeletedBy.String(), record["deleted_by"])
+	assert.Equal(t, "spam", record["mod_reason"])
+
+	// Без прав модератора сведения об удалении не выгружаются
+	out := render(t, export.FormatJSONLines, false, messages)
+	assert.NotContains(t, out, "deleted_by")
+	assert.NotContains(t, out, "mod_reason")
+}
+
+func TestCSV(t *testing.T) {
+	messages := sampleLog()
+
+	rows, err := csv.NewReader(strings.NewReader(render(t, export.FormatCSV, true, messages))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, []string{"sent_at", "seq", "message_id", "user_id", "username", "badges", "content", "is_deleted", "deleted_by", "mod_reason"}, rows[0])
+	assert.Equal(t, "vip;moderator", rows[1][5])
+	assert.Equal(t, "hello,\n\"chat\"", rows[1][6], "content is quoted, not mangled")
+	assert.Equal(t, []string{"true", messages[1].DeletedBy.String(), "spam"}, rows[2][7:])
+
+	rows, err = csv.NewReader(strings.NewReader(render(t, export.FormatCSV, false, nil))).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"sent_at", "seq", "message_id", "user_id", "username", "badges", "content"}}, rows)
+}
+
+func TestIRC(t *testing.T) {
+	messages := sampleLog()
+
+	assert.Equal(t,
+		"[2025-03-01 18:04:05] <viewer> hello, \"chat\"\n"+
+			"[2025-03-01 18:04:06] <troll> spam [deleted by "+messages[1].DeletedBy.String()+": spam]\n",
+		render(t, export.FormatIRC, true, messages))
+}
@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+)
+
+// GetAutoModRules возвращает настройки AutoMod и запрещенные выражения чата стрима
+func (h *ChatHandler) GetAutoModRules(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	streamID, err := uuid.Parse(r.URL.Query().Get("stream_id"))
+	if err != nil {
+		http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+		return
+	}
+
+	rules, err := h.chatService.GetAutoModRules(r.Context(), streamID, claims.UserID)
+	if isForbidden(err) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error receiving automod rules", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rules)
+}
+
+// UpdateAutoModSettings меняет настройки фильтров AutoMod чата стрима
+func (h *ChatHandler) UpdateAutoModSettings(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var settings entity.AutoModSettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if settings.StreamID == uuid.Nil {
+		http.Error(w, "stream_id is required", http.StatusBadRequest)
+		return
+	}
+
+	err = h.chatService.UpdateAutoModSettings(r.Context(), claims.UserID, &settings)
+	switch {
+	case errors.Is(err, service.ErrInvalidAutoModSettings):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case isForbidden(err):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
+		http.Error(w, "Error updating automod settings", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, settings)
+}
+
+// blockedTermRequest — тело запроса на добавление запрещенного выражения
+type blockedTermRequest struct {
+	StreamID uuid.UUID            `json:"stream_id"`
+	Pattern  string               `json:"pattern"`
+	Action   entity.AutoModAction `json:"action"`
+}
+
+// AddBlockedTerm добавляет запрещенное выражение в чат стрима
+func (h *ChatHandler) AddBlockedTerm(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req blockedTermRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.StreamID == uuid.Nil {
+		http.Error(w, "stream_id is required", http.StatusBadRequest)
+		return
+	}
+	if req.Action == "" {
+		req.Action = entity.AutoModReject
+	}
+
+	term, err := h.chatService.AddBlockedTerm(r.Context(), req.StreamID, claims.UserID, req.Pattern, req.Action)
+	switch {
+	case errors.Is(err, service.ErrInvalidBlockedTerm):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case isForbidden(err):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
+		http.Error(w, "Error adding blocked term", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, term)
+}
+
+// RemoveBlockedTerm удаляет запрещенное выражение из чата стрима
+func (h *ChatHandler) RemoveBlockedTerm(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	streamID, err := uuid.Parse(r.URL.Query().Get("stream_id"))
+	if err != nil {
+		http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+		return
+	}
+	termID, err := uuid.Parse(r.PathValue("term_id"))
+	if err != nil {
+		http.Error(w, "Invalid term_id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.chatService.RemoveBlockedTerm(r.Context(), streamID, termID, claims.UserID)
+	switch {
+	case errors.Is(err, repository.ErrTermNotFound):
+		http.Error(w, "Term not found", http.StatusNotFound)
+		return
+	case isForbidden(err):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
+		http.Error(w, "Error removing blocked term", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListHeldMessages возвращает сообщения, задержанные AutoMod до решения модератора
+func (h *ChatHandler) ListHeldMessages(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	streamID, err := uuid.Parse(r.URL.Query().Get("stream_id"))
+	if err != nil {
+		http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+		return
+	}
+
+	held, err := h.chatService.ListHeldMessages(r.Context(), streamID, claims.UserID)
+	if isForbidden(err) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error receiving held messages", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, held)
+}
+
+// ApproveHeldMessage отправляет задержанное сообщение в чат
+func (h *ChatHandler) ApproveHeldMessage(w http.ResponseWriter, r *http.Request) {
+	h.reviewHeldMessage(w, r, true)
+}
+
+// DenyHeldMessage отклоняет задержанное сообщение
+func (h *ChatHandler) DenyHeldMessage(w http.ResponseWriter, r *http.Request) {
+	h.reviewHeldMessage(w, r, false)
+}
+
+// reviewHeldMessage применяет решение модератора к задержанному сообщению
+func (h *ChatHandler) reviewHeldMessage(w http.ResponseWriter, r *http.Request, approve bool) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	streamID, err := uuid.Parse(r.URL.Query().Get("stream_id"))
+	if err != nil {
+		http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+		return
+	}
+	messageID, err := uuid.Parse(r.PathValue("message_id"))
+	if err != nil {
+		http.Error(w, "Invalid message_id", http.StatusBadRequest)
+		return
+	}
+
+	msg, err := h.chatService.ReviewHeldMessage(r.Context(), streamID, messageID, claims.UserID, approve)
+	switch {
+	case errors.Is(err, service.ErrHeldMessageNotFound):
+		http.Error(w, "Held message not found", http.StatusNotFound)
+		return
+	case isForbidden(err):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
+		http.Error(w, "Error reviewing held message", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, msg)
+}
@@ -1,10 +1,14 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/auth"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
 	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
 	"github.com/google/uuid"
 )
@@ -12,27 +16,67 @@ import (
 // ChatHandler обрабатывает HTTP-запросы
 type ChatHandler struct {
 	chatService *service.ChatService
+	tokens      *auth.TokenValidator
 }
 
 // NewChatHandler создает новый обработчик
-func NewChatHandler(chatService *service.ChatService) *ChatHandler {
-	return &ChatHandler{chatService: chatService}
+func NewChatHandler(chatService *service.ChatService, tokens *auth.TokenValidator) *ChatHandler {
+	return &ChatHandler{chatService: chatService, tokens: tokens}
 }
 
-// GetMessages обрабатывает запрос на получение сообщений
+// GetMessages обрабатывает запрос на получение страницы истории сообщений.
+// Параметры: stream_id, limit, before и after (ID сообщения или время в RFC 3339)
 func (h *ChatHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
-	streamID, err := uuid.Parse(r.URL.Query().Get("stream_id"))
+	params := r.URL.Query()
+
+	streamID, err := uuid.Parse(params.Get("stream_id"))
 	if err != nil {
 		http.Error(w, "Invalid steam_id", http.StatusBadRequest)
 		return
 	}
 
-	messages, err := h.chatService.GetMessages(context.Background(), streamID, 20)
+	query := entity.MessageQuery{StreamID: streamID}
+	if limit := params.Get("limit"); limit != "" {
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+	if query.Before, err = entity.ParseMessageCursor(params.Get("before")); err != nil {
+		http.Error(w, "Invalid before cursor", http.StatusBadRequest)
+		return
+	}
+	if query.After, err = entity.ParseMessageCursor(params.Get("after")); err != nil {
+		http.Error(w, "Invalid after cursor", http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.chatService.GetMessages(r.Context(), query)
+	if errors.Is(err, repository.ErrMessageNotFound) {
+		http.Error(w, "Cursor message not found", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Error receiving messages", http.StatusInternalServerError)
 		return
 	}
 
+	writeJSON(w, http.StatusOK, page)
+}
+
+// authenticate возвращает пользователя, выполняющего запрос
+func (h *ChatHandler) authenticate(r *http.Request) (*auth.Claims, error) {
+	tokenString := auth.TokenFromRequest(r)
+	if tokenString == "" {
+		return nil, auth.ErrInvalidToken
+	}
+	return h.tokens.Validate(tokenString)
+}
+
+// writeJSON отправляет ответ в формате JSON
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(messages)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
 }
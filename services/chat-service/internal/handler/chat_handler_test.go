@@ -0,0 +1,90 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/cache"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/events"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/handler"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// historyStore запоминает запрос истории. Курсор на missing считается
+// несуществующим сообщением
+type historyStore struct {
+	repository.ChatRepository
+	missing uuid.UUID
+	query   *entity.MessageQuery
+}
+
+func (h *historyStore) GetMessages(_ context.Context, query entity.MessageQuery) (*entity.MessagePage, error) {
+	h.query = &query
+	if query.Before != nil && query.Before.MessageID == h.missing {
+		return nil, repository.ErrMessageNotFound
+	}
+	return &entity.MessagePage{Messages: []*entity.ChatMessage{}, HasMore: true}, nil
+}
+
+func TestGetMessagesCursors(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	repo := &historyStore{missing: uuid.New()}
+	svc := service.NewChatService(repo, cache.NewRedisCache(client), events.NewPublisher(client), nil)
+	h := handler.NewChatHandler(svc, nil)
+
+	streamID, cursorID := uuid.New(), uuid.New()
+	get := func(params url.Values) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.GetMessages(rec, httptest.NewRequest(http.MethodGet, "/messages?"+params.Encode(), nil))
+		return rec
+	}
+
+	// before — ID сообщения, after — время в RFC 3339
+	rec := get(url.Values{
+		"stream_id": {streamID.String()},
+		"limit":     {"500"},
+		"before":    {cursorID.String()},
+		"after":     {"2024-05-01T12:00:00.5+03:00"},
+	})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var page entity.MessagePage
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+	assert.True(t, page.HasMore)
+	require.NotNil(t, repo.query)
+	assert.Equal(t, streamID, repo.query.StreamID)
+	assert.Equal(t, service.MaxHistoryLimit, repo.query.Limit)
+	assert.Equal(t, &entity.MessageCursor{MessageID: cursorID}, repo.query.Before)
+	assert.Equal(t, &entity.MessageCursor{Time: time.Date(2024, 5, 1, 9, 0, 0, 500000000, time.UTC)}, repo.query.After)
+
+	rec = get(url.Values{"stream_id": {streamID.String()}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, service.DefaultHistoryLimit, repo.query.Limit)
+	assert.Nil(t, repo.query.Before)
+	assert.Nil(t, repo.query.After)
+
+	for _, params := range []url.Values{
+		{"stream_id": {"stream"}},
+		{"stream_id": {streamID.String()}, "limit": {"0"}},
+		{"stream_id": {streamID.String()}, "limit": {"ten"}},
+		{"stream_id": {streamID.String()}, "before": {"yesterday"}},
+		{"stream_id": {streamID.String()}, "after": {"2024-05-01"}},
+		{"stream_id": {streamID.String()}, "before": {repo.missing.String()}},
+	} {
+		assert.Equal(t, http.StatusBadRequest, get(params).Code, params.Encode())
+	}
+}
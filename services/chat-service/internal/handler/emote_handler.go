@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/emotes"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+)
+
+// maxEmoteFormSize — предел тела запроса на загрузку эмоута: файл и поля формы
+const maxEmoteFormSize = emotes.MaxUploadSize + 64<<10
+
+// parseOptionalStreamID разбирает необязательный stream_id: пустое значение
+// означает глобальный набор эмоутов
+func parseOptionalStreamID(value string) (uuid.UUID, error) {
+	if value == "" {
+		return uuid.Nil, nil
+	}
+	return uuid.Parse(value)
+}
+
+// ListEmotes возвращает эмоуты, доступные в чате стрима, или только
+// глобальный набор, если stream_id не указан
+func (h *ChatHandler) ListEmotes(w http.ResponseWriter, r *http.Request) {
+	streamID, err := parseOptionalStreamID(r.URL.Query().Get("stream_id"))
+	if err != nil {
+		http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+		return
+	}
+
+	list, err := h.chatService.ListEmotes(r.Context(), streamID)
+	if err != nil {
+		http.Error(w, "Error receiving emotes", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, list)
+}
+
+// UploadEmote загружает эмоут канала или глобальный эмоут. Форма multipart:
+// stream_id (пусто — глобальный набор), code и файл image в формате PNG или GIF
+func (h *ChatHandler) UploadEmote(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxEmoteFormSize)
+	if err := r.ParseMultipartForm(maxEmoteFormSize); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, emotes.ErrTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Invalid multipart form", http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	streamID, err := parseOptionalStreamID(r.FormValue("stream_id"))
+	if err != nil {
+		http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+		return
+	}
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		http.Error(w, "image is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	image, err := io.ReadAll(io.LimitReader(file, emotes.MaxUploadSize+1))
+	if err != nil {
+		http.Error(w, "Error reading image", http.StatusBadRequest)
+		return
+	}
+
+	emote, err := h.chatService.UploadEmote(r.Context(), streamID, claims.UserID, r.FormValue("code"), image)
+	switch {
+	case errors.Is(err, emotes.ErrTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	case errors.Is(err, service.ErrInvalidEmoteCode), errors.Is(err, emotes.ErrUnsupportedFormat),
+		errors.Is(err, emotes.ErrInvalidImage), errors.Is(err, emotes.ErrInvalidDimensions),
+		errors.Is(err, emotes.ErrTooManyFrames):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrEmoteCodeTaken), errors.Is(err, service.ErrEmoteLimit):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrEmoteUploadDisabled):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	case isForbidden(err):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case err != nil:
+		http.Error(w, "Error uploading emote", http.StatusInternalServerError)
+	default:
+		writeJSON(w, http.StatusCreated, emote)
+	}
+}
+
+// DeleteEmote удаляет эмоут канала или, без stream_id, глобальный эмоут
+func (h *ChatHandler) DeleteEmote(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	streamID, err := parseOptionalStreamID(r.URL.Query().Get("stream_id"))
+	if err != nil {
+		http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+		return
+	}
+	emoteID, err := uuid.Parse(r.PathValue("emote_id"))
+	if err != nil {
+		http.Error(w, "Invalid emote_id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.chatService.DeleteEmote(r.Context(), streamID, emoteID, claims.UserID)
+	switch {
+	case errors.Is(err, repository.ErrEmoteNotFound):
+		http.Error(w, "Emote not found", http.StatusNotFound)
+	case isForbidden(err):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case err != nil:
+		http.Error(w, "Error deleting emote", http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
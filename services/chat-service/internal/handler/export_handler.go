@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/export"
+	"github.com/google/uuid"
+)
+
+// ExportChat выгружает лог чата стрима файлом. Параметры: stream_id и format
+// (jsonl, csv или irc). Лог передается потоком по мере чтения из базы
+func (h *ChatHandler) ExportChat(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	params := r.URL.Query()
+	streamID, err := uuid.Parse(params.Get("stream_id"))
+	if err != nil {
+		http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+		return
+	}
+	format, err := export.ParseFormat(params.Get("format"))
+	if errors.Is(err, export.ErrUnknownFormat) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	out := &exportResponse{w: w, format: format, streamID: streamID}
+	err = h.chatService.ExportChat(r.Context(), streamID, claims.UserID, format, out)
+	switch {
+	case err == nil:
+		if !out.started {
+			// Пустой лог в JSON Lines и IRC — пустой файл
+			out.writeHeaders()
+		}
+	case out.started:
+		// Заголовки и часть файла уже отправлены: сменить статус нельзя,
+		// обрываем соединение, чтобы клиент не принял обрезанный файл за целый
+		panic(http.ErrAbortHandler)
+	default:
+		http.Error(w, "Error exporting chat", http.StatusInternalServerError)
+	}
+}
+
+// exportResponse откладывает отправку заголовков файла до первой записи,
+// чтобы ошибка до начала выгрузки вернулась обычным ответом
+type exportResponse struct {
+	w        http.ResponseWriter
+	format   export.Format
+	streamID uuid.UUID
+	started  bool
+}
+
+func (e *exportResponse) writeHeaders() {
+	e.started = true
+	header := e.w.Header()
+	header.Set("Content-Type", e.format.ContentType())
+	header.Set("Content-Disposition", "attachment; filename=chat-"+e.streamID.String()+"."+e.format.Extension())
+	e.w.WriteHeader(http.StatusOK)
+}
+
+func (e *exportResponse) Write(p []byte) (int, error) {
+	if !e.started {
+		e.writeHeaders()
+	}
+	return e.w.Write(p)
+}
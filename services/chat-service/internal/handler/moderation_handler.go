@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+)
+
+// banRequest — тело запроса на бан или таймаут.
+// Пустая длительность означает постоянный бан
+type banRequest struct {
+	StreamID uuid.UUID `json:"stream_id"`
+	UserID   uuid.UUID `json:"user_id"`
+	Duration string    `json:"duration,omitempty"` // Например "10m" или "24h"
+	Reason   string    `json:"reason,omitempty"`
+}
+
+// BanUser выдает пользователю бан или таймаут
+func (h *ChatHandler) BanUser(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req banRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.StreamID == uuid.Nil || req.UserID == uuid.Nil {
+		http.Error(w, "stream_id and user_id are required", http.StatusBadRequest)
+		return
+	}
+
+	var duration time.Duration
+	if req.Duration != "" {
+		duration, err = time.ParseDuration(req.Duration)
+		if err != nil {
+			http.Error(w, "Invalid duration", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ban, err := h.chatService.BanUser(r.Context(), req.StreamID, req.UserID, claims.UserID, duration, req.Reason)
+	switch {
+	case errors.Is(err, service.ErrInvalidBanDuration),
+		errors.Is(err, service.ErrBanReasonTooLong),
+		errors.Is(err, service.ErrCannotBanSelf):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case isForbidden(err):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
+		http.Error(w, "Error banning user", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, ban)
+}
+
+// ListBans возвращает действующие баны и таймауты в чате стрима
+func (h *ChatHandler) ListBans(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	streamID, err := uuid.Parse(r.URL.Query().Get("stream_id"))
+	if err != nil {
+		http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+		return
+	}
+
+	bans, err := h.chatService.ListBans(r.Context(), streamID, claims.UserID)
+	if isForbidden(err) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error receiving bans", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, bans)
+}
+
+// UnbanUser снимает бан или таймаут пользователя
+func (h *ChatHandler) UnbanUser(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	streamID, err := uuid.Parse(r.URL.Query().Get("stream_id"))
+	if err != nil {
+		http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+		return
+	}
+	userID, err := uuid.Parse(r.URL.Query().Get("user_id"))
+	if err != nil {
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.chatService.UnbanUser(r.Context(), streamID, userID, claims.UserID)
+	if errors.Is(err, repository.ErrBanNotFound) {
+		http.Error(w, "Ban not found", http.StatusNotFound)
+		return
+	}
+	if isForbidden(err) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error unbanning user", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteMessage удаляет сообщение чата стрима stream_id. Причина передается параметром reason
+func (h *ChatHandler) DeleteMessage(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	streamID, err := uuid.Parse(r.URL.Query().Get("stream_id"))
+	if err != nil {
+		http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+		return
+	}
+
+	messageID, err := uuid.Parse(r.PathValue("message_id"))
+	if err != nil {
+		http.Error(w, "Invalid message_id", http.StatusBadRequest)
+		return
+	}
+
+	_, err = h.chatService.DeleteMessage(r.Context(), streamID, messageID, claims.UserID, r.URL.Query().Get("reason"))
+	switch {
+	case errors.Is(err, repository.ErrMessageNotFound):
+		http.Error(w, "Message not found", http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrBanReasonTooLong):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case isForbidden(err):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
+		http.Error(w, "Error deleting message", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// clearRequest — тело запроса на очистку сообщений пользователя
+type clearRequest struct {
+	StreamID uuid.UUID `json:"stream_id"`
+	UserID   uuid.UUID `json:"user_id"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+// ClearUserMessages удаляет недавние сообщения пользователя в чате стрима
+func (h *ChatHandler) ClearUserMessages(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req clearRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.StreamID == uuid.Nil || req.UserID == uuid.Nil {
+		http.Error(w, "stream_id and user_id are required", http.StatusBadRequest)
+		return
+	}
+
+	ids, err := h.chatService.ClearUserMessages(r.Context(), req.StreamID, req.UserID, claims.UserID, req.Reason)
+	if errors.Is(err, service.ErrBanReasonTooLong) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if isForbidden(err) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error clearing messages", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"deleted_message_ids": ids})
+}
+
+// isForbidden сообщает, что действие отклонено из-за роли пользователя в чате
+func isForbidden(err error) bool {
+	return errors.Is(err, service.ErrForbidden) || errors.Is(err, service.ErrTargetOutranks)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+)
+
+// modesRequest — тело запроса на изменение режимов чата
+type modesRequest struct {
+	StreamID uuid.UUID `json:"stream_id"`
+	entity.ChatModes
+}
+
+// GetChatModes возвращает режимы чата стрима
+func (h *ChatHandler) GetChatModes(w http.ResponseWriter, r *http.Request) {
+	streamID, err := uuid.Parse(r.URL.Query().Get("stream_id"))
+	if err != nil {
+		http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+		return
+	}
+
+	modes, err := h.chatService.GetChatModes(r.Context(), streamID)
+	if err != nil {
+		http.Error(w, "Error receiving chat modes", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, modes)
+}
+
+// SetChatModes включает и выключает режимы чата стрима
+func (h *ChatHandler) SetChatModes(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req modesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.StreamID == uuid.Nil {
+		http.Error(w, "stream_id is required", http.StatusBadRequest)
+		return
+	}
+
+	err = h.chatService.SetChatModes(r.Context(), req.StreamID, claims.UserID, req.ChatModes)
+	switch {
+	case errors.Is(err, service.ErrInvalidChatModes), errors.Is(err, service.ErrAudienceUnavailable):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case isForbidden(err):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
+		http.Error(w, "Error updating chat modes", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, req.ChatModes)
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+)
+
+// pinRequest — тело запроса на закрепление сообщения
+type pinRequest struct {
+	StreamID  uuid.UUID `json:"stream_id"`
+	MessageID uuid.UUID `json:"message_id"`
+	Duration  string    `json:"duration,omitempty"` // Например "10m", пусто — без срока
+}
+
+// announcementRequest — тело запроса на отправку объявления
+type announcementRequest struct {
+	StreamID uuid.UUID `json:"stream_id"`
+	Content  string    `json:"content"`
+	Color    string    `json:"color,omitempty"` // primary, blue, green, orange или purple
+}
+
+// GetPinnedMessage возвращает закрепленное сообщение чата стрима или null
+func (h *ChatHandler) GetPinnedMessage(w http.ResponseWriter, r *http.Request) {
+	streamID, err := uuid.Parse(r.URL.Query().Get("stream_id"))
+	if err != nil {
+		http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+		return
+	}
+
+	pin, err := h.chatService.GetPinnedMessage(r.Context(), streamID)
+	if err != nil {
+		http.Error(w, "Error receiving pinned message", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, pin)
+}
+
+// PinMessage закрепляет сообщение над чатом стрима
+func (h *ChatHandler) PinMessage(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req pinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.StreamID == uuid.Nil || req.MessageID == uuid.Nil {
+		http.Error(w, "stream_id and message_id are required", http.StatusBadRequest)
+		return
+	}
+	var duration time.Duration
+	if req.Duration != "" {
+		if duration, err = time.ParseDuration(req.Duration); err != nil {
+			http.Error(w, "Invalid duration", http.StatusBadRequest)
+			return
+		}
+	}
+
+	pin, err := h.chatService.PinMessage(r.Context(), req.StreamID, claims.UserID, req.MessageID, duration)
+	switch {
+	case errors.Is(err, service.ErrInvalidPinDuration):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrMessageNotFound):
+		http.Error(w, "Message not found", http.StatusNotFound)
+	case isForbidden(err):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case err != nil:
+		http.Error(w, "Error pinning message", http.StatusInternalServerError)
+	default:
+		writeJSON(w, http.StatusOK, pin)
+	}
+}
+
+// UnpinMessage снимает закрепленное сообщение чата стрима
+func (h *ChatHandler) UnpinMessage(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	streamID, err := uuid.Parse(r.URL.Query().Get("stream_id"))
+	if err != nil {
+		http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.chatService.UnpinMessage(r.Context(), streamID, claims.UserID)
+	switch {
+	case errors.Is(err, service.ErrNoPinnedMessage):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case isForbidden(err):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case err != nil:
+		http.Error(w, "Error unpinning message", http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// Announce отправляет объявление в чат стрима
+func (h *ChatHandler) Announce(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req announcementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.StreamID == uuid.Nil {
+		http.Error(w, "stream_id is required", http.StatusBadRequest)
+		return
+	}
+
+	msg, err := h.chatService.Announce(r.Context(), req.StreamID, claims.UserID, claims.Username, req.Content, req.Color)
+	switch {
+	case errors.Is(err, service.ErrInvalidAnnouncement), errors.Is(err, service.ErrInvalidColor):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case isForbidden(err):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case err != nil:
+		http.Error(w, "Error sending announcement", http.StatusInternalServerError)
+	default:
+		writeJSON(w, http.StatusCreated, msg)
+	}
+}
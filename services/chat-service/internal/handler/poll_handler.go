@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+)
+
+// pollRequest — тело запроса на создание опроса
+type pollRequest struct {
+	StreamID uuid.UUID `json:"stream_id"`
+	Question string    `json:"question"`
+	Options  []string  `json:"options"`
+	Duration string    `json:"duration"` // Например "90s" или "5m"
+}
+
+// voteRequest — тело запроса на голосование: номер варианта ответа с нуля
+type voteRequest struct {
+	Option *int `json:"option"`
+}
+
+// CreatePoll запускает опрос в чате стрима
+func (h *ChatHandler) CreatePoll(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req pollRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.StreamID == uuid.Nil {
+		http.Error(w, "stream_id is required", http.StatusBadRequest)
+		return
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil {
+		http.Error(w, "Invalid duration", http.StatusBadRequest)
+		return
+	}
+
+	poll, err := h.chatService.CreatePoll(r.Context(), req.StreamID, claims.UserID, req.Question, req.Options, duration)
+	switch {
+	case errors.Is(err, service.ErrInvalidPoll):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrPollInProgress):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case isForbidden(err):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case err != nil:
+		http.Error(w, "Error creating poll", http.StatusInternalServerError)
+	default:
+		writeJSON(w, http.StatusCreated, poll)
+	}
+}
+
+// ListPolls возвращает опросы чата стрима с итогами, начиная с последнего
+func (h *ChatHandler) ListPolls(w http.ResponseWriter, r *http.Request) {
+	streamID, err := uuid.Parse(r.URL.Query().Get("stream_id"))
+	if err != nil {
+		http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+		return
+	}
+
+	polls, err := h.chatService.ListPolls(r.Context(), streamID)
+	if err != nil {
+		http.Error(w, "Error receiving polls", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, polls)
+}
+
+// VotePoll засчитывает голос пользователя в опросе
+func (h *ChatHandler) VotePoll(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	pollID, err := uuid.Parse(r.PathValue("poll_id"))
+	if err != nil {
+		http.Error(w, "Invalid poll_id", http.StatusBadRequest)
+		return
+	}
+	var req voteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Option == nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.chatService.Vote(r.Context(), pollID, claims.UserID, *req.Option)
+	switch {
+	case errors.Is(err, repository.ErrPollNotFound):
+		http.Error(w, "Poll not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidPollOption):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrPollClosed),
+		errors.Is(err, service.ErrAlreadyVoted):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrVoterBanned):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case err != nil:
+		http.Error(w, "Error voting in poll", http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// EndPoll досрочно завершает опрос и возвращает его итоги
+func (h *ChatHandler) EndPoll(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	pollID, err := uuid.Parse(r.PathValue("poll_id"))
+	if err != nil {
+		http.Error(w, "Invalid poll_id", http.StatusBadRequest)
+		return
+	}
+
+	poll, err := h.chatService.EndPoll(r.Context(), pollID, claims.UserID)
+	switch {
+	case errors.Is(err, repository.ErrPollNotFound):
+		http.Error(w, "Poll not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrPollClosed):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case isForbidden(err):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case err != nil:
+		http.Error(w, "Error ending poll", http.StatusInternalServerError)
+	default:
+		writeJSON(w, http.StatusOK, poll)
+	}
+}
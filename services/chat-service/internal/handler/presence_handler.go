@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// ListChatters возвращает пользователей в чате стрима, сгруппированных по ролям.
+// Параметры: stream_id, offset и limit
+func (h *ChatHandler) ListChatters(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	streamID, err := uuid.Parse(params.Get("stream_id"))
+	if err != nil {
+		http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+		return
+	}
+
+	var offset, limit int
+	if value := params.Get("offset"); value != "" {
+		offset, err = strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			http.Error(w, "Invalid offset", http.StatusBadRequest)
+			return
+		}
+	}
+	if value := params.Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := h.chatService.ListChatters(r.Context(), streamID, offset, limit)
+	if err != nil {
+		http.Error(w, "Error receiving chatters", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+)
+
+// reactionRequest — тело запроса на реакцию
+type reactionRequest struct {
+	StreamID uuid.UUID `json:"stream_id"`
+	Reaction string    `json:"reaction"` // Код эмоута или эмодзи
+}
+
+// GetReactions возвращает счетчики реакций на сообщение. Для авторизованного
+// пользователя в ответе есть и его реакция
+func (h *ChatHandler) GetReactions(w http.ResponseWriter, r *http.Request) {
+	streamID, err := uuid.Parse(r.URL.Query().Get("stream_id"))
+	if err != nil {
+		http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+		return
+	}
+	messageID, err := uuid.Parse(r.PathValue("message_id"))
+	if err != nil {
+		http.Error(w, "Invalid message_id", http.StatusBadRequest)
+		return
+	}
+	var userID uuid.UUID
+	if claims, err := h.authenticate(r); err == nil {
+		userID = claims.UserID
+	}
+
+	reactions, err := h.chatService.GetReactions(r.Context(), streamID, messageID, userID)
+	h.writeReactions(w, reactions, err)
+}
+
+// React ставит реакцию пользователя на сообщение вместо прежней
+func (h *ChatHandler) React(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	messageID, err := uuid.Parse(r.PathValue("message_id"))
+	if err != nil {
+		http.Error(w, "Invalid message_id", http.StatusBadRequest)
+		return
+	}
+	var req reactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.StreamID == uuid.Nil {
+		http.Error(w, "stream_id is required", http.StatusBadRequest)
+		return
+	}
+
+	reactions, err := h.chatService.React(r.Context(), req.StreamID, messageID, claims.UserID, req.Reaction)
+	h.writeReactions(w, reactions, err)
+}
+
+// RemoveReaction снимает реакцию пользователя с сообщения
+func (h *ChatHandler) RemoveReaction(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	streamID, err := uuid.Parse(r.URL.Query().Get("stream_id"))
+	if err != nil {
+		http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+		return
+	}
+	messageID, err := uuid.Parse(r.PathValue("message_id"))
+	if err != nil {
+		http.Error(w, "Invalid message_id", http.StatusBadRequest)
+		return
+	}
+
+	reactions, err := h.chatService.RemoveReaction(r.Context(), streamID, messageID, claims.UserID)
+	h.writeReactions(w, reactions, err)
+}
+
+// writeReactions отвечает счетчиками реакций или ошибкой операции с реакцией
+func (h *ChatHandler) writeReactions(w http.ResponseWriter, reactions *entity.MessageReactions, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidReaction):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrReactionMessageNotFound):
+		http.Error(w, "Message not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrReactionExpired):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrReactorBanned):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case err != nil:
+		http.Error(w, "Error processing reaction", http.StatusInternalServerError)
+	default:
+		writeJSON(w, http.StatusOK, reactions)
+	}
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+)
+
+// roleRequest — тело запроса на выдачу роли модератора или VIP
+type roleRequest struct {
+	StreamID uuid.UUID   `json:"stream_id"`
+	UserID   uuid.UUID   `json:"user_id"`
+	Role     entity.Role `json:"role"`
+}
+
+// GrantRole выдает пользователю роль в чате стрима
+func (h *ChatHandler) GrantRole(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req roleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.StreamID == uuid.Nil || req.UserID == uuid.Nil {
+		http.Error(w, "stream_id and user_id are required", http.StatusBadRequest)
+		return
+	}
+
+	role, err := h.chatService.GrantRole(r.Context(), req.StreamID, req.UserID, claims.UserID, req.Role)
+	switch {
+	case errors.Is(err, service.ErrRoleNotGrantable),
+		errors.Is(err, service.ErrBroadcasterRole):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case isForbidden(err):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
+		http.Error(w, "Error granting role", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, role)
+}
+
+// ListRoles возвращает модераторов и VIP чата стрима
+func (h *ChatHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	if _, err := h.authenticate(r); err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	streamID, err := uuid.Parse(r.URL.Query().Get("stream_id"))
+	if err != nil {
+		http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+		return
+	}
+
+	roles, err := h.chatService.ListRoles(r.Context(), streamID)
+	if err != nil {
+		http.Error(w, "Error receiving roles", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, roles)
+}
+
+// RevokeRole снимает с пользователя роль в чате стрима
+func (h *ChatHandler) RevokeRole(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	streamID, err := uuid.Parse(r.URL.Query().Get("stream_id"))
+	if err != nil {
+		http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+		return
+	}
+	userID, err := uuid.Parse(r.URL.Query().Get("user_id"))
+	if err != nil {
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.chatService.RevokeRole(r.Context(), streamID, userID, claims.UserID)
+	switch {
+	case errors.Is(err, repository.ErrRoleNotFound):
+		http.Error(w, "Role not found", http.StatusNotFound)
+		return
+	case isForbidden(err):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
+		http.Error(w, "Error revoking role", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
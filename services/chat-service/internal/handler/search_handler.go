@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+)
+
+// SearchMessages ищет сообщения для модераторов. Параметры: q (слова для поиска),
+// stream_id, user_id, since и until (RFC 3339), deleted (true или false),
+// before (next_cursor предыдущей страницы) и limit
+func (h *ChatHandler) SearchMessages(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	params := r.URL.Query()
+	search := entity.MessageSearch{Text: params.Get("q")}
+
+	if value := params.Get("stream_id"); value != "" {
+		if search.StreamID, err = uuid.Parse(value); err != nil {
+			http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+			return
+		}
+	}
+	if value := params.Get("user_id"); value != "" {
+		if search.UserID, err = uuid.Parse(value); err != nil {
+			http.Error(w, "Invalid user_id", http.StatusBadRequest)
+			return
+		}
+	}
+	if value := params.Get("since"); value != "" {
+		if search.Since, err = time.Parse(time.RFC3339Nano, value); err != nil {
+			http.Error(w, "Invalid since", http.StatusBadRequest)
+			return
+		}
+	}
+	if value := params.Get("until"); value != "" {
+		if search.Until, err = time.Parse(time.RFC3339Nano, value); err != nil {
+			http.Error(w, "Invalid until", http.StatusBadRequest)
+			return
+		}
+	}
+	if value := params.Get("deleted"); value != "" {
+		deleted, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "Invalid deleted", http.StatusBadRequest)
+			return
+		}
+		search.Deleted = &deleted
+	}
+	if search.Before, err = entity.ParseMessageCursor(params.Get("before")); err != nil {
+		http.Error(w, "Invalid before cursor", http.StatusBadRequest)
+		return
+	}
+	if value := params.Get("limit"); value != "" {
+		search.Limit, err = strconv.Atoi(value)
+		if err != nil || search.Limit <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := h.chatService.SearchMessages(r.Context(), claims.UserID, search)
+	switch {
+	case errors.Is(err, service.ErrInvalidSearchRange),
+		errors.Is(err, service.ErrSearchTextTooLong):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case isForbidden(err):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, repository.ErrMessageNotFound):
+		http.Error(w, "Cursor message not found", http.StatusBadRequest)
+	case err != nil:
+		http.Error(w, "Error searching messages", http.StatusInternalServerError)
+	default:
+		writeJSON(w, http.StatusOK, page)
+	}
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+)
+
+// GetVODChat возвращает сообщения чата для отрезка записи трансляции.
+// Параметры: stream_id, from и to (секунды от начала трансляции),
+// after (ID последнего полученного сообщения отрезка) и limit
+func (h *ChatHandler) GetVODChat(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	streamID, err := uuid.Parse(params.Get("stream_id"))
+	if err != nil {
+		http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+		return
+	}
+	from, err := parseOffset(params.Get("from"))
+	if err != nil {
+		http.Error(w, "Invalid from", http.StatusBadRequest)
+		return
+	}
+	to, err := parseOffset(params.Get("to"))
+	if err != nil {
+		http.Error(w, "Invalid to", http.StatusBadRequest)
+		return
+	}
+
+	var after uuid.UUID
+	if value := params.Get("after"); value != "" {
+		if after, err = uuid.Parse(value); err != nil {
+			http.Error(w, "Invalid after", http.StatusBadRequest)
+			return
+		}
+	}
+	var limit int
+	if value := params.Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := h.chatService.GetVODChat(r.Context(), streamID, from, to, after, limit)
+	switch {
+	case errors.Is(err, service.ErrInvalidVODWindow):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrStreamNotStarted):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, repository.ErrMessageNotFound):
+		http.Error(w, "Cursor message not found", http.StatusBadRequest)
+	case err != nil:
+		http.Error(w, "Error receiving messages", http.StatusInternalServerError)
+	default:
+		writeJSON(w, http.StatusOK, page)
+	}
+}
+
+// parseOffset разбирает отступ от начала трансляции в секундах, допускаются дроби
+func parseOffset(value string) (time.Duration, error) {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if seconds < 0 || math.IsNaN(seconds) || seconds > float64(math.MaxInt64)/float64(time.Second) {
+		return 0, errors.New("offset must be a non-negative number of seconds")
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+)
+
+// blockRequest — тело запроса на блокировку личных сообщений
+type blockRequest struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// ListConversations возвращает входящие: переписки пользователя с последними
+// сообщениями. Параметры: before (updated_at последней полученной переписки в RFC 3339) и limit
+func (h *ChatHandler) ListConversations(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	params := r.URL.Query()
+	var before time.Time
+	if value := params.Get("before"); value != "" {
+		if before, err = time.Parse(time.RFC3339Nano, value); err != nil {
+			http.Error(w, "Invalid before", http.StatusBadRequest)
+			return
+		}
+	}
+	limit, ok := parseLimit(w, params.Get("limit"))
+	if !ok {
+		return
+	}
+
+	page, err := h.chatService.ListConversations(r.Context(), claims.UserID, before, limit)
+	if err != nil {
+		http.Error(w, "Error receiving conversations", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// GetWhispers возвращает переписку с пользователем user_id.
+// Параметры: before (ID сообщения или время в RFC 3339) и limit
+func (h *ChatHandler) GetWhispers(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	otherID, err := uuid.Parse(r.PathValue("user_id"))
+	if err != nil {
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+		return
+	}
+	params := r.URL.Query()
+	before, err := entity.ParseMessageCursor(params.Get("before"))
+	if err != nil {
+		http.Error(w, "Invalid before cursor", http.StatusBadRequest)
+		return
+	}
+	limit, ok := parseLimit(w, params.Get("limit"))
+	if !ok {
+		return
+	}
+
+	page, err := h.chatService.GetWhispers(r.Context(), claims.UserID, otherID, before, limit)
+	if errors.Is(err, repository.ErrMessageNotFound) {
+		http.Error(w, "Cursor message not found", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error receiving whispers", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// BlockUser запрещает личные сообщения между пользователем и user_id
+func (h *ChatHandler) BlockUser(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req blockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == uuid.Nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	block, err := h.chatService.BlockUser(r.Context(), claims.UserID, req.UserID)
+	if errors.Is(err, service.ErrCannotBlockSelf) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error blocking user", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, block)
+}
+
+// ListBlocks возвращает пользователей, заблокированных пользователем
+func (h *ChatHandler) ListBlocks(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	blocks, err := h.chatService.ListBlocks(r.Context(), claims.UserID)
+	if err != nil {
+		http.Error(w, "Error receiving blocks", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, blocks)
+}
+
+// UnblockUser снимает блокировку личных сообщений с пользователя user_id
+func (h *ChatHandler) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	blockedID, err := uuid.Parse(r.PathValue("user_id"))
+	if err != nil {
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.chatService.UnblockUser(r.Context(), claims.UserID, blockedID)
+	if errors.Is(err, repository.ErrBlockNotFound) {
+		http.Error(w, "Block not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error unblocking user", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseLimit разбирает необязательный размер страницы. При ошибке отвечает 400 и возвращает false
+func parseLimit(w http.ResponseWriter, value string) (int, bool) {
+	if value == "" {
+		return 0, true
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		http.Error(w, "Invalid limit", http.StatusBadRequest)
+		return 0, false
+	}
+	return limit, true
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/google/uuid"
+)
+
+// ChatBlockedTerm хранит запрещенные выражения AutoMod
+type ChatBlockedTerm struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	StreamID  uuid.UUID `gorm:"type:uuid"`
+	Pattern   string
+	Action    string
+	CreatedBy uuid.UUID `gorm:"type:uuid"`
+	CreatedAt time.Time
+}
+
+// NewChatBlockedTermModel конвертирует бизнес-сущность в запись PostgreSQL
+func NewChatBlockedTermModel(term *entity.BlockedTerm) *ChatBlockedTerm {
+	return &ChatBlockedTerm{
+		ID:        term.ID,
+		StreamID:  term.StreamID,
+		Pattern:   term.Pattern,
+		Action:    string(term.Action),
+		CreatedBy: term.CreatedBy,
+		CreatedAt: term.CreatedAt,
+	}
+}
+
+// ToEntity конвертирует в бизнес-сущность
+func (t *ChatBlockedTerm) ToEntity() *entity.BlockedTerm {
+	return &entity.BlockedTerm{
+		ID:        t.ID,
+		StreamID:  t.StreamID,
+		Pattern:   t.Pattern,
+		Action:    entity.AutoModAction(t.Action),
+		CreatedBy: t.CreatedBy,
+		CreatedAt: t.CreatedAt,
+	}
+}
+
+// ChatAutoModSettings хранит настройки фильтров AutoMod чата стрима
+type ChatAutoModSettings struct {
+	StreamID               uuid.UUID `gorm:"type:uuid;primaryKey"`
+	CapsPercent            int
+	MaxRepeatedChars       int
+	DuplicateWindowSeconds int
+	BlockLinks             bool
+	LinkAction             string
+	LinkAllowlist          []string `gorm:"serializer:json"`
+	UpdatedAt              time.Time
+}
+
+// TableName задает имя таблицы настроек AutoMod
+func (ChatAutoModSettings) TableName() string {
+	return "chat_automod_settings"
+}
+
+// NewChatAutoModSettingsModel конвертирует бизнес-сущность в запись PostgreSQL
+func NewChatAutoModSettingsModel(settings *entity.AutoModSettings) *ChatAutoModSettings {
+	return &ChatAutoModSettings{
+		StreamID:               settings.StreamID,
+		CapsPercent:            settings.CapsPercent,
+		MaxRepeatedChars:       settings.MaxRepeatedChars,
+		DuplicateWindowSeconds: settings.DuplicateWindowSeconds,
+		BlockLinks:             settings.BlockLinks,
+		LinkAction:             string(settings.LinkAction),
+		LinkAllowlist:          settings.LinkAllowlist,
+		UpdatedAt:              time.Now().UTC(),
+	}
+}
+
+// ToEntity конвертирует в бизнес-сущность
+func (s *ChatAutoModSettings) ToEntity() *entity.AutoModSettings {
+	return &entity.AutoModSettings{
+		StreamID:               s.StreamID,
+		CapsPercent:            s.CapsPercent,
+		MaxRepeatedChars:       s.MaxRepeatedChars,
+		DuplicateWindowSeconds: s.DuplicateWindowSeconds,
+		BlockLinks:             s.BlockLinks,
+		LinkAction:             entity.AutoModAction(s.LinkAction),
+		LinkAllowlist:          s.LinkAllowlist,
+	}
+}
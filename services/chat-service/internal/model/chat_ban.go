@@ -3,13 +3,43 @@ package model
 import (
 	"time"
 
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
 	"github.com/google/uuid"
 )
 
-// ChatBan хранит информацию о забаненных пользователях
+// ChatBan хранит информацию о банах и таймаутах пользователей
 type ChatBan struct {
-	ID       uuid.UUID `gorm:"type:uuid;primaryKey"`
-	StreamID uuid.UUID `gorm:"type:uuid;index"`
-	UserID   uuid.UUID `gorm:"type:uuid;index"`
-	BannedAt time.Time
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	StreamID    uuid.UUID `gorm:"type:uuid;index"`
+	UserID      uuid.UUID `gorm:"type:uuid;index"`
+	ModeratorID uuid.UUID `gorm:"type:uuid"`
+	Reason      string
+	BannedAt    time.Time
+	ExpiresAt   *time.Time `gorm:"index"` // NULL для постоянного бана
+}
+
+// NewChatBanModel конвертирует бизнес-сущность в запись PostgreSQL
+func NewChatBanModel(ban *entity.ChatBan) *ChatBan {
+	return &ChatBan{
+		ID:          ban.ID,
+		StreamID:    ban.StreamID,
+		UserID:      ban.UserID,
+		ModeratorID: ban.ModeratorID,
+		Reason:      ban.Reason,
+		BannedAt:    ban.CreatedAt,
+		ExpiresAt:   ban.ExpiresAt,
+	}
+}
+
+// ToEntity конвертирует в бизнес-сущность
+func (b *ChatBan) ToEntity() *entity.ChatBan {
+	return &entity.ChatBan{
+		ID:          b.ID,
+		StreamID:    b.StreamID,
+		UserID:      b.UserID,
+		ModeratorID: b.ModeratorID,
+		Reason:      b.Reason,
+		CreatedAt:   b.BannedAt,
+		ExpiresAt:   b.ExpiresAt,
+	}
 }
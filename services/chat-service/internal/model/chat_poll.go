@@ -0,0 +1,68 @@
+package model
+
+import (
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/google/uuid"
+)
+
+// ChatPollOption — вариант ответа опроса в JSONB-колонке options
+type ChatPollOption struct {
+	Text  string `json:"text"`
+	Votes int    `json:"votes"`
+}
+
+// ChatPoll хранит опросы чатов стримов и их итоги
+type ChatPoll struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	StreamID   uuid.UUID `gorm:"type:uuid"`
+	Question   string
+	Options    []ChatPollOption `gorm:"serializer:json"`
+	TotalVotes int
+	Status     string
+	CreatedBy  uuid.UUID `gorm:"type:uuid"`
+	CreatedAt  time.Time
+	EndsAt     time.Time
+	EndedAt    *time.Time
+}
+
+// NewChatPollModel конвертирует бизнес-сущность в запись PostgreSQL
+func NewChatPollModel(poll *entity.Poll) *ChatPoll {
+	options := make([]ChatPollOption, 0, len(poll.Options))
+	for _, option := range poll.Options {
+		options = append(options, ChatPollOption{Text: option.Text, Votes: option.Votes})
+	}
+	return &ChatPoll{
+		ID:         poll.ID,
+		StreamID:   poll.StreamID,
+		Question:   poll.Question,
+		Options:    options,
+		TotalVotes: poll.TotalVotes,
+		Status:     string(poll.Status),
+		CreatedBy:  poll.CreatedBy,
+		CreatedAt:  poll.CreatedAt,
+		EndsAt:     poll.EndsAt,
+		EndedAt:    poll.EndedAt,
+	}
+}
+
+// ToEntity конвертирует в бизнес-сущность
+func (p *ChatPoll) ToEntity() *entity.Poll {
+	options := make([]entity.PollOption, 0, len(p.Options))
+	for _, option := range p.Options {
+		options = append(options, entity.PollOption{Text: option.Text, Votes: option.Votes})
+	}
+	return &entity.Poll{
+		ID:         p.ID,
+		StreamID:   p.StreamID,
+		Question:   p.Question,
+		Options:    options,
+		TotalVotes: p.TotalVotes,
+		Status:     entity.PollStatus(p.Status),
+		CreatedBy:  p.CreatedBy,
+		CreatedAt:  p.CreatedAt,
+		EndsAt:     p.EndsAt,
+		EndedAt:    p.EndedAt,
+	}
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/google/uuid"
+)
+
+// ChatRole хранит роли модераторов и VIP в чатах стримов
+type ChatRole struct {
+	StreamID  uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Role      string
+	GrantedBy uuid.UUID `gorm:"type:uuid"`
+	GrantedAt time.Time
+}
+
+// NewChatRoleModel конвертирует бизнес-сущность в запись PostgreSQL
+func NewChatRoleModel(role *entity.ChatRole) *ChatRole {
+	return &ChatRole{
+		StreamID:  role.StreamID,
+		UserID:    role.UserID,
+		Role:      string(role.Role),
+		GrantedBy: role.GrantedBy,
+		GrantedAt: role.GrantedAt,
+	}
+}
+
+// ToEntity конвертирует в бизнес-сущность
+func (r *ChatRole) ToEntity() *entity.ChatRole {
+	return &entity.ChatRole{
+		StreamID:  r.StreamID,
+		UserID:    r.UserID,
+		Role:      entity.Role(r.Role),
+		GrantedBy: r.GrantedBy,
+		GrantedAt: r.GrantedAt,
+	}
+}
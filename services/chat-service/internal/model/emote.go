@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/google/uuid"
+)
+
+// ChatEmote хранит эмоуты каналов и глобального набора
+type ChatEmote struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	StreamID  uuid.UUID `gorm:"type:uuid"` // Нулевой UUID — глобальный набор
+	Code      string
+	Format    string
+	CreatedBy uuid.UUID `gorm:"type:uuid"`
+	CreatedAt time.Time
+}
+
+// NewChatEmoteModel конвертирует бизнес-сущность в запись PostgreSQL
+func NewChatEmoteModel(emote *entity.Emote) *ChatEmote {
+	return &ChatEmote{
+		ID:        emote.ID,
+		StreamID:  emote.StreamID,
+		Code:      emote.Code,
+		Format:    emote.Format,
+		CreatedBy: emote.CreatedBy,
+		CreatedAt: emote.CreatedAt,
+	}
+}
+
+// ToEntity конвертирует в бизнес-сущность
+func (e *ChatEmote) ToEntity() *entity.Emote {
+	return &entity.Emote{
+		ID:        e.ID,
+		StreamID:  e.StreamID,
+		Code:      e.Code,
+		Format:    e.Format,
+		CreatedBy: e.CreatedBy,
+		CreatedAt: e.CreatedAt,
+	}
+}
@@ -8,14 +8,71 @@ import (
 )
 
 type ChatMessage struct {
-	ID        uuid.UUID `bson:"_id"`       // ObjectID для MongoDB
-	StreamID  uuid.UUID `bson:"stream_id"` // streams.id
-	UserID    uuid.UUID `bson:"user_id"`   // users.id
-	Username  string    `bson:"username"`  // users.username
+	ID        uuid.UUID         `bson:"_id"`       // ObjectID для MongoDB
+	StreamID  uuid.UUID         `bson:"stream_id"` // streams.id
+	UserID    uuid.UUID         `bson:"user_id"`   // users.id
+	Username  string            `bson:"username"`  // users.username
+	Content   string            `bson:"content"`
+	Timestamp time.Time         `bson:"sent_at"`
+	Seq       int64             `bson:"seq,omitempty"`       // Порядковый номер в комнате
+	Badges    []string          `bson:"badges,omitempty"`    // Значки ролей на момент отправки
+	Kind      string            `bson:"kind,omitempty"`      // Вид сообщения, пусто для обычного
+	Color     string            `bson:"color,omitempty"`     // Цвет оформления объявления
+	ReplyTo   *MessageReply     `bson:"reply_to,omitempty"`  // Снимок сообщения, на которое дан ответ
+	Mentions  []MessageMention  `bson:"mentions,omitempty"`  // Упомянутые пользователи
+	Fragments []MessageFragment `bson:"fragments,omitempty"` // Фрагменты текста и эмоутов
+	Reactions map[string]int    `bson:"reactions,omitempty"` // Число реакций по видам
+	IsDeleted bool              `bson:"is_deleted"`
+	ModReason string            `bson:"mod_reason,omitempty"`
+	DeletedBy uuid.UUID         `bson:"deleted_by,omitempty"` // Модератор, удаливший сообщение
+	DeletedAt *time.Time        `bson:"deleted_at,omitempty"`
+}
+
+// MessageReply — снимок исходного сообщения в документе ответа
+type MessageReply struct {
+	MessageID uuid.UUID `bson:"message_id"`
+	ThreadID  uuid.UUID `bson:"thread_id"`
+	UserID    uuid.UUID `bson:"user_id"`
+	Username  string    `bson:"username"`
 	Content   string    `bson:"content"`
-	Timestamp time.Time `bson:"sent_at"`
-	IsDeleted bool      `bson:"is_deleted"`
-	ModReason string    `bson:"mod_reason,omitempty"`
+}
+
+// MessageMention — упомянутый в сообщении пользователь
+type MessageMention struct {
+	UserID   uuid.UUID `bson:"user_id"`
+	Username string    `bson:"username"`
+}
+
+// MessageFragment — фрагмент текста или эмоут в документе сообщения
+type MessageFragment struct {
+	Type    string     `bson:"type"`
+	Text    string     `bson:"text"`
+	Start   int        `bson:"start"`
+	End     int        `bson:"end"`
+	EmoteID *uuid.UUID `bson:"emote_id,omitempty"`
+}
+
+// NewChatMessageModel конвертирует бизнес-сущность в документ MongoDB
+func NewChatMessageModel(msg *entity.ChatMessage) *ChatMessage {
+	return &ChatMessage{
+		ID:        msg.ID,
+		StreamID:  msg.StreamID,
+		UserID:    msg.UserID,
+		Username:  msg.Username,
+		Content:   msg.Content,
+		Timestamp: msg.Timestamp,
+		Seq:       msg.Seq,
+		Badges:    msg.Badges,
+		Kind:      string(msg.Kind),
+		Color:     msg.Color,
+		ReplyTo:   newMessageReply(msg.ReplyTo),
+		Mentions:  newMessageMentions(msg.Mentions),
+		Fragments: newMessageFragments(msg.Fragments),
+		Reactions: msg.Reactions,
+		IsDeleted: msg.IsDeleted,
+		ModReason: msg.ModReason,
+		DeletedBy: msg.DeletedBy,
+	}
 }
 
 // ToEntity конвертирует в бизнес-сущность
@@ -27,6 +84,92 @@ func (cm *ChatMessage) ToEntity() *entity.ChatMessage {
 		Username:  cm.Username,
 		Content:   cm.Content,
 		Timestamp: cm.Timestamp,
+		Seq:       cm.Seq,
+		Badges:    cm.Badges,
+		Kind:      entity.MessageKind(cm.Kind),
+		Color:     cm.Color,
+		ReplyTo:   cm.ReplyTo.toEntity(),
+		Mentions:  mentionsToEntity(cm.Mentions),
+		Fragments: fragmentsToEntity(cm.Fragments),
+		Reactions: cm.Reactions,
 		IsDeleted: cm.IsDeleted,
+		DeletedBy: cm.DeletedBy,
+		ModReason: cm.ModReason,
+	}
+}
+
+// newMessageReply конвертирует снимок исходного сообщения для документа
+func newMessageReply(reply *entity.ReplyContext) *MessageReply {
+	if reply == nil {
+		return nil
+	}
+	return &MessageReply{
+		MessageID: reply.MessageID,
+		ThreadID:  reply.ThreadID,
+		UserID:    reply.UserID,
+		Username:  reply.Username,
+		Content:   reply.Content,
+	}
+}
+
+// toEntity конвертирует снимок исходного сообщения в бизнес-сущность
+func (r *MessageReply) toEntity() *entity.ReplyContext {
+	if r == nil {
+		return nil
+	}
+	return &entity.ReplyContext{
+		MessageID: r.MessageID,
+		ThreadID:  r.ThreadID,
+		UserID:    r.UserID,
+		Username:  r.Username,
+		Content:   r.Content,
+	}
+}
+
+// newMessageMentions конвертирует упоминания для документа
+func newMessageMentions(mentions []entity.Mention) []MessageMention {
+	if len(mentions) == 0 {
+		return nil
+	}
+	docs := make([]MessageMention, 0, len(mentions))
+	for _, m := range mentions {
+		docs = append(docs, MessageMention{UserID: m.UserID, Username: m.Username})
+	}
+	return docs
+}
+
+// mentionsToEntity конвертирует упоминания в бизнес-сущности
+func mentionsToEntity(docs []MessageMention) []entity.Mention {
+	if len(docs) == 0 {
+		return nil
+	}
+	mentions := make([]entity.Mention, 0, len(docs))
+	for _, d := range docs {
+		mentions = append(mentions, entity.Mention{UserID: d.UserID, Username: d.Username})
+	}
+	return mentions
+}
+
+// newMessageFragments конвертирует фрагменты сообщения для документа
+func newMessageFragments(fragments []entity.MessageFragment) []MessageFragment {
+	if len(fragments) == 0 {
+		return nil
+	}
+	docs := make([]MessageFragment, 0, len(fragments))
+	for _, f := range fragments {
+		docs = append(docs, MessageFragment{Type: string(f.Type), Text: f.Text, Start: f.Start, End: f.End, EmoteID: f.EmoteID})
+	}
+	return docs
+}
+
+// fragmentsToEntity конвертирует фрагменты сообщения в бизнес-сущности
+func fragmentsToEntity(docs []MessageFragment) []entity.MessageFragment {
+	if len(docs) == 0 {
+		return nil
+	}
+	fragments := make([]entity.MessageFragment, 0, len(docs))
+	for _, d := range docs {
+		fragments = append(fragments, entity.MessageFragment{Type: entity.FragmentType(d.Type), Text: d.Text, Start: d.Start, End: d.End, EmoteID: d.EmoteID})
 	}
+	return fragments
 }
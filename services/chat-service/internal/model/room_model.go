@@ -13,6 +13,66 @@ type ChatRoom struct {
 	StreamTitle string    `bson:"stream_title"`
 	CreatedAt   time.Time `bson:"created_at"`
 	IsActive    bool      `bson:"is_active"`
+
+	// Режимы чата
+	SlowModeSeconds     int
+	FollowersOnly       bool
+	FollowersMinMinutes int
+	EmoteOnly           bool
+	SubscribersOnly     bool
+
+	// Закрепленное сообщение, NULL — ничего не закреплено
+	PinnedMessage *ChatRoomPin `gorm:"serializer:json"`
+}
+
+// ChatRoomPin — закрепленное сообщение в JSONB-колонке pinned_message
+type ChatRoomPin struct {
+	MessageID uuid.UUID  `json:"message_id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Username  string     `json:"username"`
+	Content   string     `json:"content"`
+	Kind      string     `json:"kind,omitempty"`
+	Color     string     `json:"color,omitempty"`
+	PinnedBy  uuid.UUID  `json:"pinned_by"`
+	PinnedAt  time.Time  `json:"pinned_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// NewChatRoomPin конвертирует закрепленное сообщение в значение колонки
+func NewChatRoomPin(pin *entity.PinnedMessage) *ChatRoomPin {
+	if pin == nil {
+		return nil
+	}
+	return &ChatRoomPin{
+		MessageID: pin.MessageID,
+		UserID:    pin.UserID,
+		Username:  pin.Username,
+		Content:   pin.Content,
+		Kind:      string(pin.Kind),
+		Color:     pin.Color,
+		PinnedBy:  pin.PinnedBy,
+		PinnedAt:  pin.PinnedAt,
+		ExpiresAt: pin.ExpiresAt,
+	}
+}
+
+// ToEntity конвертирует в бизнес-сущность
+func (p *ChatRoomPin) ToEntity(streamID uuid.UUID) *entity.PinnedMessage {
+	if p == nil {
+		return nil
+	}
+	return &entity.PinnedMessage{
+		StreamID:  streamID,
+		MessageID: p.MessageID,
+		UserID:    p.UserID,
+		Username:  p.Username,
+		Content:   p.Content,
+		Kind:      entity.MessageKind(p.Kind),
+		Color:     p.Color,
+		PinnedBy:  p.PinnedBy,
+		PinnedAt:  p.PinnedAt,
+		ExpiresAt: p.ExpiresAt,
+	}
 }
 
 // ToEntity конвертирует в бизнес-сущность
@@ -20,5 +80,13 @@ func (cr *ChatRoom) ToEntity() *entity.ChatRoom {
 	return &entity.ChatRoom{
 		ID:       cr.ID,
 		StreamID: cr.StreamID,
+		Modes: entity.ChatModes{
+			SlowModeSeconds:     cr.SlowModeSeconds,
+			FollowersOnly:       cr.FollowersOnly,
+			FollowersMinMinutes: cr.FollowersMinMinutes,
+			EmoteOnly:           cr.EmoteOnly,
+			SubscribersOnly:     cr.SubscribersOnly,
+		},
+		PinnedMessage: cr.PinnedMessage.ToEntity(cr.StreamID),
 	}
 }
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/google/uuid"
+)
+
+// Whisper — документ личного сообщения в MongoDB
+type Whisper struct {
+	ID             uuid.UUID `bson:"_id"`
+	ConversationID string    `bson:"conversation_id"`
+	FromUserID     uuid.UUID `bson:"from_user_id"` // users.id
+	FromUsername   string    `bson:"from_username"`
+	ToUserID       uuid.UUID `bson:"to_user_id"` // users.id
+	Content        string    `bson:"content"`
+	Timestamp      time.Time `bson:"sent_at"`
+}
+
+// NewWhisperModel конвертирует бизнес-сущность в документ MongoDB
+func NewWhisperModel(whisper *entity.Whisper) *Whisper {
+	return &Whisper{
+		ID:             whisper.ID,
+		ConversationID: whisper.ConversationID,
+		FromUserID:     whisper.FromUserID,
+		FromUsername:   whisper.FromUsername,
+		ToUserID:       whisper.ToUserID,
+		Content:        whisper.Content,
+		Timestamp:      whisper.Timestamp,
+	}
+}
+
+// ToEntity конвертирует в бизнес-сущность
+func (w *Whisper) ToEntity() *entity.Whisper {
+	return &entity.Whisper{
+		ID:             w.ID,
+		ConversationID: w.ConversationID,
+		FromUserID:     w.FromUserID,
+		FromUsername:   w.FromUsername,
+		ToUserID:       w.ToUserID,
+		Content:        w.Content,
+		Timestamp:      w.Timestamp,
+	}
+}
+
+// Conversation — документ переписки в MongoDB с последним сообщением для списка входящих
+type Conversation struct {
+	ID           string      `bson:"_id"`
+	Participants []uuid.UUID `bson:"participants"`
+	LastWhisper  *Whisper    `bson:"last_whisper"`
+	UpdatedAt    time.Time   `bson:"updated_at"`
+}
+
+// ToEntity конвертирует в переписку с точки зрения пользователя viewerID
+func (c *Conversation) ToEntity(viewerID uuid.UUID) *entity.Conversation {
+	conversation := &entity.Conversation{ID: c.ID, UpdatedAt: c.UpdatedAt}
+	for _, participant := range c.Participants {
+		if participant != viewerID {
+			conversation.UserID = participant
+		}
+	}
+	if c.LastWhisper != nil {
+		conversation.LastWhisper = c.LastWhisper.ToEntity()
+	}
+	return conversation
+}
+
+// ChatUserBlock хранит блокировки личных сообщений между пользователями
+type ChatUserBlock struct {
+	BlockerID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	BlockedID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	CreatedAt time.Time
+}
+
+// ToEntity конвертирует в бизнес-сущность
+func (b *ChatUserBlock) ToEntity() *entity.UserBlock {
+	return &entity.UserBlock{BlockerID: b.BlockerID, BlockedID: b.BlockedID, CreatedAt: b.CreatedAt}
+}
@@ -0,0 +1,211 @@
+// Package protocol описывает формат кадров WebSocket-чата.
+//
+// Каждый кадр — JSON-объект (конверт):
+//
+//	{"v": 1, "type": "send", "id": "c-42", "payload": {...}}
+//
+// v — версия протокола, type — тип кадра, id — идентификатор, который клиент
+// присваивает своим запросам и получает обратно в ack/nack, payload — данные кадра.
+//
+// Кадры клиента:
+//
+//	send             {"content": "...", "reply_to": ...}        отправить сообщение (reply_to — ответ) или выполнить команду (/ban, /slow, ...)
+//	whisper          {"to_user_id": ..., "content": "..."}      отправить личное сообщение
+//
+// Кадры сервера:
+//
+//	ack              ChatMessage                                сообщение с id клиента принято
+//	ack              {"command": "...", "message": "..."}       команда выполнена, ответ видит только отправитель
+//	nack             {"code": "...", "message": "..."}          запрос с id клиента отклонен
+//	message          ChatMessage                                новое сообщение в комнате
+//	announcement     ChatMessage                                объявление бродкастера или модератора
+//	message_deleted  {"message_ids": [...], "user_id": ...}     сообщения удалены модератором (chat_cleared — весь чат)
+//	user_banned      {"user_id": ..., "reason": ...}            пользователь забанен
+//	user_timed_out   {"user_id": ..., "expires_at": ...}        пользователь получил таймаут
+//	room_state       {"modes": {...}}                           режимы чата (при подключении и изменении)
+//	presence         {"joined": [...], "left": [...], ...}      кто вошел в чат и вышел за последние секунды
+//	resumed          {"last_seq": ..., "replayed": ..., ...}    пропущенные сообщения повторены
+//	message_pinned   PinnedMessage                              закреплено сообщение (при подключении и изменении)
+//	message_unpinned {"message_id": ..., "unpinned_by": ...}    закрепление снято
+//	poll_started     Poll                                       в чате начался опрос
+//	poll_updated     {"poll_id": ..., "votes": [...], ...}      промежуточные итоги опроса (не чаще раза в секунду)
+//	poll_ended       Poll                                       опрос завершен, итоги в options
+//	whisper          Whisper                                    личное сообщение
+//	mention          {"message_id": ..., "reply": ..., ...}     пользователя упомянули или ответили на его сообщение
+//	reactions_updated {"message_id": ..., "counts": {...}}      новые счетчики реакций на сообщение (не чаще раза в секунду)
+//
+// Текст send, начинающийся с /, разбирается как команда модерации и в чат
+// не попадает: результат получает только отправитель в ack или nack.
+//
+// Объявления нумеруются вместе с сообщениями комнаты и при повторе после
+// переподключения тоже приходят кадрами announcement. Закрепленное сообщение
+// со сроком клиент скрывает сам по наступлении expires_at, отдельного кадра
+// об истечении срока нет.
+//
+// Кадр whisper приходит на все подключения получателя и отправителя, в том
+// числе на подключение, с которого сообщение отправлено: клиент отбрасывает
+// дубль ack по id сообщения.
+//
+// Эмоуты сервер находит в тексте сам: если в сообщении есть эмоуты канала
+// или глобального набора, поле fragments содержит разбиение content на
+// фрагменты text и emote с границами в символах Unicode. Клиент рисует
+// сообщение по фрагментам, а изображения берет по emote_id из GET /emotes;
+// неизвестный emote_id — повод перезапросить набор. Без fragments
+// сообщение — обычный текст.
+//
+// Реакции ставятся через HTTP API. Кадр reactions_updated содержит счетчики
+// целиком, поэтому клиент просто заменяет ими прежние; дубли от разных
+// экземпляров сервиса безвредны.
+//
+// Упоминания @username сервер находит в тексте сам и возвращает в поле
+// mentions сообщения вместе с ID пользователей. Кадр mention приходит на все
+// подключения упомянутого пользователя и автора сообщения, на которое
+// ответили (reply: true), даже если они сейчас в другой комнате. Ответ
+// содержит в reply_to снимок исходного сообщения и thread_id — ID первого
+// сообщения цепочки.
+//
+// Сообщения комнаты нумеруются по порядку (поле seq в ChatMessage). Клиент
+// запоминает последний полученный номер и при переподключении передает его
+// в параметре last_seq: сервер сначала отправляет пропущенные сообщения
+// кадрами message, затем кадр resumed, и только потом живые сообщения.
+// Сообщения с номером не больше уже полученного клиент отбрасывает как дубли,
+// а скачок номера больше чем на единицу означает пропуск, который можно
+// восполнить переподключением с last_seq. Если resumed содержит
+// "truncated": true, более ранние сообщения загружаются из истории.
+//
+// Коды ошибок nack перечислены в константах Code*.
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// Version — текущая версия протокола
+const Version = 1
+
+// Типы кадров клиента
+const (
+	TypeSend    = "send"
+	TypeWhisper = "whisper" // Так же называется кадр сервера с личным сообщением
+)
+
+// Типы кадров сервера
+const (
+	TypeAck              = "ack"
+	TypeNack             = "nack"
+	TypeMessage          = "message"
+	TypeAnnouncement     = "announcement"
+	TypeMessageDeleted   = "message_deleted"
+	TypeUserBanned       = "user_banned"
+	TypeUserTimedOut     = "user_timed_out"
+	TypeRoomState        = "room_state"
+	TypePresence         = "presence"
+	TypeResumed          = "resumed"
+	TypeMessagePinned    = "message_pinned"
+	TypeMessageUnpinned  = "message_unpinned"
+	TypePollStarted      = "poll_started"
+	TypePollUpdated      = "poll_updated"
+	TypePollEnded        = "poll_ended"
+	TypeMention          = "mention"
+	TypeReactionsUpdated = "reactions_updated"
+)
+
+// Коды ошибок в кадре nack
+const (
+	CodeInvalidFrame       = "invalid_frame"       // Кадр не разобран
+	CodeUnsupportedVersion = "unsupported_version" // Версия протокола не поддерживается
+	CodeUnknownType        = "unknown_type"        // Неизвестный тип кадра
+	CodeInvalidMessage     = "invalid_message"     // Сообщение не прошло валидацию
+	CodeRateLimited        = "rate_limited"        // Превышен лимит сообщений
+	CodeBanned             = "banned"              // Пользователь забанен
+	CodeTimedOut           = "timed_out"           // Пользователь в таймауте
+	CodeSlowMode           = "slow_mode"           // Интервал slow mode еще не прошел
+	CodeFollowersOnly      = "followers_only"      // Чат только для фолловеров
+	CodeSubscribersOnly    = "subscribers_only"    // Чат только для подписчиков
+	CodeEmoteOnly          = "emote_only"          // Чат только для эмоутов
+	CodeAutoModRejected    = "automod_rejected"    // Сообщение отклонено AutoMod
+	CodeHeldForReview      = "held_for_review"     // Сообщение ждет решения модератора
+	CodeWhisperBlocked     = "whisper_blocked"     // Получатель не принимает личные сообщения от отправителя
+	CodeUnknownCommand     = "unknown_command"     // Команда чата не существует
+	CodeInvalidCommand     = "invalid_command"     // Команда не выполнена: неверные аргументы или цель
+	CodeForbidden          = "forbidden"           // Недостаточно прав для команды
+	CodeInternal           = "internal_error"      // Внутренняя ошибка сервера
+)
+
+// ErrUnsupportedVersion возвращается для кадров другой версии протокола
+var ErrUnsupportedVersion = errors.New("unsupported protocol version")
+
+// Envelope — конверт кадра WebSocket
+type Envelope struct {
+	Version int             `json:"v"`
+	Type    string          `json:"type"`
+	ID      string          `json:"id,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// SendPayload — данные кадра send
+type SendPayload struct {
+	Content string    `json:"content"`
+	ReplyTo uuid.UUID `json:"reply_to,omitempty"` // ID сообщения, на которое отвечает пользователь
+}
+
+// WhisperPayload — данные кадра whisper от клиента
+type WhisperPayload struct {
+	ToUserID uuid.UUID `json:"to_user_id"`
+	Content  string    `json:"content"`
+}
+
+// CommandResultPayload — данные кадра ack в ответ на команду чата
+type CommandResultPayload struct {
+	Command string `json:"command"` // Имя команды без префикса
+	Message string `json:"message"` // Ответ для отправителя
+}
+
+// ResumedPayload — данные кадра resumed
+type ResumedPayload struct {
+	LastSeq   int64 `json:"last_seq"`  // Последний номер сообщения в комнате на момент повтора
+	Replayed  int   `json:"replayed"`  // Сколько сообщений повторено
+	Truncated bool  `json:"truncated"` // Повторены не все пропущенные сообщения
+}
+
+// NackPayload — данные кадра nack
+type NackPayload struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Encode упаковывает данные в кадр текущей версии
+func Encode(frameType, id string, payload interface{}) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeRaw(frameType, id, data)
+}
+
+// EncodeRaw упаковывает уже сериализованные данные в кадр текущей версии
+func EncodeRaw(frameType, id string, payload json.RawMessage) ([]byte, error) {
+	return json.Marshal(Envelope{Version: Version, Type: frameType, ID: id, Payload: payload})
+}
+
+// Decode разбирает кадр клиента и проверяет версию протокола
+func Decode(data []byte) (*Envelope, error) {
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, err
+	}
+	if env.Version != Version {
+		return &env, ErrUnsupportedVersion
+	}
+	return &env, nil
+}
+
+// Nack формирует кадр отказа на запрос клиента с идентификатором id
+func Nack(id, code, message string) []byte {
+	// NackPayload всегда сериализуется, ошибка невозможна
+	frame, _ := Encode(TypeNack, id, NackPayload{Code: code, Message: message})
+	return frame
+}
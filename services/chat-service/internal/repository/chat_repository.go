@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
@@ -12,11 +13,41 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrMessageNotFound возвращается, если сообщение с указанным ID не существует
+var ErrMessageNotFound = errors.New("сообщение не найдено")
+
+// ErrBanNotFound возвращается, если у пользователя нет блокировки в чате
+var ErrBanNotFound = errors.New("пользователь не найден в бан-листе")
+
+// ErrRoomNotFound возвращается, если у стрима нет активной комнаты чата
+var ErrRoomNotFound = errors.New("комната не найдена")
+
+// ErrTermNotFound возвращается, если запрещенное выражение не найдено в чате
+var ErrTermNotFound = errors.New("запрещенное выражение не найдено")
+
+// ErrRoleNotFound возвращается, если у пользователя нет выданной роли в чате
+var ErrRoleNotFound = errors.New("у пользователя нет роли в чате")
+
+// ErrPollNotFound возвращается, если опрос с указанным ID не существует
+var ErrPollNotFound = errors.New("опрос не найден")
+
+// ErrBlockNotFound возвращается, если пользователь не заблокирован
+var ErrBlockNotFound = errors.New("пользователь не заблокирован")
+
+// ErrEmoteNotFound возвращается, если эмоут не найден в наборе
+var ErrEmoteNotFound = errors.New("эмоут не найден")
+
+// ErrEmoteCodeTaken возвращается, если код эмоута уже занят в наборе канала или глобальном наборе
+var ErrEmoteCodeTaken = errors.New("код эмоута уже занят")
+
 // ChatRepositoryImpl реализует интерфейс ChatRepository
 type ChatRepositoryImpl struct {
 	mongoCollection *mongo.Collection
+	whispers        *mongo.Collection
+	conversations   *mongo.Collection
 	pgDB            *gorm.DB
 }
 
@@ -24,47 +55,416 @@ type ChatRepositoryImpl struct {
 func NewChatRepository(mongoDB *mongo.Database, pgDB *gorm.DB) *ChatRepositoryImpl {
 	return &ChatRepositoryImpl{
 		mongoCollection: mongoDB.Collection("messages"),
+		whispers:        mongoDB.Collection("whispers"),
+		conversations:   mongoDB.Collection("conversations"),
 		pgDB:            pgDB,
 	}
 }
 
 // SaveMessage сохраняет сообщение в MongoDB
 func (r *ChatRepositoryImpl) SaveMessage(ctx context.Context, msg *entity.ChatMessage) error {
-	_, err := r.mongoCollection.InsertOne(ctx, msg)
+	_, err := r.mongoCollection.InsertOne(ctx, model.NewChatMessageModel(msg))
+	return err
+}
+
+// SaveMessages сохраняет пачку сообщений в MongoDB одним запросом. Уже
+// сохраненные сообщения пропускаются, поэтому пачку можно записывать повторно
+func (r *ChatRepositoryImpl) SaveMessages(ctx context.Context, msgs []*entity.ChatMessage) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, 0, len(msgs))
+	for _, msg := range msgs {
+		docs = append(docs, model.NewChatMessageModel(msg))
+	}
+
+	_, err := r.mongoCollection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if onlyDuplicates(err) {
+		return nil
+	}
+	return err
+}
+
+// onlyDuplicates сообщает, что пакетная вставка не удалась только из-за
+// документов, которые уже есть в коллекции
+func onlyDuplicates(err error) bool {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil || len(bulkErr.WriteErrors) == 0 {
+		return false
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Code != 11000 { // E11000 duplicate key
+			return false
+		}
+	}
+	return true
+}
+
+// GetMessages получает страницу сообщений стрима из MongoDB.
+// Сообщения упорядочены по (sent_at, _id), поэтому курсор однозначен
+// даже для сообщений с одинаковым временем отправки. Удаленные модераторами
+// сообщения не возвращаются
+func (r *ChatRepositoryImpl) GetMessages(ctx context.Context, query entity.MessageQuery) (*entity.MessagePage, error) {
+	filter := bson.M{"stream_id": query.StreamID, "is_deleted": false}
+	var bounds bson.A
+
+	if query.Before != nil {
+		bound, err := r.cursorFilter(ctx, r.mongoCollection, query.Before, "$lt")
+		if err != nil {
+			return nil, err
+		}
+		bounds = append(bounds, bound)
+	}
+	if query.After != nil {
+		bound, err := r.cursorFilter(ctx, r.mongoCollection, query.After, "$gt")
+		if err != nil {
+			return nil, err
+		}
+		bounds = append(bounds, bound)
+	}
+	if len(bounds) > 0 {
+		filter["$and"] = bounds
+	}
+
+	// Без курсора after страница строится от самых новых сообщений назад
+	order := -1
+	if query.After != nil {
+		order = 1
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "sent_at", Value: order}, {Key: "_id", Value: order}}).
+		SetLimit(int64(query.Limit + 1))
+
+	cur, err := r.mongoCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	messages := make([]*entity.ChatMessage, 0, query.Limit)
+	for cur.Next(ctx) {
+		var msg model.ChatMessage
+		if err := cur.Decode(&msg); err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg.ToEntity())
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	page := &entity.MessagePage{Messages: messages}
+	if len(messages) > query.Limit {
+		page.HasMore = true
+		page.Messages = messages[:query.Limit]
+	}
+	if order < 0 {
+		slices.Reverse(page.Messages)
+	}
+	return page, nil
+}
+
+// cursorFilter строит условие выборки относительно курсора. Сообщение курсора
+// ищется в коллекции coll: сообщений чата или личных сообщений
+func (r *ChatRepositoryImpl) cursorFilter(ctx context.Context, coll *mongo.Collection, cursor *entity.MessageCursor, op string) (bson.M, error) {
+	if cursor.MessageID == uuid.Nil {
+		return bson.M{"sent_at": bson.M{op: cursor.Time}}, nil
+	}
+
+	var anchor struct {
+		ID        uuid.UUID `bson:"_id"`
+		Timestamp time.Time `bson:"sent_at"`
+	}
+	err := coll.FindOne(ctx, bson.M{"_id": cursor.MessageID}).Decode(&anchor)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrMessageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return bson.M{"$or": bson.A{
+		bson.M{"sent_at": bson.M{op: anchor.Timestamp}},
+		bson.M{"sent_at": anchor.Timestamp, "_id": bson.M{op: anchor.ID}},
+	}}, nil
+}
+
+// EnsureIndexes создает индексы, необходимые для выборки истории, повтора по номерам и поиска.
+// Текстовый индекс строится без языка: в чате смешаны языки и сленг,
+// поэтому слова ищутся как есть, без стемминга и стоп-слов
+func (r *ChatRepositoryImpl) EnsureIndexes(ctx context.Context) error {
+	_, err := r.mongoCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "stream_id", Value: 1}, {Key: "sent_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "stream_id", Value: 1}, {Key: "seq", Value: -1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "sent_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{
+			Keys:    bson.D{{Key: "content", Value: "text"}},
+			Options: options.Index().SetDefaultLanguage("none"),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.whispers.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "conversation_id", Value: 1}, {Key: "sent_at", Value: -1}, {Key: "_id", Value: -1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.conversations.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "participants", Value: 1}, {Key: "updated_at", Value: -1}},
+	})
 	return err
 }
 
-// GetMessages получает последние сообщения из MongoDB по streamID
-func (r *ChatRepositoryImpl) GetMessages(ctx context.Context, streamID uuid.UUID, limit int) ([]*entity.ChatMessage, error) {
-	var messages []*entity.ChatMessage
-	cur, err := r.mongoCollection.Find(ctx, bson.M{"stream_id": streamID}, options.Find().SetSort(bson.M{"created_at": -1}).SetLimit(int64(limit)))
+// GetMessagesAfterSeq возвращает не более limit последних сообщений стрима
+// с номером больше afterSeq по возрастанию номера. Удаленные сообщения не возвращаются
+func (r *ChatRepositoryImpl) GetMessagesAfterSeq(ctx context.Context, streamID uuid.UUID, afterSeq int64, limit int) ([]*entity.ChatMessage, error) {
+	filter := bson.M{"stream_id": streamID, "is_deleted": false, "seq": bson.M{"$gt": afterSeq}}
+	opts := options.Find().SetSort(bson.D{{Key: "seq", Value: -1}}).SetLimit(int64(limit))
+
+	cur, err := r.mongoCollection.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
 	defer cur.Close(ctx)
 
+	messages := make([]*entity.ChatMessage, 0, limit)
 	for cur.Next(ctx) {
-		var msg entity.ChatMessage
+		var msg model.ChatMessage
 		if err := cur.Decode(&msg); err != nil {
 			return nil, err
 		}
-		messages = append(messages, &msg)
+		messages = append(messages, msg.ToEntity())
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
 	}
+
+	slices.Reverse(messages)
 	return messages, nil
 }
 
-// DeleteMessage удаляет сообщение по ID
-func (r *ChatRepositoryImpl) DeleteMessage(ctx context.Context, messageID uuid.UUID) error {
-	res, err := r.mongoCollection.DeleteOne(ctx, bson.M{"id": messageID})
+// GetLastSeq возвращает наибольший сохраненный номер сообщения стрима или 0
+func (r *ChatRepositoryImpl) GetLastSeq(ctx context.Context, streamID uuid.UUID) (int64, error) {
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: "seq", Value: -1}}).
+		SetProjection(bson.M{"seq": 1})
+
+	var doc struct {
+		Seq int64 `bson:"seq"`
+	}
+	err := r.mongoCollection.FindOne(ctx, bson.M{"stream_id": streamID}, opts).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return doc.Seq, nil
+}
+
+// SearchMessages ищет сообщения по тексту и фильтрам, от новых к старым
+func (r *ChatRepositoryImpl) SearchMessages(ctx context.Context, search entity.MessageSearch) (*entity.MessageSearchPage, error) {
+	filter := bson.M{}
+	if search.Text != "" {
+		filter["$text"] = bson.M{"$search": search.Text}
+	}
+	if search.StreamID != uuid.Nil {
+		filter["stream_id"] = search.StreamID
+	}
+	if search.UserID != uuid.Nil {
+		filter["user_id"] = search.UserID
+	}
+	if search.Deleted != nil {
+		filter["is_deleted"] = *search.Deleted
+	}
+
+	sentAt := bson.M{}
+	if !search.Since.IsZero() {
+		sentAt["$gte"] = search.Since
+	}
+	if !search.Until.IsZero() {
+		sentAt["$lt"] = search.Until
+	}
+	if len(sentAt) > 0 {
+		filter["sent_at"] = sentAt
+	}
+
+	if search.Before != nil {
+		bound, err := r.cursorFilter(ctx, r.mongoCollection, search.Before, "$lt")
+		if err != nil {
+			return nil, err
+		}
+		filter["$and"] = bson.A{bound}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "sent_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(search.Limit + 1))
+
+	cur, err := r.mongoCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	messages := make([]*entity.ModeratedMessage, 0, search.Limit)
+	for cur.Next(ctx) {
+		var msg model.ChatMessage
+		if err := cur.Decode(&msg); err != nil {
+			return nil, err
+		}
+		messages = append(messages, entity.Moderated(msg.ToEntity()))
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	page := &entity.MessageSearchPage{Messages: messages}
+	if len(messages) > search.Limit {
+		page.Messages = messages[:search.Limit]
+		page.HasMore = true
+		page.NextCursor = page.Messages[search.Limit-1].ID.String()
+	}
+	return page, nil
+}
+
+// exportBatchSize — сколько сообщений курсор выгрузки читает из MongoDB за раз
+const exportBatchSize = 500
+
+// ExportMessages передает fn все сообщения стрима в хронологическом порядке.
+// Сообщения читаются курсором пачками, поэтому лог не загружается в память целиком.
+// Ошибка fn прерывает выгрузку
+func (r *ChatRepositoryImpl) ExportMessages(ctx context.Context, streamID uuid.UUID, includeDeleted bool, fn func(*entity.ChatMessage) error) error {
+	filter := bson.M{"stream_id": streamID}
+	if !includeDeleted {
+		filter["is_deleted"] = false
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "sent_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetBatchSize(exportBatchSize)
+
+	cur, err := r.mongoCollection.Find(ctx, filter, opts)
 	if err != nil {
 		return err
 	}
-	if res.DeletedCount == 0 {
-		return errors.New("сообщение не найдено")
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var msg model.ChatMessage
+		if err := cur.Decode(&msg); err != nil {
+			return err
+		}
+		if err := fn(msg.ToEntity()); err != nil {
+			return err
+		}
+	}
+	return cur.Err()
+}
+
+// GetMessage возвращает сообщение по ID, включая удаленные
+func (r *ChatRepositoryImpl) GetMessage(ctx context.Context, messageID uuid.UUID) (*entity.ChatMessage, error) {
+	var msg model.ChatMessage
+	err := r.mongoCollection.FindOne(ctx, bson.M{"_id": messageID}).Decode(&msg)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrMessageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return msg.ToEntity(), nil
+}
+
+// DeleteMessage помечает сообщение удаленным модератором и возвращает его
+func (r *ChatRepositoryImpl) DeleteMessage(ctx context.Context, messageID, moderatorID uuid.UUID, reason string) (*entity.ChatMessage, error) {
+	update := bson.M{"$set": bson.M{
+		"is_deleted": true,
+		"mod_reason": reason,
+		"deleted_by": moderatorID,
+		"deleted_at": time.Now().UTC(),
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var msg model.ChatMessage
+	err := r.mongoCollection.FindOneAndUpdate(ctx, bson.M{"_id": messageID}, update, opts).Decode(&msg)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrMessageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return msg.ToEntity(), nil
+}
+
+// SetMessageReactions сохраняет число реакций на сообщение, заменяя прежние счетчики
+func (r *ChatRepositoryImpl) SetMessageReactions(ctx context.Context, messageID uuid.UUID, counts map[string]int) error {
+	update := bson.M{"$set": bson.M{"reactions": counts}}
+	if len(counts) == 0 {
+		update = bson.M{"$unset": bson.M{"reactions": ""}}
+	}
+
+	res, err := r.mongoCollection.UpdateOne(ctx, bson.M{"_id": messageID}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrMessageNotFound
 	}
 	return nil
 }
 
+// DeleteUserMessages помечает удаленными сообщения пользователя в чате стрима,
+// отправленные начиная с since, и возвращает их ID. При userID = uuid.Nil
+// удаляются сообщения всех пользователей
+func (r *ChatRepositoryImpl) DeleteUserMessages(ctx context.Context, streamID, userID, moderatorID uuid.UUID, since time.Time, reason string) ([]uuid.UUID, error) {
+	filter := bson.M{
+		"stream_id":  streamID,
+		"is_deleted": false,
+		"sent_at":    bson.M{"$gte": since},
+	}
+	if userID != uuid.Nil {
+		filter["user_id"] = userID
+	}
+
+	cur, err := r.mongoCollection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var ids []uuid.UUID
+	for cur.Next(ctx) {
+		var doc struct {
+			ID uuid.UUID `bson:"_id"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			return nil, err
+		}
+		ids = append(ids, doc.ID)
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	update := bson.M{"$set": bson.M{
+		"is_deleted": true,
+		"mod_reason": reason,
+		"deleted_by": moderatorID,
+		"deleted_at": time.Now().UTC(),
+	}}
+	if _, err := r.mongoCollection.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}}, update); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
 // CreateRoom создает комнату в PostgreSQL
 func (r *ChatRepositoryImpl) CreateRoom(ctx context.Context, streamID uuid.UUID, title string) (*entity.ChatRoom, error) {
 	room := &model.ChatRoom{
@@ -81,12 +481,69 @@ func (r *ChatRepositoryImpl) CreateRoom(ctx context.Context, streamID uuid.UUID,
 }
 
 // GetRoom получает комнату по streamID
-func (r *ChatRepositoryImpl) GetRoom(ctx context.Context, streamID uuid.UUID) (*model.ChatRoom, error) {
+func (r *ChatRepositoryImpl) GetRoom(ctx context.Context, streamID uuid.UUID) (*entity.ChatRoom, error) {
 	var room model.ChatRoom
-	if err := r.pgDB.WithContext(ctx).Where("stream_id = ? AND is_active = ?", streamID, true).First(&room).Error; err != nil {
+	err := r.pgDB.WithContext(ctx).Where("stream_id = ? AND is_active = ?", streamID, true).First(&room).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRoomNotFound
+	}
+	if err != nil {
 		return nil, err
 	}
-	return &room, nil
+	return room.ToEntity(), nil
+}
+
+// SetRoomModes сохраняет режимы чата активной комнаты стрима, создавая ее при необходимости
+func (r *ChatRepositoryImpl) SetRoomModes(ctx context.Context, streamID uuid.UUID, modes entity.ChatModes) error {
+	columns := map[string]interface{}{
+		"slow_mode_seconds":     modes.SlowModeSeconds,
+		"followers_only":        modes.FollowersOnly,
+		"followers_min_minutes": modes.FollowersMinMinutes,
+		"emote_only":            modes.EmoteOnly,
+		"subscribers_only":      modes.SubscribersOnly,
+	}
+
+	return r.pgDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.ChatRoom{}).Where("stream_id = ? AND is_active = ?", streamID, true).Updates(columns)
+		if res.Error != nil || res.RowsAffected > 0 {
+			return res.Error
+		}
+
+		return tx.Create(&model.ChatRoom{
+			ID:                  uuid.New(),
+			StreamID:            streamID,
+			CreatedAt:           time.Now(),
+			IsActive:            true,
+			SlowModeSeconds:     modes.SlowModeSeconds,
+			FollowersOnly:       modes.FollowersOnly,
+			FollowersMinMinutes: modes.FollowersMinMinutes,
+			EmoteOnly:           modes.EmoteOnly,
+			SubscribersOnly:     modes.SubscribersOnly,
+		}).Error
+	})
+}
+
+// SetRoomPin сохраняет закрепленное сообщение активной комнаты стрима, создавая
+// ее при необходимости. pin = nil снимает закрепление
+func (r *ChatRepositoryImpl) SetRoomPin(ctx context.Context, streamID uuid.UUID, pin *entity.PinnedMessage) error {
+	// Колонка обновляется через структуру, чтобы применился JSON-сериализатор
+	update := &model.ChatRoom{PinnedMessage: model.NewChatRoomPin(pin)}
+
+	return r.pgDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.ChatRoom{}).Where("stream_id = ? AND is_active = ?", streamID, true).
+			Select("pinned_message").Updates(update)
+		if res.Error != nil || res.RowsAffected > 0 || pin == nil {
+			return res.Error
+		}
+
+		return tx.Create(&model.ChatRoom{
+			ID:            uuid.New(),
+			StreamID:      streamID,
+			CreatedAt:     time.Now(),
+			IsActive:      true,
+			PinnedMessage: update.PinnedMessage,
+		}).Error
+	})
 }
 
 // CloseRoom закрывает комнату (делает неактивной)
@@ -96,20 +553,19 @@ func (r *ChatRepositoryImpl) CloseRoom(ctx context.Context, streamID uuid.UUID)
 		return res.Error
 	}
 	if res.RowsAffected == 0 {
-		return errors.New("комната не найдена")
+		return ErrRoomNotFound
 	}
 	return nil
 }
 
-// BanUser блокирует пользователя в PostgreSQL
-func (r *ChatRepositoryImpl) BanUser(ctx context.Context, streamID, userID uuid.UUID) error {
-	ban := &model.ChatBan{
-		ID:       uuid.New(),
-		StreamID: streamID,
-		UserID:   userID,
-		BannedAt: time.Now(),
-	}
-	return r.pgDB.WithContext(ctx).Create(ban).Error
+// BanUser сохраняет бан или таймаут в PostgreSQL, заменяя предыдущую блокировку
+func (r *ChatRepositoryImpl) BanUser(ctx context.Context, ban *entity.ChatBan) error {
+	return r.pgDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("stream_id = ? AND user_id = ?", ban.StreamID, ban.UserID).Delete(&model.ChatBan{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(model.NewChatBanModel(ban)).Error
+	})
 }
 
 // UnbanUser удаляет блокировку пользователя
@@ -119,7 +575,393 @@ func (r *ChatRepositoryImpl) UnbanUser(ctx context.Context, streamID, userID uui
 		return res.Error
 	}
 	if res.RowsAffected == 0 {
-		return errors.New("пользователь не найден в бан-листе")
+		return ErrBanNotFound
+	}
+	return nil
+}
+
+// GetActiveBan возвращает действующую блокировку пользователя или nil
+func (r *ChatRepositoryImpl) GetActiveBan(ctx context.Context, streamID, userID uuid.UUID) (*entity.ChatBan, error) {
+	var ban model.ChatBan
+	err := r.pgDB.WithContext(ctx).
+		Where("stream_id = ? AND user_id = ? AND (expires_at IS NULL OR expires_at > ?)", streamID, userID, time.Now()).
+		First(&ban).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ban.ToEntity(), nil
+}
+
+// ListActiveBans возвращает действующие блокировки в чате стрима.
+// Для uuid.Nil возвращаются блокировки во всех чатах
+func (r *ChatRepositoryImpl) ListActiveBans(ctx context.Context, streamID uuid.UUID) ([]*entity.ChatBan, error) {
+	query := r.pgDB.WithContext(ctx).Where("expires_at IS NULL OR expires_at > ?", time.Now())
+	if streamID != uuid.Nil {
+		query = query.Where("stream_id = ?", streamID)
+	}
+
+	var bans []model.ChatBan
+	if err := query.Order("banned_at DESC").Find(&bans).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*entity.ChatBan, 0, len(bans))
+	for i := range bans {
+		result = append(result, bans[i].ToEntity())
+	}
+	return result, nil
+}
+
+// SetRole выдает пользователю роль в чате стрима, заменяя предыдущую
+func (r *ChatRepositoryImpl) SetRole(ctx context.Context, role *entity.ChatRole) error {
+	return r.pgDB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "stream_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "granted_by", "granted_at"}),
+	}).Create(model.NewChatRoleModel(role)).Error
+}
+
+// RemoveRole снимает с пользователя выданную роль
+func (r *ChatRepositoryImpl) RemoveRole(ctx context.Context, streamID, userID uuid.UUID) error {
+	res := r.pgDB.WithContext(ctx).Where("stream_id = ? AND user_id = ?", streamID, userID).Delete(&model.ChatRole{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrRoleNotFound
+	}
+	return nil
+}
+
+// GetRole возвращает выданную пользователю роль или nil
+func (r *ChatRepositoryImpl) GetRole(ctx context.Context, streamID, userID uuid.UUID) (*entity.ChatRole, error) {
+	var role model.ChatRole
+	err := r.pgDB.WithContext(ctx).Where("stream_id = ? AND user_id = ?", streamID, userID).First(&role).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return role.ToEntity(), nil
+}
+
+// ListRoles возвращает модераторов и VIP чата стрима
+func (r *ChatRepositoryImpl) ListRoles(ctx context.Context, streamID uuid.UUID) ([]*entity.ChatRole, error) {
+	var roles []model.ChatRole
+	if err := r.pgDB.WithContext(ctx).Where("stream_id = ?", streamID).Order("granted_at").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*entity.ChatRole, 0, len(roles))
+	for i := range roles {
+		result = append(result, roles[i].ToEntity())
+	}
+	return result, nil
+}
+
+// GetAutoModSettings возвращает настройки AutoMod чата стрима или nil, если они не заданы
+func (r *ChatRepositoryImpl) GetAutoModSettings(ctx context.Context, streamID uuid.UUID) (*entity.AutoModSettings, error) {
+	var settings model.ChatAutoModSettings
+	err := r.pgDB.WithContext(ctx).Where("stream_id = ?", streamID).First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return settings.ToEntity(), nil
+}
+
+// SaveAutoModSettings сохраняет настройки AutoMod чата стрима
+func (r *ChatRepositoryImpl) SaveAutoModSettings(ctx context.Context, settings *entity.AutoModSettings) error {
+	return r.pgDB.WithContext(ctx).Save(model.NewChatAutoModSettingsModel(settings)).Error
+}
+
+// ListBlockedTerms возвращает запрещенные выражения чата стрима
+func (r *ChatRepositoryImpl) ListBlockedTerms(ctx context.Context, streamID uuid.UUID) ([]*entity.BlockedTerm, error) {
+	var terms []model.ChatBlockedTerm
+	if err := r.pgDB.WithContext(ctx).Where("stream_id = ?", streamID).Order("created_at").Find(&terms).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*entity.BlockedTerm, 0, len(terms))
+	for i := range terms {
+		result = append(result, terms[i].ToEntity())
+	}
+	return result, nil
+}
+
+// AddBlockedTerm добавляет запрещенное выражение. Повторное добавление шаблона меняет его действие
+func (r *ChatRepositoryImpl) AddBlockedTerm(ctx context.Context, term *entity.BlockedTerm) error {
+	return r.pgDB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "stream_id"}, {Name: "pattern"}},
+		DoUpdates: clause.AssignmentColumns([]string{"action", "created_by", "created_at"}),
+	}).Create(model.NewChatBlockedTermModel(term)).Error
+}
+
+// RemoveBlockedTerm удаляет запрещенное выражение из чата стрима
+func (r *ChatRepositoryImpl) RemoveBlockedTerm(ctx context.Context, streamID, termID uuid.UUID) error {
+	res := r.pgDB.WithContext(ctx).Where("stream_id = ? AND id = ?", streamID, termID).Delete(&model.ChatBlockedTerm{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrTermNotFound
 	}
 	return nil
 }
+
+// CreatePoll сохраняет новый опрос
+func (r *ChatRepositoryImpl) CreatePoll(ctx context.Context, poll *entity.Poll) error {
+	return r.pgDB.WithContext(ctx).Create(model.NewChatPollModel(poll)).Error
+}
+
+// GetPoll возвращает опрос по ID
+func (r *ChatRepositoryImpl) GetPoll(ctx context.Context, pollID uuid.UUID) (*entity.Poll, error) {
+	var poll model.ChatPoll
+	err := r.pgDB.WithContext(ctx).Where("id = ?", pollID).First(&poll).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPollNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return poll.ToEntity(), nil
+}
+
+// ListPolls возвращает опросы чата стрима, начиная с последнего
+func (r *ChatRepositoryImpl) ListPolls(ctx context.Context, streamID uuid.UUID) ([]*entity.Poll, error) {
+	var polls []model.ChatPoll
+	if err := r.pgDB.WithContext(ctx).Where("stream_id = ?", streamID).Order("created_at DESC").Find(&polls).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*entity.Poll, 0, len(polls))
+	for i := range polls {
+		result = append(result, polls[i].ToEntity())
+	}
+	return result, nil
+}
+
+// ListActivePolls возвращает опросы всех чатов, итоги которых еще не подведены
+func (r *ChatRepositoryImpl) ListActivePolls(ctx context.Context) ([]*entity.Poll, error) {
+	var polls []model.ChatPoll
+	if err := r.pgDB.WithContext(ctx).Where("status = ?", string(entity.PollActive)).Find(&polls).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*entity.Poll, 0, len(polls))
+	for i := range polls {
+		result = append(result, polls[i].ToEntity())
+	}
+	return result, nil
+}
+
+// FinishPoll записывает итоги опроса. Возвращает false, если итоги
+// уже записаны, например другим экземпляром сервиса
+func (r *ChatRepositoryImpl) FinishPoll(ctx context.Context, poll *entity.Poll) (bool, error) {
+	// Обновление структурой, чтобы варианты ответа прошли через JSON-сериализатор
+	res := r.pgDB.WithContext(ctx).Model(&model.ChatPoll{}).
+		Where("id = ? AND status = ?", poll.ID, string(entity.PollActive)).
+		Select("options", "total_votes", "status", "ended_at").
+		Updates(model.NewChatPollModel(poll))
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// SaveWhisper сохраняет личное сообщение и поднимает переписку в списке входящих
+func (r *ChatRepositoryImpl) SaveWhisper(ctx context.Context, whisper *entity.Whisper) error {
+	doc := model.NewWhisperModel(whisper)
+	if _, err := r.whispers.InsertOne(ctx, doc); err != nil {
+		return err
+	}
+
+	_, err := r.conversations.UpdateOne(ctx,
+		bson.M{"_id": whisper.ConversationID},
+		bson.M{
+			"$set":         bson.M{"last_whisper": doc, "updated_at": whisper.Timestamp},
+			"$setOnInsert": bson.M{"participants": []uuid.UUID{whisper.FromUserID, whisper.ToUserID}},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// ListConversations возвращает переписки пользователя, начиная с самых свежих.
+// Если задан before, возвращаются переписки, обновленные раньше него
+func (r *ChatRepositoryImpl) ListConversations(ctx context.Context, userID uuid.UUID, before time.Time, limit int) (*entity.ConversationPage, error) {
+	filter := bson.M{"participants": userID}
+	if !before.IsZero() {
+		filter["updated_at"] = bson.M{"$lt": before}
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "updated_at", Value: -1}}).
+		SetLimit(int64(limit + 1))
+
+	cur, err := r.conversations.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	conversations := make([]*entity.Conversation, 0, limit)
+	for cur.Next(ctx) {
+		var conversation model.Conversation
+		if err := cur.Decode(&conversation); err != nil {
+			return nil, err
+		}
+		conversations = append(conversations, conversation.ToEntity(userID))
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	page := &entity.ConversationPage{Conversations: conversations}
+	if len(conversations) > limit {
+		page.Conversations = conversations[:limit]
+		page.HasMore = true
+	}
+	return page, nil
+}
+
+// GetWhispers возвращает страницу переписки в хронологическом порядке:
+// последние сообщения или сообщения раньше курсора before
+func (r *ChatRepositoryImpl) GetWhispers(ctx context.Context, conversationID string, before *entity.MessageCursor, limit int) (*entity.WhisperPage, error) {
+	filter := bson.M{"conversation_id": conversationID}
+	if before != nil {
+		bound, err := r.cursorFilter(ctx, r.whispers, before, "$lt")
+		if err != nil {
+			return nil, err
+		}
+		filter["$and"] = bson.A{bound}
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "sent_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit + 1))
+
+	cur, err := r.whispers.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	whispers := make([]*entity.Whisper, 0, limit)
+	for cur.Next(ctx) {
+		var whisper model.Whisper
+		if err := cur.Decode(&whisper); err != nil {
+			return nil, err
+		}
+		whispers = append(whispers, whisper.ToEntity())
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	page := &entity.WhisperPage{Whispers: whispers}
+	if len(whispers) > limit {
+		page.Whispers = whispers[:limit]
+		page.HasMore = true
+	}
+	slices.Reverse(page.Whispers)
+	return page, nil
+}
+
+// BlockUser блокирует личные сообщения между пользователями. Повторная блокировка не ошибка
+func (r *ChatRepositoryImpl) BlockUser(ctx context.Context, block *entity.UserBlock) error {
+	return r.pgDB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&model.ChatUserBlock{
+		BlockerID: block.BlockerID,
+		BlockedID: block.BlockedID,
+		CreatedAt: block.CreatedAt,
+	}).Error
+}
+
+// UnblockUser снимает блокировку личных сообщений
+func (r *ChatRepositoryImpl) UnblockUser(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	res := r.pgDB.WithContext(ctx).Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Delete(&model.ChatUserBlock{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrBlockNotFound
+	}
+	return nil
+}
+
+// ListBlocks возвращает пользователей, заблокированных пользователем blockerID
+func (r *ChatRepositoryImpl) ListBlocks(ctx context.Context, blockerID uuid.UUID) ([]*entity.UserBlock, error) {
+	var blocks []model.ChatUserBlock
+	if err := r.pgDB.WithContext(ctx).Where("blocker_id = ?", blockerID).Order("created_at").Find(&blocks).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*entity.UserBlock, 0, len(blocks))
+	for i := range blocks {
+		result = append(result, blocks[i].ToEntity())
+	}
+	return result, nil
+}
+
+// HasBlock сообщает, заблокировал ли один из пользователей другого
+func (r *ChatRepositoryImpl) HasBlock(ctx context.Context, a, b uuid.UUID) (bool, error) {
+	var count int64
+	err := r.pgDB.WithContext(ctx).Model(&model.ChatUserBlock{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", a, b, b, a).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListEmotes возвращает эмоуты канала или, при нулевом streamID, глобальный набор
+func (r *ChatRepositoryImpl) ListEmotes(ctx context.Context, streamID uuid.UUID) ([]*entity.Emote, error) {
+	var emotes []model.ChatEmote
+	if err := r.pgDB.WithContext(ctx).Where("stream_id = ?", streamID).Order("created_at").Find(&emotes).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*entity.Emote, 0, len(emotes))
+	for i := range emotes {
+		result = append(result, emotes[i].ToEntity())
+	}
+	return result, nil
+}
+
+// AddEmote сохраняет эмоут в наборе канала или в глобальном наборе.
+// Уникальность кода проверяется триггером chat_emotes_check_code
+func (r *ChatRepositoryImpl) AddEmote(ctx context.Context, emote *entity.Emote) error {
+	err := r.pgDB.WithContext(ctx).Create(model.NewChatEmoteModel(emote)).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrEmoteCodeTaken
+	}
+	return err
+}
+
+// EmoteCodeTaken сообщает, занят ли код эмоута. Код канала не должен совпадать
+// с кодами канала и глобального набора, глобальный код — ни с одним кодом
+func (r *ChatRepositoryImpl) EmoteCodeTaken(ctx context.Context, streamID uuid.UUID, code string) (bool, error) {
+	query := r.pgDB.WithContext(ctx).Model(&model.ChatEmote{}).Where("code = ?", code)
+	if streamID != uuid.Nil {
+		query = query.Where("stream_id IN ?", []uuid.UUID{uuid.Nil, streamID})
+	}
+	var count int64
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// RemoveEmote удаляет эмоут из набора и возвращает его, чтобы можно было удалить изображения
+func (r *ChatRepositoryImpl) RemoveEmote(ctx context.Context, streamID, emoteID uuid.UUID) (*entity.Emote, error) {
+	var emote model.ChatEmote
+	res := r.pgDB.WithContext(ctx).Clauses(clause.Returning{}).
+		Where("stream_id = ? AND id = ?", streamID, emoteID).
+		Delete(&emote)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrEmoteNotFound
+	}
+	return emote.ToEntity(), nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
 
@@ -12,15 +13,62 @@ import (
 type ChatRepository interface {
 	// Сообщения
 	SaveMessage(ctx context.Context, msg *entity.ChatMessage) error
-	GetMessages(ctx context.Context, streamID uuid.UUID, limit int) ([]*entity.ChatMessage, error)
-	DeleteMessage(ctx context.Context, messageID uuid.UUID) error
+	SaveMessages(ctx context.Context, msgs []*entity.ChatMessage) error
+	GetMessages(ctx context.Context, query entity.MessageQuery) (*entity.MessagePage, error)
+	GetMessage(ctx context.Context, messageID uuid.UUID) (*entity.ChatMessage, error)
+	GetMessagesAfterSeq(ctx context.Context, streamID uuid.UUID, afterSeq int64, limit int) ([]*entity.ChatMessage, error)
+	GetLastSeq(ctx context.Context, streamID uuid.UUID) (int64, error)
+	SearchMessages(ctx context.Context, search entity.MessageSearch) (*entity.MessageSearchPage, error)
+	ExportMessages(ctx context.Context, streamID uuid.UUID, includeDeleted bool, fn func(*entity.ChatMessage) error) error
+	DeleteMessage(ctx context.Context, messageID, moderatorID uuid.UUID, reason string) (*entity.ChatMessage, error)
+	DeleteUserMessages(ctx context.Context, streamID, userID, moderatorID uuid.UUID, since time.Time, reason string) ([]uuid.UUID, error)
+	SetMessageReactions(ctx context.Context, messageID uuid.UUID, counts map[string]int) error
 
 	// Комнаты
 	CreateRoom(ctx context.Context, streamID uuid.UUID, title string) (*entity.ChatRoom, error)
 	GetRoom(ctx context.Context, streamID uuid.UUID) (*entity.ChatRoom, error)
 	CloseRoom(ctx context.Context, streamID uuid.UUID) error
+	SetRoomModes(ctx context.Context, streamID uuid.UUID, modes entity.ChatModes) error
+	SetRoomPin(ctx context.Context, streamID uuid.UUID, pin *entity.PinnedMessage) error
 
 	// Модерация
-	BanUser(ctx context.Context, streamID, userID uuid.UUID) error
+	BanUser(ctx context.Context, ban *entity.ChatBan) error
 	UnbanUser(ctx context.Context, streamID, userID uuid.UUID) error
+	GetActiveBan(ctx context.Context, streamID, userID uuid.UUID) (*entity.ChatBan, error)
+	ListActiveBans(ctx context.Context, streamID uuid.UUID) ([]*entity.ChatBan, error)
+
+	// Роли
+	SetRole(ctx context.Context, role *entity.ChatRole) error
+	RemoveRole(ctx context.Context, streamID, userID uuid.UUID) error
+	GetRole(ctx context.Context, streamID, userID uuid.UUID) (*entity.ChatRole, error)
+	ListRoles(ctx context.Context, streamID uuid.UUID) ([]*entity.ChatRole, error)
+
+	// AutoMod
+	GetAutoModSettings(ctx context.Context, streamID uuid.UUID) (*entity.AutoModSettings, error)
+	SaveAutoModSettings(ctx context.Context, settings *entity.AutoModSettings) error
+	ListBlockedTerms(ctx context.Context, streamID uuid.UUID) ([]*entity.BlockedTerm, error)
+	AddBlockedTerm(ctx context.Context, term *entity.BlockedTerm) error
+	RemoveBlockedTerm(ctx context.Context, streamID, termID uuid.UUID) error
+
+	// Опросы
+	CreatePoll(ctx context.Context, poll *entity.Poll) error
+	GetPoll(ctx context.Context, pollID uuid.UUID) (*entity.Poll, error)
+	ListPolls(ctx context.Context, streamID uuid.UUID) ([]*entity.Poll, error)
+	ListActivePolls(ctx context.Context) ([]*entity.Poll, error)
+	FinishPoll(ctx context.Context, poll *entity.Poll) (bool, error)
+
+	// Личные сообщения
+	SaveWhisper(ctx context.Context, whisper *entity.Whisper) error
+	ListConversations(ctx context.Context, userID uuid.UUID, before time.Time, limit int) (*entity.ConversationPage, error)
+	GetWhispers(ctx context.Context, conversationID string, before *entity.MessageCursor, limit int) (*entity.WhisperPage, error)
+	BlockUser(ctx context.Context, block *entity.UserBlock) error
+	UnblockUser(ctx context.Context, blockerID, blockedID uuid.UUID) error
+	ListBlocks(ctx context.Context, blockerID uuid.UUID) ([]*entity.UserBlock, error)
+	HasBlock(ctx context.Context, a, b uuid.UUID) (bool, error)
+
+	// Эмоуты
+	ListEmotes(ctx context.Context, streamID uuid.UUID) ([]*entity.Emote, error)
+	AddEmote(ctx context.Context, emote *entity.Emote) error
+	EmoteCodeTaken(ctx context.Context, streamID uuid.UUID, code string) (bool, error)
+	RemoveEmote(ctx context.Context, streamID, emoteID uuid.UUID) (*entity.Emote, error)
 }
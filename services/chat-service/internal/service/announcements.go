@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/google/uuid"
+)
+
+// MaxAnnouncementLength — максимальная длина объявления в символах
+const MaxAnnouncementLength = 500
+
+var (
+	ErrInvalidAnnouncement = errors.New("announcement must be between 1 and 500 characters")
+	ErrInvalidColor        = errors.New("unknown announcement color")
+)
+
+// Announce отправляет в чат стрима объявление. Объявления не ограничены
+// режимами чата, в том числе slow mode, сохраняются в истории вместе
+// с сообщениями и приходят клиентам отдельным типом кадра.
+// Доступно модераторам и бродкастеру
+func (s *ChatService) Announce(ctx context.Context, streamID, actorID uuid.UUID, actorName, content, color string) (*entity.ChatMessage, error) {
+	content = strings.TrimSpace(content)
+	if content == "" || utf8.RuneCountInString(content) > MaxAnnouncementLength {
+		return nil, ErrInvalidAnnouncement
+	}
+	if color == "" {
+		color = entity.AnnouncementPrimary
+	}
+	if !entity.ValidAnnouncementColor(color) {
+		return nil, ErrInvalidColor
+	}
+
+	role, err := s.GetRole(ctx, streamID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !role.CanModerate() && !s.IsAdmin(actorID) {
+		return nil, ErrForbidden
+	}
+
+	msg := entity.NewAnnouncement(streamID, actorID, actorName, content, color)
+	msg.Badges = role.Badges()
+	if err := s.QueueMessage(msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
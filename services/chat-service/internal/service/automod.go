@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/automod"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/google/uuid"
+)
+
+const MaxBlockedTermLength = 100 // Максимальная длина шаблона запрещенного выражения
+
+var (
+	ErrMessageRejected        = errors.New("message rejected by automod")
+	ErrMessageHeld            = errors.New("message held for moderator review")
+	ErrHeldMessageNotFound    = errors.New("held message not found")
+	ErrInvalidBlockedTerm     = errors.New("invalid blocked term")
+	ErrInvalidAutoModSettings = errors.New("invalid automod settings")
+)
+
+// ModerateMessage пропускает сообщение через цепочку AutoMod. Действие mask
+// меняет текст сообщения, hold задерживает его до решения модератора.
+// Сообщения модераторов и бродкастера не проверяются
+func (s *ChatService) ModerateMessage(ctx context.Context, msg *entity.ChatMessage, role entity.Role) error {
+	if role.CanModerate() {
+		return nil
+	}
+
+	rules, err := s.autoModRules(ctx, msg.StreamID)
+	if err != nil {
+		return err
+	}
+
+	verdict, err := s.automod.Run(ctx, &automod.Input{Message: msg, Rules: rules})
+	if err != nil {
+		return err
+	}
+
+	switch verdict.Action {
+	case entity.AutoModReject:
+		return fmt.Errorf("%w: %s", ErrMessageRejected, verdict.Reason)
+	case entity.AutoModHold:
+		held := &entity.HeldMessage{Message: msg, Filter: verdict.Filter, Reason: verdict.Reason, HeldAt: time.Now().UTC()}
+		if err := s.cache.HoldMessage(ctx, held); err != nil {
+			return err
+		}
+		return ErrMessageHeld
+	}
+	return nil
+}
+
+// GetAutoModRules возвращает настройки и запрещенные выражения чата стрима
+func (s *ChatService) GetAutoModRules(ctx context.Context, streamID, actorID uuid.UUID) (*entity.AutoModRules, error) {
+	if err := s.requireModerator(ctx, streamID, actorID, uuid.Nil); err != nil {
+		return nil, err
+	}
+	return s.autoModRules(ctx, streamID)
+}
+
+// UpdateAutoModSettings меняет настройки эвристических фильтров чата стрима
+func (s *ChatService) UpdateAutoModSettings(ctx context.Context, actorID uuid.UUID, settings *entity.AutoModSettings) error {
+	if settings.LinkAction == "" {
+		settings.LinkAction = entity.AutoModReject
+	}
+	if !settings.Validate() {
+		return ErrInvalidAutoModSettings
+	}
+	if err := s.requireModerator(ctx, settings.StreamID, actorID, uuid.Nil); err != nil {
+		return err
+	}
+
+	if err := s.repo.SaveAutoModSettings(ctx, settings); err != nil {
+		return err
+	}
+	s.invalidateAutoModRules(ctx, settings.StreamID)
+	return nil
+}
+
+// AddBlockedTerm добавляет запрещенное выражение в чат стрима
+func (s *ChatService) AddBlockedTerm(ctx context.Context, streamID, actorID uuid.UUID, pattern string, action entity.AutoModAction) (*entity.BlockedTerm, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" || strings.Trim(pattern, "*") == "" || len([]rune(pattern)) > MaxBlockedTermLength || !action.IsValid() {
+		return nil, ErrInvalidBlockedTerm
+	}
+	if err := s.requireModerator(ctx, streamID, actorID, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	term := &entity.BlockedTerm{
+		ID:        uuid.New(),
+		StreamID:  streamID,
+		Pattern:   pattern,
+		Action:    action,
+		CreatedBy: actorID,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.repo.AddBlockedTerm(ctx, term); err != nil {
+		return nil, err
+	}
+	s.invalidateAutoModRules(ctx, streamID)
+	return term, nil
+}
+
+// RemoveBlockedTerm удаляет запрещенное выражение из чата стрима
+func (s *ChatService) RemoveBlockedTerm(ctx context.Context, streamID, termID, actorID uuid.UUID) error {
+	if err := s.requireModerator(ctx, streamID, actorID, uuid.Nil); err != nil {
+		return err
+	}
+	if err := s.repo.RemoveBlockedTerm(ctx, streamID, termID); err != nil {
+		return err
+	}
+	s.invalidateAutoModRules(ctx, streamID)
+	return nil
+}
+
+// ListHeldMessages возвращает сообщения, задержанные AutoMod
+func (s *ChatService) ListHeldMessages(ctx context.Context, streamID, actorID uuid.UUID) ([]*entity.HeldMessage, error) {
+	if err := s.requireModerator(ctx, streamID, actorID, uuid.Nil); err != nil {
+		return nil, err
+	}
+	return s.cache.ListHeldMessages(ctx, streamID)
+}
+
+// ReviewHeldMessage одобряет задержанное сообщение и отправляет его в чат
+// или отклоняет его
+func (s *ChatService) ReviewHeldMessage(ctx context.Context, streamID, messageID, actorID uuid.UUID, approve bool) (*entity.ChatMessage, error) {
+	if err := s.requireModerator(ctx, streamID, actorID, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	held, err := s.cache.TakeHeldMessage(ctx, streamID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if held == nil {
+		return nil, ErrHeldMessageNotFound
+	}
+	if !approve {
+		return held.Message, nil
+	}
+
+	msg := held.Message
+	if err := s.QueueMessage(msg); err != nil {
+		return nil, err
+	}
+	s.NotifyMentions(ctx, msg)
+	return msg, nil
+}
+
+// autoModRules загружает правила AutoMod из кеша или PostgreSQL
+func (s *ChatService) autoModRules(ctx context.Context, streamID uuid.UUID) (*entity.AutoModRules, error) {
+	rules, err := s.cache.GetAutoModRules(ctx, streamID)
+	if err != nil {
+		log.Warn("AutoMod cache unavailable, falling back to PostgreSQL", "error", err)
+	}
+	if rules != nil {
+		return rules, nil
+	}
+
+	settings, err := s.repo.GetAutoModSettings(ctx, streamID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = entity.DefaultAutoModSettings(streamID)
+	}
+	terms, err := s.repo.ListBlockedTerms(ctx, streamID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules = &entity.AutoModRules{Settings: settings, Terms: terms}
+	if err := s.cache.SetAutoModRules(ctx, streamID, rules); err != nil {
+		log.Warn("Failed to cache automod rules", "stream_id", streamID, "error", err)
+	}
+	return rules, nil
+}
+
+// invalidateAutoModRules сбрасывает закешированные правила после их изменения
+func (s *ChatService) invalidateAutoModRules(ctx context.Context, streamID uuid.UUID) {
+	if err := s.cache.InvalidateAutoModRules(ctx, streamID); err != nil {
+		log.Error("Failed to invalidate automod cache", "stream_id", streamID, "error", err)
+	}
+}
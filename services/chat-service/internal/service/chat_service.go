@@ -3,27 +3,117 @@ package service
 import (
 	"context"
 
+	"github.com/exPriceD/Streaming-platform/pkg/logger"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/automod"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/cache"
 	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/events"
 	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/storage"
 	"github.com/google/uuid"
 )
 
+var log = logger.InitLogger("chat-service")
+
+const (
+	DefaultHistoryLimit = 50  // Размер страницы истории по умолчанию
+	MaxHistoryLimit     = 100 // Максимальный размер страницы истории
+)
+
 // ChatService реализует бизнес-логику чата
 type ChatService struct {
-	repo repository.ChatRepository
+	repo      repository.ChatRepository
+	cache     *cache.RedisCache
+	publisher *events.Publisher
+	streams   StreamDirectory
+	audience  Audience
+	automod   *automod.Pipeline
+	writer    *MessageWriter
+	admins    map[uuid.UUID]struct{}
+	polls     *pollTracker
+	reactions *reactionTracker
+
+	emoteStorage storage.Storage // Изображения эмоутов; без хранилища загрузка недоступна
 }
 
 // NewChatService создает новый сервис
-func NewChatService(repo repository.ChatRepository) *ChatService {
-	return &ChatService{repo: repo}
+func NewChatService(repo repository.ChatRepository, cache *cache.RedisCache, publisher *events.Publisher, streams StreamDirectory) *ChatService {
+	s := &ChatService{
+		repo:      repo,
+		cache:     cache,
+		publisher: publisher,
+		streams:   streams,
+		automod:   automod.NewPipeline(automod.DefaultFilters(cache)...),
+		writer:    NewMessageWriter(repo),
+		polls:     newPollTracker(),
+		reactions: newReactionTracker(),
+	}
+	s.writer.onSaved = s.applyDeletions
+	return s
+}
+
+// SetAudience подключает источник сведений о фолловерах и подписчиках
+func (s *ChatService) SetAudience(audience Audience) {
+	s.audience = audience
+}
+
+// SetAdmins задает администраторов платформы: им доступна модерация любого чата
+func (s *ChatService) SetAdmins(ids []uuid.UUID) {
+	s.admins = make(map[uuid.UUID]struct{}, len(ids))
+	for _, id := range ids {
+		s.admins[id] = struct{}{}
+	}
+}
+
+// IsAdmin сообщает, является ли пользователь администратором платформы
+func (s *ChatService) IsAdmin(userID uuid.UUID) bool {
+	_, ok := s.admins[userID]
+	return ok
+}
+
+// Run запускает фоновую запись сообщений
+func (s *ChatService) Run() {
+	s.writer.Run()
+}
+
+// Close дожидается записи всех принятых сообщений, а затем счетчиков реакций на них
+func (s *ChatService) Close(ctx context.Context) error {
+	if err := s.writer.Close(ctx); err != nil {
+		return err
+	}
+	s.FlushReactions(ctx)
+	return nil
 }
 
-// GetMessages получает сообщения
-func (s *ChatService) GetMessages(ctx context.Context, streamID uuid.UUID, limit int) ([]*entity.ChatMessage, error) {
-	return s.repo.GetMessages(ctx, streamID, limit)
+// GetMessages получает страницу истории сообщений
+func (s *ChatService) GetMessages(ctx context.Context, query entity.MessageQuery) (*entity.MessagePage, error) {
+	if query.Limit <= 0 {
+		query.Limit = DefaultHistoryLimit
+	}
+	if query.Limit > MaxHistoryLimit {
+		query.Limit = MaxHistoryLimit
+	}
+	return s.repo.GetMessages(ctx, query)
 }
 
-// SendMessage сохраняет сообщение
+// SendMessage разбирает эмоуты в сообщении, резервирует для него место
+// в очереди записи, присваивает номер в комнате и рассылает участникам комнаты.
+// Сообщение, для которого нет места в очереди, никому не рассылается
 func (s *ChatService) SendMessage(ctx context.Context, msg *entity.ChatMessage) error {
-	return s.repo.SaveMessage(ctx, msg)
+	s.tokenize(ctx, msg)
+	return s.writer.EnqueueAfter(msg, func() error {
+		return s.sequence(ctx, msg)
+	})
+}
+
+// QueueMessage — SendMessage без контекста вызывающего
+func (s *ChatService) QueueMessage(msg *entity.ChatMessage) error {
+	return s.SendMessage(context.Background(), msg)
+}
+
+// publishEvent рассылает служебное событие участникам комнаты на всех экземплярах
+func (s *ChatService) publishEvent(ctx context.Context, streamID uuid.UUID, eventType string, event interface{}) {
+	if err := s.publisher.PublishEvent(ctx, streamID, eventType, event); err != nil {
+		log.Error("Failed to publish room event", "stream_id", streamID, "type", eventType, "error", err)
+	}
 }
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/emotes"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/storage"
+	"github.com/google/uuid"
+)
+
+// MaxChannelEmotes — сколько эмоутов можно загрузить в один канал
+const MaxChannelEmotes = 50
+
+var (
+	ErrInvalidEmoteCode    = errors.New("emote code must be 2-25 letters, digits or underscores and start with a letter")
+	ErrEmoteCodeTaken      = errors.New("emote code is already in use")
+	ErrEmoteLimit          = errors.New("channel emote limit reached")
+	ErrEmoteUploadDisabled = errors.New("emote uploads are not configured")
+)
+
+// SetEmoteStorage подключает хранилище изображений эмоутов
+func (s *ChatService) SetEmoteStorage(storage storage.Storage) {
+	s.emoteStorage = storage
+}
+
+// ListEmotes возвращает эмоуты, доступные в чате стрима: глобальный набор
+// и эмоуты канала. При нулевом streamID возвращает только глобальный набор
+func (s *ChatService) ListEmotes(ctx context.Context, streamID uuid.UUID) ([]*entity.Emote, error) {
+	result, err := s.loadEmotes(ctx, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	if streamID != uuid.Nil {
+		channel, err := s.loadEmotes(ctx, streamID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, channel...)
+	}
+
+	for _, emote := range result {
+		s.fillEmoteURLs(emote)
+	}
+	return result, nil
+}
+
+// UploadEmote проверяет изображение, сохраняет его в стандартных размерах
+// и добавляет эмоут в набор канала. Загружать эмоуты канала может бродкастер,
+// глобальные эмоуты (нулевой streamID) — администраторы платформы
+func (s *ChatService) UploadEmote(ctx context.Context, streamID, actorID uuid.UUID, code string, image []byte) (*entity.Emote, error) {
+	if s.emoteStorage == nil {
+		return nil, ErrEmoteUploadDisabled
+	}
+	if !entity.ValidEmoteCode(code) {
+		return nil, ErrInvalidEmoteCode
+	}
+	if err := s.requireEmoteManager(ctx, streamID, actorID); err != nil {
+		return nil, err
+	}
+
+	if streamID != uuid.Nil {
+		channel, err := s.loadEmotes(ctx, streamID)
+		if err != nil {
+			return nil, err
+		}
+		if len(channel) >= MaxChannelEmotes {
+			return nil, ErrEmoteLimit
+		}
+	}
+	// Глобальный код не может совпадать ни с одним кодом канала, и наоборот:
+	// иначе сообщения в разных чатах читались бы по-разному
+	taken, err := s.repo.EmoteCodeTaken(ctx, streamID, code)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrEmoteCodeTaken
+	}
+
+	processed, err := emotes.Process(image)
+	if err != nil {
+		return nil, err
+	}
+
+	emote := &entity.Emote{
+		ID:        uuid.New(),
+		StreamID:  streamID,
+		Code:      code,
+		Format:    processed.Format,
+		CreatedBy: actorID,
+		CreatedAt: time.Now().UTC(),
+	}
+	for _, img := range processed.Images {
+		if err := s.emoteStorage.Save(ctx, emotes.Key(emote.ID, img.Size, emote.Format), img.Data); err != nil {
+			s.deleteEmoteImages(ctx, emote)
+			return nil, err
+		}
+	}
+	if err := s.repo.AddEmote(ctx, emote); err != nil {
+		s.deleteEmoteImages(ctx, emote)
+		// Код успели занять параллельной загрузкой
+		if errors.Is(err, repository.ErrEmoteCodeTaken) {
+			return nil, ErrEmoteCodeTaken
+		}
+		return nil, err
+	}
+	s.invalidateEmotes(ctx, streamID)
+
+	s.fillEmoteURLs(emote)
+	return emote, nil
+}
+
+// DeleteEmote удаляет эмоут из набора канала или глобального набора вместе
+// с изображениями. Сообщения, уже разобранные с этим эмоутом, не меняются
+func (s *ChatService) DeleteEmote(ctx context.Context, streamID, emoteID, actorID uuid.UUID) error {
+	if err := s.requireEmoteManager(ctx, streamID, actorID); err != nil {
+		return err
+	}
+
+	emote, err := s.repo.RemoveEmote(ctx, streamID, emoteID)
+	if err != nil {
+		return err
+	}
+	s.invalidateEmotes(ctx, streamID)
+	s.deleteEmoteImages(ctx, emote)
+	return nil
+}
+
+// requireEmoteManager проверяет право менять набор эмоутов: глобальный
+// набор — только администраторы платформы, набор канала — еще и бродкастер
+func (s *ChatService) requireEmoteManager(ctx context.Context, streamID, actorID uuid.UUID) error {
+	if s.IsAdmin(actorID) {
+		return nil
+	}
+	if streamID == uuid.Nil {
+		return ErrForbidden
+	}
+	role, err := s.GetRole(ctx, streamID, actorID)
+	if err != nil {
+		return err
+	}
+	if role != entity.RoleBroadcaster {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// emoteSet возвращает эмоуты, которые распознаются в сообщениях чата стрима
+func (s *ChatService) emoteSet(ctx context.Context, streamID uuid.UUID) (entity.EmoteSet, error) {
+	global, err := s.loadEmotes(ctx, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	channel, err := s.loadEmotes(ctx, streamID)
+	if err != nil {
+		return nil, err
+	}
+	return entity.NewEmoteSet(global, channel), nil
+}
+
+// tokenize разбивает текст сообщения на фрагменты текста и эмоутов перед
+// рассылкой. Без набора эмоутов сообщение уходит без фрагментов
+func (s *ChatService) tokenize(ctx context.Context, msg *entity.ChatMessage) {
+	set, err := s.emoteSet(ctx, msg.StreamID)
+	if err != nil {
+		log.Warn("Failed to load emotes", "stream_id", msg.StreamID, "error", err)
+		return
+	}
+	msg.Fragments = entity.Tokenize(msg.Content, set)
+}
+
+// loadEmotes загружает эмоуты канала или глобального набора из кеша или PostgreSQL
+func (s *ChatService) loadEmotes(ctx context.Context, streamID uuid.UUID) ([]*entity.Emote, error) {
+	list, cached, err := s.cache.GetEmotes(ctx, streamID)
+	if err != nil {
+		log.Warn("Emotes cache unavailable, falling back to PostgreSQL", "error", err)
+	}
+	if cached {
+		return list, nil
+	}
+
+	list, err = s.repo.ListEmotes(ctx, streamID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cache.SetEmotes(ctx, streamID, list); err != nil {
+		log.Warn("Failed to cache emotes", "stream_id", streamID, "error", err)
+	}
+	return list, nil
+}
+
+// fillEmoteURLs заполняет ссылки на изображения эмоута во всех размерах
+func (s *ChatService) fillEmoteURLs(emote *entity.Emote) {
+	if s.emoteStorage == nil {
+		return
+	}
+	emote.URLs = make(map[string]string, len(emotes.Sizes))
+	for _, size := range emotes.Sizes {
+		emote.URLs[size.Scale] = s.emoteStorage.URL(emotes.Key(emote.ID, size, emote.Format))
+	}
+}
+
+// deleteEmoteImages удаляет изображения эмоута из хранилища
+func (s *ChatService) deleteEmoteImages(ctx context.Context, emote *entity.Emote) {
+	if s.emoteStorage == nil {
+		return
+	}
+	for _, size := range emotes.Sizes {
+		if err := s.emoteStorage.Delete(ctx, emotes.Key(emote.ID, size, emote.Format)); err != nil {
+			log.Error("Failed to delete emote image", "emote_id", emote.ID, "size", size.Pixels, "error", err)
+		}
+	}
+}
+
+// invalidateEmotes сбрасывает закешированный набор эмоутов после его изменения
+func (s *ChatService) invalidateEmotes(ctx context.Context, streamID uuid.UUID) {
+	if err := s.cache.InvalidateEmotes(ctx, streamID); err != nil {
+		log.Error("Failed to invalidate emotes cache", "stream_id", streamID, "error", err)
+	}
+}
@@ -0,0 +1,175 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/emotes"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/storage"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// emoteStore хранит эмоуты в памяти
+type emoteStore struct {
+	*pinStore
+	emotesMu sync.Mutex
+	emotes   []*entity.Emote
+}
+
+func (e *emoteStore) ListEmotes(_ context.Context, streamID uuid.UUID) ([]*entity.Emote, error) {
+	e.emotesMu.Lock()
+	defer e.emotesMu.Unlock()
+	var result []*entity.Emote
+	for _, emote := range e.emotes {
+		if emote.StreamID == streamID {
+			copied := *emote
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (e *emoteStore) AddEmote(_ context.Context, emote *entity.Emote) error {
+	e.emotesMu.Lock()
+	defer e.emotesMu.Unlock()
+	e.emotes = append(e.emotes, emote)
+	return nil
+}
+
+func (e *emoteStore) EmoteCodeTaken(_ context.Context, streamID uuid.UUID, code string) (bool, error) {
+	e.emotesMu.Lock()
+	defer e.emotesMu.Unlock()
+	for _, emote := range e.emotes {
+		if emote.Code == code && (streamID == uuid.Nil || emote.StreamID == uuid.Nil || emote.StreamID == streamID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (e *emoteStore) RemoveEmote(_ context.Context, streamID, emoteID uuid.UUID) (*entity.Emote, error) {
+	e.emotesMu.Lock()
+	defer e.emotesMu.Unlock()
+	for i, emote := range e.emotes {
+		if emote.StreamID == streamID && emote.ID == emoteID {
+			e.emotes = append(e.emotes[:i], e.emotes[i+1:]...)
+			return emote, nil
+		}
+	}
+	return nil, repository.ErrEmoteNotFound
+}
+
+func (e *emoteStore) SetRoomModes(context.Context, uuid.UUID, entity.ChatModes) error { return nil }
+
+func emoteImage(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 112, 112))))
+	return buf.Bytes()
+}
+
+func TestEmotes(t *testing.T) {
+	ctx := context.Background()
+	streamID, owner, admin, viewer := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	repo := &emoteStore{pinStore: &pinStore{
+		roleStore: &roleStore{roles: make(map[uuid.UUID]*entity.ChatRole)},
+		messages:  make(map[uuid.UUID]*entity.ChatMessage),
+		pins:      make(map[uuid.UUID]*entity.PinnedMessage),
+	}}
+	svc, _ := newTestService(t, repo, ownerDirectory(owner))
+	svc.SetAdmins([]uuid.UUID{admin})
+
+	_, err := svc.UploadEmote(ctx, streamID, owner, "PogChamp", emoteImage(t))
+	assert.ErrorIs(t, err, service.ErrEmoteUploadDisabled)
+
+	dir := t.TempDir()
+	files, err := storage.NewLocalStorage(dir, "http://cdn.test/static/")
+	require.NoError(t, err)
+	svc.SetEmoteStorage(files)
+
+	// Эмоуты канала загружает бродкастер, глобальные — только администратор
+	_, err = svc.UploadEmote(ctx, streamID, viewer, "PogChamp", emoteImage(t))
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	_, err = svc.UploadEmote(ctx, uuid.Nil, owner, "Kappa", emoteImage(t))
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	_, err = svc.UploadEmote(ctx, streamID, owner, ":Pog:", emoteImage(t))
+	assert.ErrorIs(t, err, service.ErrInvalidEmoteCode)
+	_, err = svc.UploadEmote(ctx, streamID, owner, "PogChamp", []byte("gif?"))
+	assert.ErrorIs(t, err, emotes.ErrUnsupportedFormat)
+
+	kappa, err := svc.UploadEmote(ctx, uuid.Nil, admin, "Kappa", emoteImage(t))
+	require.NoError(t, err)
+	pog, err := svc.UploadEmote(ctx, streamID, owner, "PogChamp", emoteImage(t))
+	require.NoError(t, err)
+	assert.Equal(t, "http://cdn.test/static/emotes/"+pog.ID.String()+"/28.png", pog.URLs["1x"])
+	for _, size := range emotes.Sizes {
+		assert.FileExists(t, filepath.Join(dir, filepath.FromSlash(emotes.Key(pog.ID, size, pog.Format))))
+	}
+
+	// Код канала не может повторять глобальный эмоут
+	_, err = svc.UploadEmote(ctx, streamID, owner, "Kappa", emoteImage(t))
+	assert.ErrorIs(t, err, service.ErrEmoteCodeTaken)
+	// Глобальный код не может повторять эмоут какого-либо канала
+	_, err = svc.UploadEmote(ctx, uuid.Nil, admin, "PogChamp", emoteImage(t))
+	assert.ErrorIs(t, err, service.ErrEmoteCodeTaken)
+	// Другой канал может использовать тот же код
+	_, err = svc.UploadEmote(ctx, uuid.New(), admin, "PogChamp", emoteImage(t))
+	require.NoError(t, err)
+
+	list, err := svc.ListEmotes(ctx, streamID)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, []string{"Kappa", "PogChamp"}, []string{list[0].Code, list[1].Code})
+	assert.NotEmpty(t, list[1].URLs["4x"])
+	list, err = svc.ListEmotes(ctx, uuid.Nil)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	// Сообщение разбирается на фрагменты до рассылки; регистр кода важен
+	msg := entity.NewChatMessage(streamID, viewer, "viewer", "gg PogChamp kappa Kappa")
+	require.NoError(t, svc.QueueMessage(msg))
+	require.Len(t, msg.Fragments, 4)
+	assert.Equal(t, entity.MessageFragment{Type: entity.FragmentText, Text: "gg ", Start: 0, End: 3}, msg.Fragments[0])
+	assert.Equal(t, entity.MessageFragment{Type: entity.FragmentEmote, Text: "PogChamp", Start: 3, End: 11, EmoteID: &pog.ID}, msg.Fragments[1])
+	assert.Equal(t, " kappa ", msg.Fragments[2].Text)
+	assert.Equal(t, kappa.ID, *msg.Fragments[3].EmoteID)
+	assert.Equal(t, 23, msg.Fragments[3].End)
+
+	plain := entity.NewChatMessage(streamID, viewer, "viewer", "привет PogChampion")
+	require.NoError(t, svc.QueueMessage(plain))
+	assert.Nil(t, plain.Fragments)
+
+	// В emote-only проходят эмоуты канала, глобальные эмоуты и эмодзи
+	require.NoError(t, svc.SetChatModes(ctx, streamID, owner, entity.ChatModes{EmoteOnly: true}))
+	for content, allowed := range map[string]bool{"PogChamp Kappa 🎉": true, ":PogChamp:": false, "PogChamp gg": false} {
+		err := svc.CheckChatModes(ctx, entity.NewChatMessage(streamID, viewer, "viewer", content), entity.RoleViewer)
+		if allowed {
+			assert.NoError(t, err, content)
+		} else {
+			assert.ErrorIs(t, err, service.ErrEmoteOnly, content)
+		}
+	}
+
+	// Удаление убирает эмоут из набора и изображения из хранилища
+	assert.ErrorIs(t, svc.DeleteEmote(ctx, streamID, pog.ID, viewer), service.ErrForbidden)
+	assert.ErrorIs(t, svc.DeleteEmote(ctx, streamID, kappa.ID, owner), repository.ErrEmoteNotFound)
+	require.NoError(t, svc.DeleteEmote(ctx, streamID, pog.ID, owner))
+	_, err = os.Stat(filepath.Join(dir, "emotes", pog.ID.String()))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	msg = entity.NewChatMessage(streamID, viewer, "viewer", "PogChamp")
+	require.NoError(t, svc.QueueMessage(msg))
+	assert.Nil(t, msg.Fragments)
+}
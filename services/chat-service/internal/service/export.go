@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"io"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/export"
+	"github.com/google/uuid"
+)
+
+// ExportChat выгружает лог чата стрима в формате format. Удаленные сообщения
+// вместе с модератором и причиной удаления получают только модераторы чата
+// и администраторы платформы
+func (s *ChatService) ExportChat(ctx context.Context, streamID, actorID uuid.UUID, format export.Format, w io.Writer) error {
+	role, err := s.GetRole(ctx, streamID, actorID)
+	if err != nil {
+		return err
+	}
+	moderation := role.CanModerate() || s.IsAdmin(actorID)
+
+	writer, err := export.NewWriter(format, w, moderation)
+	if err != nil {
+		return err
+	}
+	err = s.repo.ExportMessages(ctx, streamID, moderation, writer.Write)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		log.Error("Chat export failed", "stream_id", streamID, "format", format, "error", err)
+	}
+	return err
+}
@@ -0,0 +1,46 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/export"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exportStore запоминает, запрашивались ли удаленные сообщения
+type exportStore struct {
+	*roleStore
+	includeDeleted bool
+}
+
+func (e *exportStore) ExportMessages(_ context.Context, _ uuid.UUID, includeDeleted bool, _ func(*entity.ChatMessage) error) error {
+	e.includeDeleted = includeDeleted
+	return nil
+}
+
+func TestExportChatModerationData(t *testing.T) {
+	ctx := context.Background()
+	streamID, owner, viewer, admin := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	repo := &exportStore{roleStore: &roleStore{roles: make(map[uuid.UUID]*entity.ChatRole)}}
+	svc, _ := newTestService(t, repo, ownerDirectory(owner))
+	svc.SetAdmins([]uuid.UUID{admin})
+
+	for _, tc := range []struct {
+		actor      uuid.UUID
+		moderation bool
+	}{
+		{viewer, false},
+		{owner, true},
+		{admin, true},
+	} {
+		var out bytes.Buffer
+		require.NoError(t, svc.ExportChat(ctx, streamID, tc.actor, export.FormatJSONLines, &out))
+		assert.Equal(t, tc.moderation, repo.includeDeleted)
+	}
+}
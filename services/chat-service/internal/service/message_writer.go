@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+)
+
+const (
+	defaultBatchSize     = 100                    // Максимальный размер пачки для InsertMany
+	defaultBufferSize    = 10000                  // Максимальное число сообщений в очереди на запись
+	defaultFlushInterval = 500 * time.Millisecond // Период принудительного сброса неполной пачки
+	flushTimeout         = 5 * time.Second        // Таймаут одной записи в MongoDB
+	flushAttempts        = 4                      // Сколько раз пробовать записать пачку
+	flushBackoff         = 200 * time.Millisecond // Пауза перед первым повтором, далее удваивается
+)
+
+var (
+	ErrWriterBufferFull = errors.New("message writer buffer is full")
+	ErrWriterClosed     = errors.New("message writer is closed")
+)
+
+// MessageWriter асинхронно сохраняет сообщения чата пачками,
+// чтобы задержки MongoDB не тормозили рассылку сообщений
+type MessageWriter struct {
+	repo          repository.ChatRepository
+	queue         chan *entity.ChatMessage
+	slots         chan struct{} // Занятые места очереди, включая зарезервированные в EnqueueAfter
+	batchSize     int
+	flushInterval time.Duration
+	closed        bool
+	mu            sync.RWMutex
+	done          chan struct{}
+	dropped       int64 // Сколько сообщений потеряно после всех повторов записи
+
+	onSaved func(ctx context.Context, batch []*entity.ChatMessage) // Вызывается после записи каждой пачки
+}
+
+// NewMessageWriter создает писатель сообщений с настройками по умолчанию
+func NewMessageWriter(repo repository.ChatRepository) *MessageWriter {
+	return &MessageWriter{
+		repo:          repo,
+		queue:         make(chan *entity.ChatMessage, defaultBufferSize),
+		slots:         make(chan struct{}, defaultBufferSize),
+		batchSize:     defaultBatchSize,
+		flushInterval: defaultFlushInterval,
+		done:          make(chan struct{}),
+	}
+}
+
+// Enqueue ставит сообщение в очередь на запись, не блокируя вызывающего
+func (w *MessageWriter) Enqueue(msg *entity.ChatMessage) error {
+	return w.EnqueueAfter(msg, nil)
+}
+
+// EnqueueAfter резервирует место в очереди, вызывает prepare и только после
+// его успеха ставит сообщение в очередь. Если очередь заполнена или писатель
+// закрыт, prepare не вызывается: так сообщение не уходит зрителям, пока
+// не гарантировано, что оно будет записано
+func (w *MessageWriter) EnqueueAfter(msg *entity.ChatMessage, prepare func() error) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return ErrWriterClosed
+	}
+
+	select {
+	case w.slots <- struct{}{}:
+	default:
+		return ErrWriterBufferFull
+	}
+	if prepare != nil {
+		if err := prepare(); err != nil {
+			<-w.slots
+			return err
+		}
+	}
+	// Место зарезервировано, поэтому запись в очередь не блокируется
+	w.queue <- msg
+	return nil
+}
+
+// Run сохраняет сообщения из очереди, пока писатель не будет закрыт
+func (w *MessageWriter) Run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*entity.ChatMessage, 0, w.batchSize)
+	for {
+		select {
+		case msg, ok := <-w.queue:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			<-w.slots
+			batch = append(batch, msg)
+			if len(batch) >= w.batchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// Close прекращает прием сообщений и дожидается записи оставшихся
+func (w *MessageWriter) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// flush записывает пачку сообщений в репозиторий
+func (w *MessageWriter) flush(batch []*entity.ChatMessage) {
+	if len(batch) == 0 {
+		return
+	}
+
+	// Клиенты уже получили подтверждение, поэтому временный сбой MongoDB
+	// не должен терять сообщения: пачка записывается повторно с растущей паузой.
+	// Повтор безопасен — уже записанные сообщения репозиторий пропускает
+	backoff := flushBackoff
+	for attempt := 1; ; attempt++ {
+		err := w.save(batch)
+		if err == nil {
+			return
+		}
+		if attempt == flushAttempts {
+			w.dropped += int64(len(batch))
+			log.Error("Dropped message batch after retries", "size", len(batch), "attempts", attempt, "dropped_total", w.dropped, "error", err)
+			return
+		}
+		log.Warn("Failed to save message batch, retrying", "size", len(batch), "attempt", attempt, "error", err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// save выполняет одну попытку записи пачки
+func (w *MessageWriter) save(batch []*entity.ChatMessage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+
+	if err := w.repo.SaveMessages(ctx, batch); err != nil {
+		return err
+	}
+	if w.onSaved != nil {
+		w.onSaved(ctx, batch)
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/cache"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchRecorder запоминает пачки, переданные в SaveMessages
+type batchRecorder struct {
+	repository.ChatRepository
+	mu      sync.Mutex
+	batches [][]*entity.ChatMessage
+}
+
+func (r *batchRecorder) SaveMessages(_ context.Context, msgs []*entity.ChatMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, append([]*entity.ChatMessage(nil), msgs...))
+	return nil
+}
+
+func (r *batchRecorder) saved() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	total := 0
+	for _, batch := range r.batches {
+		total += len(batch)
+	}
+	return total
+}
+
+// TestMessageWriterFlushesOnClose проверяет, что при остановке
+// сохраняются все сообщения, принятые в очередь
+func TestMessageWriterFlushesOnClose(t *testing.T) {
+	repo := &batchRecorder{}
+	writer := service.NewMessageWriter(repo)
+	go writer.Run()
+
+	streamID := uuid.New()
+	for i := 0; i < 250; i++ {
+		require.NoError(t, writer.Enqueue(entity.NewChatMessage(streamID, uuid.New(), "user", "hi")))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, writer.Close(ctx))
+
+	assert.Equal(t, 250, repo.saved())
+	for _, batch := range repo.batches {
+		assert.LessOrEqual(t, len(batch), 100)
+	}
+	assert.ErrorIs(t, writer.Enqueue(entity.NewChatMessage(streamID, uuid.New(), "user", "late")), service.ErrWriterClosed)
+}
+
+// flakyRecorder отказывает в записи первые failures раз
+type flakyRecorder struct {
+	batchRecorder
+	failures int
+	attempts int
+}
+
+func (r *flakyRecorder) SaveMessages(ctx context.Context, msgs []*entity.ChatMessage) error {
+	r.mu.Lock()
+	r.attempts++
+	failed := r.attempts <= r.failures
+	r.mu.Unlock()
+	if failed {
+		return errors.New("mongo: connection reset")
+	}
+	return r.batchRecorder.SaveMessages(ctx, msgs)
+}
+
+// TestMessageWriterRetriesFailedBatch проверяет, что пачка, которую
+// не удалось записать из-за временного сбоя, записывается повторно
+func TestMessageWriterRetriesFailedBatch(t *testing.T) {
+	repo := &flakyRecorder{failures: 1}
+	writer := service.NewMessageWriter(repo)
+	go writer.Run()
+
+	streamID := uuid.New()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, writer.Enqueue(entity.NewChatMessage(streamID, uuid.New(), "user", "hi")))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, writer.Close(ctx))
+
+	assert.Equal(t, 3, repo.saved())
+	assert.Equal(t, 2, repo.attempts)
+}
+
+// TestQueueMessageNotPublishedUnlessAccepted проверяет, что сообщение,
+// не принятое в очередь записи, не получает номер и не рассылается
+func TestQueueMessageNotPublishedUnlessAccepted(t *testing.T) {
+	ctx := context.Background()
+	streamID := uuid.New()
+	repo := &deleteStore{pinStore: &pinStore{
+		roleStore: &roleStore{roles: make(map[uuid.UUID]*entity.ChatRole)},
+		messages:  make(map[uuid.UUID]*entity.ChatMessage),
+	}}
+	svc, client := newTestService(t, repo, ownerDirectory(uuid.New()))
+	go svc.Run()
+	require.NoError(t, svc.Close(ctx))
+
+	msg := entity.NewChatMessage(streamID, uuid.New(), "viewer", "too late")
+	assert.ErrorIs(t, svc.QueueMessage(msg), service.ErrWriterClosed)
+	assert.Zero(t, msg.Seq)
+
+	redisCache := cache.NewRedisCache(client)
+	seq, err := redisCache.LastSeq(ctx, streamID)
+	require.NoError(t, err)
+	assert.Zero(t, seq)
+	buffered, err := redisCache.BufferedMessagesAfter(ctx, streamID, 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, buffered)
+}
+
+// deleteStore сохраняет сообщения и помечает их удаленными, как MongoDB
+type deleteStore struct {
+	*pinStore
+	mu sync.Mutex
+}
+
+func (d *deleteStore) SaveMessages(_ context.Context, msgs []*entity.ChatMessage) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, msg := range msgs {
+		saved := *msg
+		d.messages[msg.ID] = &saved
+	}
+	return nil
+}
+
+func (d *deleteStore) DeleteMessage(_ context.Context, messageID, moderatorID uuid.UUID, reason string) (*entity.ChatMessage, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	msg, ok := d.messages[messageID]
+	if !ok {
+		return nil, repository.ErrMessageNotFound
+	}
+	msg.IsDeleted, msg.DeletedBy, msg.ModReason = true, moderatorID, reason
+	return msg, nil
+}
+
+func (d *deleteStore) DeleteUserMessages(context.Context, uuid.UUID, uuid.UUID, uuid.UUID, time.Time, string) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
+// newDeleteService создает сервис, сохраняющий сообщения в deleteStore
+func newDeleteService(t *testing.T, owner uuid.UUID) (*service.ChatService, *deleteStore) {
+	t.Helper()
+	repo := &deleteStore{pinStore: &pinStore{
+		roleStore: &roleStore{roles: make(map[uuid.UUID]*entity.ChatRole)},
+		messages:  make(map[uuid.UUID]*entity.ChatMessage),
+		pins:      make(map[uuid.UUID]*entity.PinnedMessage),
+	}}
+	svc, _ := newTestService(t, repo, ownerDirectory(owner))
+	return svc, repo
+}
+
+// flushMessages запускает очередь записи и дожидается сохранения всех сообщений
+func flushMessages(t *testing.T, svc *service.ChatService) {
+	t.Helper()
+	go svc.Run()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, svc.Close(ctx))
+}
+
+// TestClearQueuedMessages проверяет, что очистка чата и сообщений пользователя
+// применяется к сообщениям, которые еще ждали записи, но не к отправленным после нее
+func TestClearQueuedMessages(t *testing.T) {
+	ctx := context.Background()
+	streamID, owner, spammer, viewer := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	send := func(svc *service.ChatService, userID uuid.UUID) *entity.ChatMessage {
+		msg := entity.NewChatMessage(streamID, userID, "user", "message")
+		require.NoError(t, svc.QueueMessage(msg))
+		return msg
+	}
+
+	t.Run("user", func(t *testing.T) {
+		svc, repo := newDeleteService(t, owner)
+		spam, other := send(svc, spammer), send(svc, viewer)
+		_, err := svc.ClearUserMessages(ctx, streamID, spammer, owner, "spam")
+		require.NoError(t, err)
+		later := send(svc, spammer)
+		flushMessages(t, svc)
+
+		assert.True(t, repo.messages[spam.ID].IsDeleted)
+		assert.Equal(t, "spam", repo.messages[spam.ID].ModReason)
+		assert.False(t, repo.messages[other.ID].IsDeleted)
+		assert.False(t, repo.messages[later.ID].IsDeleted)
+	})
+
+	t.Run("chat", func(t *testing.T) {
+		svc, repo := newDeleteService(t, owner)
+		first, second := send(svc, spammer), send(svc, viewer)
+		_, err := svc.ClearChat(ctx, streamID, owner)
+		require.NoError(t, err)
+		later := send(svc, viewer)
+		flushMessages(t, svc)
+
+		assert.True(t, repo.messages[first.ID].IsDeleted)
+		assert.True(t, repo.messages[second.ID].IsDeleted)
+		assert.Equal(t, owner, repo.messages[second.ID].DeletedBy)
+		assert.False(t, repo.messages[later.ID].IsDeleted)
+	})
+}
+
+// TestDeleteQueuedMessage проверяет, что сообщение, удаленное до записи
+// в MongoDB, сохраняется уже удаленным
+func TestDeleteQueuedMessage(t *testing.T) {
+	ctx := context.Background()
+	streamID, owner, viewer := uuid.New(), uuid.New(), uuid.New()
+
+	svc, repo := newDeleteService(t, owner)
+
+	// Очередь записи еще не запущена, поэтому сообщение есть только в буфере
+	msg := entity.NewChatMessage(streamID, viewer, "viewer", "spam link")
+	require.NoError(t, svc.QueueMessage(msg))
+
+	_, err := svc.DeleteMessage(ctx, uuid.New(), msg.ID, owner, "spam")
+	assert.ErrorIs(t, err, repository.ErrMessageNotFound, "message of another stream")
+
+	deleted, err := svc.DeleteMessage(ctx, streamID, msg.ID, owner, "spam")
+	require.NoError(t, err)
+	assert.True(t, deleted.IsDeleted)
+
+	flushMessages(t, svc)
+
+	saved := repo.messages[msg.ID]
+	require.NotNil(t, saved)
+	assert.True(t, saved.IsDeleted)
+	assert.Equal(t, owner, saved.DeletedBy)
+	assert.Equal(t, "spam", saved.ModReason)
+}
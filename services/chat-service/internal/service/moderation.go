@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/protocol"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	MaxTimeoutDuration = 14 * 24 * time.Hour // Максимальная длительность таймаута
+	MaxBanReasonLength = 500                 // Максимальная длина причины блокировки
+	DefaultClearWindow = 24 * time.Hour      // За какой период очищаются сообщения пользователя
+)
+
+var (
+	ErrInvalidBanDuration = errors.New("timeout duration must be between 1s and 14 days")
+	ErrBanReasonTooLong   = errors.New("ban reason is too long")
+	ErrCannotBanSelf      = errors.New("moderator cannot ban themselves")
+	ErrBanned             = errors.New("user is banned")
+	ErrTimedOut           = errors.New("user is timed out")
+	ErrBanCheckFailed     = errors.New("failed to check ban status")
+)
+
+// BanUser выдает пользователю постоянный бан (duration == 0) или таймаут.
+// Модератор может заблокировать только пользователя с более низкой ролью
+func (s *ChatService) BanUser(ctx context.Context, streamID, userID, moderatorID uuid.UUID, duration time.Duration, reason string) (*entity.ChatBan, error) {
+	if duration < 0 || (duration > 0 && duration < time.Second) || duration > MaxTimeoutDuration {
+		return nil, ErrInvalidBanDuration
+	}
+	if len([]rune(reason)) > MaxBanReasonLength {
+		return nil, ErrBanReasonTooLong
+	}
+	if userID == moderatorID {
+		return nil, ErrCannotBanSelf
+	}
+	if err := s.requireModerator(ctx, streamID, moderatorID, userID); err != nil {
+		return nil, err
+	}
+
+	ban := entity.NewChatBan(streamID, userID, moderatorID, duration, reason)
+	if err := s.repo.BanUser(ctx, ban); err != nil {
+		return nil, err
+	}
+	if err := s.cache.BanUser(ctx, ban); err != nil {
+		// PostgreSQL остается источником истины, проверка бана откатится к нему
+		log.Error("Failed to cache ban", "stream_id", streamID, "user_id", userID, "error", err)
+	}
+
+	eventType := protocol.TypeUserBanned
+	if !ban.IsPermanent() {
+		eventType = protocol.TypeUserTimedOut
+	}
+	s.publishEvent(ctx, streamID, eventType, entity.NewUserBannedEvent(ban))
+	return ban, nil
+}
+
+// UnbanUser снимает бан или таймаут пользователя
+func (s *ChatService) UnbanUser(ctx context.Context, streamID, userID, moderatorID uuid.UUID) error {
+	if err := s.requireModerator(ctx, streamID, moderatorID, uuid.Nil); err != nil {
+		return err
+	}
+	if err := s.repo.UnbanUser(ctx, streamID, userID); err != nil {
+		return err
+	}
+	return s.cache.UnbanUser(ctx, streamID, userID)
+}
+
+// ListBans возвращает действующие баны и таймауты в чате стрима
+func (s *ChatService) ListBans(ctx context.Context, streamID, moderatorID uuid.UUID) ([]*entity.ChatBan, error) {
+	if err := s.requireModerator(ctx, streamID, moderatorID, uuid.Nil); err != nil {
+		return nil, err
+	}
+	return s.repo.ListActiveBans(ctx, streamID)
+}
+
+// GetActiveBan возвращает действующую блокировку пользователя или nil.
+// Проверка идет через Redis, при его недоступности — через PostgreSQL
+func (s *ChatService) GetActiveBan(ctx context.Context, streamID, userID uuid.UUID) (*entity.ChatBan, error) {
+	ban, err := s.cache.GetBan(ctx, streamID, userID)
+	if err == nil {
+		return ban, nil
+	}
+
+	log.Warn("Ban cache unavailable, falling back to PostgreSQL", "error", err)
+	return s.repo.GetActiveBan(ctx, streamID, userID)
+}
+
+// CheckBan возвращает ErrBanned или ErrTimedOut, если пользователь забанен
+// или находится в таймауте. Если проверить блокировку не удалось, пользователь
+// не допускается в чат: возвращается ErrBanCheckFailed
+func (s *ChatService) CheckBan(ctx context.Context, streamID, userID uuid.UUID) error {
+	ban, err := s.GetActiveBan(ctx, streamID, userID)
+	if err != nil {
+		log.Error("Failed to check ban", "user_id", userID, "stream_id", streamID, "error", err)
+		return ErrBanCheckFailed
+	}
+	if ban == nil {
+		return nil
+	}
+	if ban.IsPermanent() {
+		return ErrBanned
+	}
+	return fmt.Errorf("%w until %s", ErrTimedOut, ban.ExpiresAt.Format(time.RFC3339))
+}
+
+// RestoreBans заново кеширует действующие блокировки, например после потери данных Redis
+func (s *ChatService) RestoreBans(ctx context.Context) error {
+	bans, err := s.repo.ListActiveBans(ctx, uuid.Nil)
+	if err != nil {
+		return err
+	}
+	for _, ban := range bans {
+		if err := s.cache.BanUser(ctx, ban); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteMessage удаляет сообщение чата стрима и сообщает участникам комнаты,
+// что его нужно скрыть. Сообщение, которое еще ждет записи в MongoDB,
+// удаляется сразу после сохранения
+func (s *ChatService) DeleteMessage(ctx context.Context, streamID, messageID, moderatorID uuid.UUID, reason string) (*entity.ChatMessage, error) {
+	if len([]rune(reason)) > MaxBanReasonLength {
+		return nil, ErrBanReasonTooLong
+	}
+
+	msg, err := s.findMessage(ctx, streamID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	// Свои сообщения можно удалять без проверки старшинства ролей
+	target := msg.UserID
+	if target == moderatorID {
+		target = uuid.Nil
+	}
+	if err := s.requireModerator(ctx, msg.StreamID, moderatorID, target); err != nil {
+		return nil, err
+	}
+
+	// Отметка ставится до удаления в MongoDB: очередь записи проверяет ее
+	// после сохранения, поэтому сообщение удалится, когда бы оно ни было записано
+	deletion := &entity.MessageDeletion{MessageID: messageID, DeletedBy: moderatorID, Reason: reason}
+	if err := s.cache.MarkMessageDeleted(ctx, deletion); err != nil {
+		return nil, err
+	}
+	deleted, err := s.repo.DeleteMessage(ctx, messageID, moderatorID, reason)
+	switch {
+	case errors.Is(err, repository.ErrMessageNotFound):
+		msg.IsDeleted, msg.DeletedBy, msg.ModReason = true, moderatorID, reason
+	case err != nil:
+		return nil, err
+	default:
+		msg = deleted
+	}
+	s.forgetBuffered(ctx, msg.StreamID, func(m *entity.ChatMessage) bool { return m.ID == messageID })
+
+	event := entity.NewMessageDeletedEvent(msg.StreamID, moderatorID, []uuid.UUID{msg.ID}, reason)
+	s.publishEvent(ctx, msg.StreamID, protocol.TypeMessageDeleted, event)
+	return msg, nil
+}
+
+// applyDeletions удаляет только что сохраненные сообщения, которые модератор
+// удалил или очистил, пока они ждали записи
+func (s *ChatService) applyDeletions(ctx context.Context, batch []*entity.ChatMessage) {
+	ids := make([]uuid.UUID, len(batch))
+	users := make(map[uuid.UUID][]uuid.UUID)
+	for i, msg := range batch {
+		ids[i] = msg.ID
+		users[msg.StreamID] = append(users[msg.StreamID], msg.UserID)
+	}
+	deletions, err := s.cache.MessageDeletions(ctx, ids)
+	if err != nil {
+		log.Error("Failed to check deleted messages", "count", len(ids), "error", err)
+		return
+	}
+
+	var clears []*entity.MessageClear
+	for streamID, userIDs := range users {
+		streamClears, err := s.cache.MessageClears(ctx, streamID, userIDs)
+		if err != nil {
+			log.Error("Failed to check cleared messages", "stream_id", streamID, "error", err)
+			continue
+		}
+		clears = append(clears, streamClears...)
+	}
+	for _, msg := range batch {
+		for _, clear := range clears {
+			if clear.Covers(msg) {
+				deletions = append(deletions, &entity.MessageDeletion{MessageID: msg.ID, DeletedBy: clear.DeletedBy, Reason: clear.Reason})
+				break
+			}
+		}
+	}
+
+	for _, deletion := range deletions {
+		if _, err := s.repo.DeleteMessage(ctx, deletion.MessageID, deletion.DeletedBy, deletion.Reason); err != nil {
+			log.Error("Failed to delete saved message", "message_id", deletion.MessageID, "error", err)
+		}
+	}
+}
+
+// markCleared запоминает очистку, чтобы она применилась и к сообщениям,
+// которые еще ждут записи. Номер берется до удаления в MongoDB: все сообщения
+// с номером не больше него уже разосланы клиентам, и клиенты их скроют
+func (s *ChatService) markCleared(ctx context.Context, clear *entity.MessageClear) error {
+	seq, err := s.cache.LastSeq(ctx, clear.StreamID)
+	if err != nil {
+		return err
+	}
+	clear.Seq = seq
+	return s.cache.MarkMessagesCleared(ctx, clear)
+}
+
+// ClearUserMessages удаляет недавние сообщения пользователя в чате стрима.
+// Клиенты получают событие с user_id и скрывают все сообщения пользователя,
+// включая еще не сохраненные
+func (s *ChatService) ClearUserMessages(ctx context.Context, streamID, userID, moderatorID uuid.UUID, reason string) ([]uuid.UUID, error) {
+	if len([]rune(reason)) > MaxBanReasonLength {
+		return nil, ErrBanReasonTooLong
+	}
+	if err := s.requireModerator(ctx, streamID, moderatorID, userID); err != nil {
+		return nil, err
+	}
+
+	since := time.Now().UTC().Add(-DefaultClearWindow)
+	clear := &entity.MessageClear{StreamID: streamID, UserID: userID, DeletedBy: moderatorID, Reason: reason, Since: since}
+	if err := s.markCleared(ctx, clear); err != nil {
+		return nil, err
+	}
+	ids, err := s.repo.DeleteUserMessages(ctx, streamID, userID, moderatorID, since, reason)
+	if err != nil {
+		return nil, err
+	}
+	s.forgetBuffered(ctx, streamID, func(m *entity.ChatMessage) bool {
+		return m.UserID == userID && !m.Timestamp.Before(since)
+	})
+
+	event := entity.NewMessageDeletedEvent(streamID, moderatorID, ids, reason)
+	event.UserID = userID
+	s.publishEvent(ctx, streamID, protocol.TypeMessageDeleted, event)
+	return ids, nil
+}
+
+// ClearChat удаляет недавние сообщения всех пользователей в чате стрима,
+// включая еще не сохраненные. Клиенты получают событие с chat_cleared
+// и очищают чат целиком
+func (s *ChatService) ClearChat(ctx context.Context, streamID, moderatorID uuid.UUID) ([]uuid.UUID, error) {
+	if err := s.requireModerator(ctx, streamID, moderatorID, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	since := time.Now().UTC().Add(-DefaultClearWindow)
+	if err := s.markCleared(ctx, &entity.MessageClear{StreamID: streamID, DeletedBy: moderatorID, Since: since}); err != nil {
+		return nil, err
+	}
+	ids, err := s.repo.DeleteUserMessages(ctx, streamID, uuid.Nil, moderatorID, since, "")
+	if err != nil {
+		return nil, err
+	}
+	s.forgetBuffered(ctx, streamID, func(m *entity.ChatMessage) bool {
+		return !m.Timestamp.Before(since)
+	})
+
+	event := entity.NewMessageDeletedEvent(streamID, moderatorID, ids, "")
+	event.ChatCleared = true
+	s.publishEvent(ctx, streamID, protocol.TypeMessageDeleted, event)
+	return ids, nil
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/protocol"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidChatModes = errors.New("invalid chat modes")
+	ErrSlowMode         = errors.New("slow mode is enabled")
+	ErrFollowersOnly    = errors.New("chat is in followers-only mode")
+	ErrEmoteOnly        = errors.New("chat is in emote-only mode")
+	ErrSubscribersOnly  = errors.New("chat is in subscribers-only mode")
+
+	ErrAudienceUnavailable = errors.New("followers-only and subscribers-only modes are unavailable: no follower data source")
+)
+
+// Audience предоставляет сведения о фолловерах и подписчиках канала.
+// Пока источник не подключен (SetAudience), followers-only и subscribers-only
+// включить нельзя: проверить зрителей было бы не по чему
+type Audience interface {
+	FollowedAt(ctx context.Context, streamID, userID uuid.UUID) (*time.Time, error)
+	IsSubscriber(ctx context.Context, streamID, userID uuid.UUID) (bool, error)
+}
+
+// GetChatModes возвращает режимы чата стрима
+func (s *ChatService) GetChatModes(ctx context.Context, streamID uuid.UUID) (entity.ChatModes, error) {
+	cached, err := s.cache.GetModes(ctx, streamID)
+	if err != nil {
+		log.Warn("Chat modes cache unavailable, falling back to PostgreSQL", "error", err)
+	}
+	if cached != nil {
+		return *cached, nil
+	}
+
+	var modes entity.ChatModes
+	room, err := s.repo.GetRoom(ctx, streamID)
+	switch {
+	case errors.Is(err, repository.ErrRoomNotFound):
+	case err != nil:
+		return modes, err
+	default:
+		modes = room.Modes
+	}
+
+	if err := s.cache.SetModes(ctx, streamID, modes); err != nil {
+		log.Warn("Failed to cache chat modes", "stream_id", streamID, "error", err)
+	}
+	return modes, nil
+}
+
+// SetChatModes меняет режимы чата и сообщает о них участникам комнаты.
+// Доступно модераторам и бродкастеру
+func (s *ChatService) SetChatModes(ctx context.Context, streamID, actorID uuid.UUID, modes entity.ChatModes) error {
+	if !modes.Validate() {
+		return ErrInvalidChatModes
+	}
+	if (modes.FollowersOnly || modes.SubscribersOnly) && s.audience == nil {
+		return ErrAudienceUnavailable
+	}
+	if err := s.requireModerator(ctx, streamID, actorID, uuid.Nil); err != nil {
+		return err
+	}
+
+	if err := s.repo.SetRoomModes(ctx, streamID, modes); err != nil {
+		return err
+	}
+	if err := s.cache.SetModes(ctx, streamID, modes); err != nil {
+		log.Error("Failed to cache chat modes", "stream_id", streamID, "error", err)
+	}
+
+	s.publishEvent(ctx, streamID, protocol.TypeRoomState, entity.NewRoomStateEvent(streamID, actorID, modes))
+	return nil
+}
+
+// CheckChatModes проверяет, что сообщение разрешено текущими режимами чата.
+// Модераторы и бродкастер не ограничены режимами, VIP ограничены только emote-only.
+// Интервал slow mode здесь только проверяется: он начинается, когда сообщение
+// принято целиком, см. touchSlowMode
+func (s *ChatService) CheckChatModes(ctx context.Context, msg *entity.ChatMessage, role entity.Role) error {
+	if role.CanModerate() {
+		return nil
+	}
+
+	modes, err := s.GetChatModes(ctx, msg.StreamID)
+	if err != nil {
+		return err
+	}
+
+	if modes.EmoteOnly {
+		set, err := s.emoteSet(ctx, msg.StreamID)
+		if err != nil {
+			return err
+		}
+		if !entity.IsEmoteOnly(msg.Content, set) {
+			return ErrEmoteOnly
+		}
+	}
+	if role == entity.RoleVIP {
+		return nil
+	}
+
+	if modes.FollowersOnly {
+		if err := s.checkFollower(ctx, msg.StreamID, msg.UserID, modes.FollowersMinMinutes); err != nil {
+			return err
+		}
+	}
+	if modes.SubscribersOnly {
+		if err := s.checkSubscriber(ctx, msg.StreamID, msg.UserID); err != nil {
+			return err
+		}
+	}
+
+	if modes.SlowModeSeconds > 0 {
+		remaining, err := s.cache.SlowModeRemaining(ctx, msg.StreamID, msg.UserID)
+		if err != nil {
+			return err
+		}
+		if remaining > 0 {
+			return slowModeError(remaining)
+		}
+	}
+	return nil
+}
+
+// touchSlowMode начинает интервал slow mode после сообщения, которое прошло
+// все проверки, включая AutoMod. Отклоненные и задержанные сообщения интервал
+// не занимают. Если сообщение из другого подключения успело раньше,
+// возвращает ErrSlowMode
+func (s *ChatService) touchSlowMode(ctx context.Context, msg *entity.ChatMessage, role entity.Role) error {
+	if role.CanModerate() || role == entity.RoleVIP {
+		return nil
+	}
+
+	modes, err := s.GetChatModes(ctx, msg.StreamID)
+	if err != nil || modes.SlowModeSeconds == 0 {
+		return err
+	}
+
+	interval := time.Duration(modes.SlowModeSeconds) * time.Second
+	remaining, err := s.cache.TouchSlowMode(ctx, msg.StreamID, msg.UserID, interval)
+	if err != nil {
+		return err
+	}
+	if remaining > 0 {
+		return slowModeError(remaining)
+	}
+	return nil
+}
+
+// slowModeError сообщает, сколько осталось ждать до следующего сообщения
+func slowModeError(remaining time.Duration) error {
+	return fmt.Errorf("%w: wait %s", ErrSlowMode, remaining.Round(time.Second))
+}
+
+// checkFollower проверяет, что пользователь фолловит канал не меньше minMinutes
+func (s *ChatService) checkFollower(ctx context.Context, streamID, userID uuid.UUID, minMinutes int) error {
+	if s.audience == nil {
+		return ErrFollowersOnly
+	}
+	followedAt, err := s.audience.FollowedAt(ctx, streamID, userID)
+	if err != nil {
+		return err
+	}
+	if followedAt == nil || time.Since(*followedAt) < time.Duration(minMinutes)*time.Minute {
+		return ErrFollowersOnly
+	}
+	return nil
+}
+
+// checkSubscriber проверяет, что пользователь подписан на канал
+func (s *ChatService) checkSubscriber(ctx context.Context, streamID, userID uuid.UUID) error {
+	if s.audience == nil {
+		return ErrSubscribersOnly
+	}
+	ok, err := s.audience.IsSubscriber(ctx, streamID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrSubscribersOnly
+	}
+	return nil
+}
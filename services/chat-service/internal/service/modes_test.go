@@ -0,0 +1,67 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubAudience считает пользователей из followedAt фолловерами с указанного
+// времени, а пользователей из subscribers — подписчиками
+type stubAudience struct {
+	followedAt  map[uuid.UUID]time.Time
+	subscribers map[uuid.UUID]bool
+}
+
+func (a stubAudience) FollowedAt(_ context.Context, _, userID uuid.UUID) (*time.Time, error) {
+	at, ok := a.followedAt[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &at, nil
+}
+
+func (a stubAudience) IsSubscriber(_ context.Context, _, userID uuid.UUID) (bool, error) {
+	return a.subscribers[userID], nil
+}
+
+func TestAudienceModes(t *testing.T) {
+	ctx := context.Background()
+	streamID, owner, follower, subscriber := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	repo := &emoteStore{pinStore: &pinStore{
+		roleStore: &roleStore{roles: make(map[uuid.UUID]*entity.ChatRole)},
+		messages:  make(map[uuid.UUID]*entity.ChatMessage),
+		pins:      make(map[uuid.UUID]*entity.PinnedMessage),
+	}}
+	svc, _ := newTestService(t, repo, ownerDirectory(owner))
+
+	// Без источника сведений о зрителях режимы заперли бы чат для всех
+	for _, modes := range []entity.ChatModes{{FollowersOnly: true}, {SubscribersOnly: true}} {
+		assert.ErrorIs(t, svc.SetChatModes(ctx, streamID, owner, modes), service.ErrAudienceUnavailable)
+	}
+
+	svc.SetAudience(stubAudience{
+		followedAt:  map[uuid.UUID]time.Time{follower: time.Now().Add(-5 * time.Minute)},
+		subscribers: map[uuid.UUID]bool{subscriber: true},
+	})
+	check := func(userID uuid.UUID) error {
+		return svc.CheckChatModes(ctx, entity.NewChatMessage(streamID, userID, "viewer", "hi"), entity.RoleViewer)
+	}
+
+	require.NoError(t, svc.SetChatModes(ctx, streamID, owner, entity.ChatModes{FollowersOnly: true, FollowersMinMinutes: 10}))
+	assert.ErrorIs(t, check(follower), service.ErrFollowersOnly, "followed too recently")
+	require.NoError(t, svc.SetChatModes(ctx, streamID, owner, entity.ChatModes{FollowersOnly: true, FollowersMinMinutes: 1}))
+	assert.NoError(t, check(follower))
+	assert.ErrorIs(t, check(subscriber), service.ErrFollowersOnly)
+
+	require.NoError(t, svc.SetChatModes(ctx, streamID, owner, entity.ChatModes{SubscribersOnly: true}))
+	assert.NoError(t, check(subscriber))
+	assert.ErrorIs(t, check(follower), service.ErrSubscribersOnly)
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/protocol"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidPin         = errors.New("pinned text must be between 1 and 500 characters")
+	ErrInvalidPinDuration = errors.New("pin duration must be between 0 and 24h")
+	ErrNoPinnedMessage    = errors.New("no message is pinned")
+)
+
+// GetPinnedMessage возвращает закрепленное сообщение чата стрима или nil,
+// если ничего не закреплено или срок закрепления истек
+func (s *ChatService) GetPinnedMessage(ctx context.Context, streamID uuid.UUID) (*entity.PinnedMessage, error) {
+	pin, cached, err := s.cache.GetPin(ctx, streamID)
+	if err != nil {
+		log.Warn("Pinned message cache unavailable, falling back to PostgreSQL", "error", err)
+	}
+	if !cached {
+		room, err := s.repo.GetRoom(ctx, streamID)
+		switch {
+		case errors.Is(err, repository.ErrRoomNotFound):
+		case err != nil:
+			return nil, err
+		default:
+			pin = room.PinnedMessage
+		}
+
+		if err := s.cache.SetPin(ctx, streamID, pin); err != nil {
+			log.Warn("Failed to cache pinned message", "stream_id", streamID, "error", err)
+		}
+	}
+
+	if pin == nil || !pin.IsActive(time.Now()) {
+		return nil, nil
+	}
+	return pin, nil
+}
+
+// PinMessage закрепляет сообщение над чатом стрима вместо прежнего закрепленного.
+// При duration = 0 сообщение закрепляется без срока. Доступно модераторам и бродкастеру
+func (s *ChatService) PinMessage(ctx context.Context, streamID, actorID, messageID uuid.UUID, duration time.Duration) (*entity.PinnedMessage, error) {
+	if duration < 0 || duration > entity.MaxPinDuration {
+		return nil, ErrInvalidPinDuration
+	}
+	if err := s.requireModerator(ctx, streamID, actorID, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	// Только что отправленное сообщение может быть еще в очереди записи,
+	// поэтому ищется сначала в буфере повтора
+	msg, err := s.findMessage(ctx, streamID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	return s.pin(ctx, entity.NewPinnedMessage(msg, actorID, duration))
+}
+
+// PinText отправляет в чат сообщение от имени модератора и сразу закрепляет его.
+// Доступно модераторам и бродкастеру
+func (s *ChatService) PinText(ctx context.Context, streamID, actorID uuid.UUID, actorName, content string, duration time.Duration) (*entity.PinnedMessage, error) {
+	content = strings.TrimSpace(content)
+	if content == "" || utf8.RuneCountInString(content) > MaxAnnouncementLength {
+		return nil, ErrInvalidPin
+	}
+	if duration < 0 || duration > entity.MaxPinDuration {
+		return nil, ErrInvalidPinDuration
+	}
+
+	role, err := s.GetRole(ctx, streamID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !role.CanModerate() && !s.IsAdmin(actorID) {
+		return nil, ErrForbidden
+	}
+
+	msg := entity.NewChatMessage(streamID, actorID, actorName, content)
+	msg.Badges = role.Badges()
+	if err := s.QueueMessage(msg); err != nil {
+		return nil, err
+	}
+	return s.pin(ctx, entity.NewPinnedMessage(msg, actorID, duration))
+}
+
+// pin сохраняет закрепленное сообщение и рассылает его участникам комнаты
+func (s *ChatService) pin(ctx context.Context, pin *entity.PinnedMessage) (*entity.PinnedMessage, error) {
+	if err := s.repo.SetRoomPin(ctx, pin.StreamID, pin); err != nil {
+		return nil, err
+	}
+	if err := s.cache.SetPin(ctx, pin.StreamID, pin); err != nil {
+		log.Error("Failed to cache pinned message", "stream_id", pin.StreamID, "error", err)
+	}
+
+	s.publishEvent(ctx, pin.StreamID, protocol.TypeMessagePinned, pin)
+	return pin, nil
+}
+
+// UnpinMessage снимает закрепленное сообщение. Доступно модераторам и бродкастеру
+func (s *ChatService) UnpinMessage(ctx context.Context, streamID, actorID uuid.UUID) error {
+	if err := s.requireModerator(ctx, streamID, actorID, uuid.Nil); err != nil {
+		return err
+	}
+
+	pin, err := s.GetPinnedMessage(ctx, streamID)
+	if err != nil {
+		return err
+	}
+	if pin == nil {
+		return ErrNoPinnedMessage
+	}
+
+	if err := s.repo.SetRoomPin(ctx, streamID, nil); err != nil {
+		return err
+	}
+	if err := s.cache.SetPin(ctx, streamID, nil); err != nil {
+		log.Error("Failed to cache pinned message", "stream_id", streamID, "error", err)
+	}
+
+	s.publishEvent(ctx, streamID, protocol.TypeMessageUnpinned, entity.MessageUnpinnedEvent{
+		StreamID:   streamID,
+		MessageID:  pin.MessageID,
+		UnpinnedBy: actorID,
+	})
+	return nil
+}
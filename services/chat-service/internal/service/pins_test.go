@@ -0,0 +1,145 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/events"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/protocol"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pinStore хранит сообщения и закрепления комнат в памяти
+type pinStore struct {
+	*roleStore
+	pinsMu   sync.Mutex
+	messages map[uuid.UUID]*entity.ChatMessage
+	pins     map[uuid.UUID]*entity.PinnedMessage
+}
+
+func (p *pinStore) GetMessage(_ context.Context, messageID uuid.UUID) (*entity.ChatMessage, error) {
+	msg, ok := p.messages[messageID]
+	if !ok {
+		return nil, repository.ErrMessageNotFound
+	}
+	return msg, nil
+}
+
+func (p *pinStore) GetRoom(_ context.Context, streamID uuid.UUID) (*entity.ChatRoom, error) {
+	p.pinsMu.Lock()
+	defer p.pinsMu.Unlock()
+	room := entity.NewChatRoom(streamID)
+	room.PinnedMessage = p.pins[streamID]
+	return room, nil
+}
+
+func (p *pinStore) SetRoomPin(_ context.Context, streamID uuid.UUID, pin *entity.PinnedMessage) error {
+	p.pinsMu.Lock()
+	defer p.pinsMu.Unlock()
+	p.pins[streamID] = pin
+	return nil
+}
+
+func (p *pinStore) GetLastSeq(context.Context, uuid.UUID) (int64, error) { return 0, nil }
+
+func TestPinAndAnnounce(t *testing.T) {
+	ctx := context.Background()
+	streamID, owner, viewer := uuid.New(), uuid.New(), uuid.New()
+
+	msg := entity.NewChatMessage(streamID, viewer, "viewer", "check the schedule")
+	foreign := entity.NewChatMessage(uuid.New(), viewer, "viewer", "elsewhere")
+	repo := &pinStore{
+		roleStore: &roleStore{roles: make(map[uuid.UUID]*entity.ChatRole)},
+		messages:  map[uuid.UUID]*entity.ChatMessage{msg.ID: msg, foreign.ID: foreign},
+		pins:      make(map[uuid.UUID]*entity.PinnedMessage),
+	}
+	svc, client := newTestService(t, repo, ownerDirectory(owner))
+
+	room := client.Subscribe(ctx, events.RoomChannel(streamID))
+	t.Cleanup(func() { room.Close() })
+	_, err := room.Receive(ctx)
+	require.NoError(t, err)
+
+	// Закреплять могут модераторы и бродкастер, только сообщения своей комнаты
+	_, err = svc.PinMessage(ctx, streamID, viewer, msg.ID, 0)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	_, err = svc.PinMessage(ctx, streamID, owner, foreign.ID, 0)
+	assert.ErrorIs(t, err, repository.ErrMessageNotFound)
+	_, err = svc.PinMessage(ctx, streamID, owner, msg.ID, 48*time.Hour)
+	assert.ErrorIs(t, err, service.ErrInvalidPinDuration)
+
+	pin, err := svc.PinMessage(ctx, streamID, owner, msg.ID, 10*time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, pin.ExpiresAt)
+
+	var pinned entity.PinnedMessage
+	receiveRoomEvent(t, room, protocol.TypeMessagePinned, &pinned)
+	assert.Equal(t, msg.ID, pinned.MessageID)
+	assert.Equal(t, "check the schedule", pinned.Content)
+
+	got, err := svc.GetPinnedMessage(ctx, streamID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, msg.ID, got.MessageID)
+
+	// Истекшее закрепление не отдается, даже если оно еще хранится в комнате
+	expired := entity.NewPinnedMessage(msg, owner, time.Minute)
+	expired.ExpiresAt = &expired.PinnedAt
+	require.NoError(t, repo.SetRoomPin(ctx, streamID, expired))
+	require.NoError(t, client.Del(ctx, "chat:"+streamID.String()+":pin").Err())
+	got, err = svc.GetPinnedMessage(ctx, streamID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	assert.ErrorIs(t, svc.UnpinMessage(ctx, streamID, owner), service.ErrNoPinnedMessage)
+
+	// Только что отправленное сообщение закрепляется до записи в MongoDB
+	queued := entity.NewChatMessage(streamID, viewer, "viewer", "just sent")
+	require.NoError(t, svc.QueueMessage(queued))
+	pin, err = svc.PinMessage(ctx, streamID, owner, queued.ID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, queued.ID, pin.MessageID)
+
+	_, err = svc.PinMessage(ctx, streamID, owner, msg.ID, 0)
+	require.NoError(t, err)
+	require.NoError(t, svc.UnpinMessage(ctx, streamID, owner))
+	var unpinned entity.MessageUnpinnedEvent
+	receiveRoomEvent(t, room, protocol.TypeMessageUnpinned, &unpinned)
+	assert.Equal(t, msg.ID, unpinned.MessageID)
+	assert.Equal(t, owner, unpinned.UnpinnedBy)
+
+	// Объявления рассылаются отдельным типом кадра и оформляются цветом
+	_, err = svc.Announce(ctx, streamID, viewer, "viewer", "hi all", "")
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	_, err = svc.Announce(ctx, streamID, owner, "owner", "hi all", "pink")
+	assert.ErrorIs(t, err, service.ErrInvalidColor)
+
+	announcement, err := svc.Announce(ctx, streamID, owner, "owner", " raid at 9 ", "")
+	require.NoError(t, err)
+	assert.Equal(t, "raid at 9", announcement.Content)
+	assert.Equal(t, entity.AnnouncementPrimary, announcement.Color)
+	assert.Positive(t, announcement.Seq)
+
+	recvCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	for {
+		redisMsg, err := room.ReceiveMessage(recvCtx)
+		require.NoError(t, err)
+		var event events.RoomEvent
+		require.NoError(t, json.Unmarshal([]byte(redisMsg.Payload), &event))
+		if event.Message == nil {
+			continue
+		}
+		assert.Equal(t, protocol.TypeAnnouncement, event.Type)
+		assert.Equal(t, announcement.ID, event.Message.ID)
+		assert.True(t, event.Message.IsAnnouncement())
+		break
+	}
+}
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/cache"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/protocol"
+	"github.com/google/uuid"
+)
+
+// PollTallyInterval — как часто экземпляр сервиса рассылает промежуточные итоги
+// опроса, если за это время были новые голоса. Голоса, принятые разными
+// экземплярами, рассылаются каждым из них, но не чаще этого интервала
+const PollTallyInterval = time.Second
+
+var (
+	ErrInvalidPoll       = errors.New("poll needs a question, 2 to 5 options and a duration between 15s and 30m")
+	ErrPollInProgress    = errors.New("another poll is already running in this chat")
+	ErrPollClosed        = errors.New("poll is closed")
+	ErrAlreadyVoted      = errors.New("user has already voted in this poll")
+	ErrInvalidPollOption = errors.New("poll has no such option")
+	ErrVoterBanned       = errors.New("banned users cannot vote")
+)
+
+// pollTracker хранит опросы, которые ведет этот экземпляр: созданные на нем
+// или получившие на нем голоса. По ним рассылаются промежуточные итоги
+// и подводятся итоги по окончании
+type pollTracker struct {
+	mu    sync.Mutex
+	polls map[uuid.UUID]*trackedPoll
+}
+
+type trackedPoll struct {
+	poll  *entity.Poll
+	dirty bool // Были голоса после последней рассылки итогов
+}
+
+func newPollTracker() *pollTracker {
+	return &pollTracker{polls: make(map[uuid.UUID]*trackedPoll)}
+}
+
+func (t *pollTracker) track(poll *entity.Poll) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.polls[poll.ID]; !ok {
+		t.polls[poll.ID] = &trackedPoll{poll: poll}
+	}
+}
+
+func (t *pollTracker) get(pollID uuid.UUID) *entity.Poll {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if tracked, ok := t.polls[pollID]; ok {
+		return tracked.poll
+	}
+	return nil
+}
+
+func (t *pollTracker) markDirty(pollID uuid.UUID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if tracked, ok := t.polls[pollID]; ok {
+		tracked.dirty = true
+	}
+}
+
+func (t *pollTracker) forget(pollID uuid.UUID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.polls, pollID)
+}
+
+// due возвращает опросы, время которых истекло, и опросы с новыми голосами,
+// сбрасывая у последних отметку о голосах
+func (t *pollTracker) due(now time.Time) (expired, updated []*entity.Poll) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, tracked := range t.polls {
+		switch {
+		case !now.Before(tracked.poll.EndsAt):
+			expired = append(expired, tracked.poll)
+		case tracked.dirty:
+			tracked.dirty = false
+			updated = append(updated, tracked.poll)
+		}
+	}
+	return expired, updated
+}
+
+// CreatePoll запускает опрос в чате стрима. Доступно модераторам и бродкастеру.
+// В чате одновременно идет не больше одного опроса
+func (s *ChatService) CreatePoll(ctx context.Context, streamID, actorID uuid.UUID, question string, options []string, duration time.Duration) (*entity.Poll, error) {
+	poll := entity.NewPoll(streamID, actorID, question, options, duration)
+	if !poll.Validate() {
+		return nil, ErrInvalidPoll
+	}
+	if err := s.requireModerator(ctx, streamID, actorID, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	started, err := s.cache.StartPoll(ctx, streamID, poll.ID, duration)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		return nil, ErrPollInProgress
+	}
+	if err := s.repo.CreatePoll(ctx, poll); err != nil {
+		if err := s.cache.EndPoll(ctx, streamID, poll.ID); err != nil {
+			log.Error("Failed to release poll slot", "stream_id", streamID, "poll_id", poll.ID, "error", err)
+		}
+		return nil, err
+	}
+
+	s.polls.track(poll)
+	s.publishEvent(ctx, streamID, protocol.TypePollStarted, poll)
+	return poll, nil
+}
+
+// Vote засчитывает голос пользователя за вариант option. Каждый пользователь
+// голосует в опросе один раз
+func (s *ChatService) Vote(ctx context.Context, pollID, userID uuid.UUID, option int) error {
+	poll, err := s.trackedPoll(ctx, pollID)
+	if err != nil {
+		return err
+	}
+	if !poll.IsOpen(time.Now()) {
+		return ErrPollClosed
+	}
+	if option < 0 || option >= len(poll.Options) {
+		return ErrInvalidPollOption
+	}
+
+	ban, err := s.GetActiveBan(ctx, poll.StreamID, userID)
+	if err != nil {
+		return err
+	}
+	if ban != nil && ban.IsActive(time.Now()) {
+		return ErrVoterBanned
+	}
+
+	err = s.cache.CastVote(ctx, poll.StreamID, poll.ID, userID, option, time.Until(poll.EndsAt))
+	switch {
+	case errors.Is(err, cache.ErrPollClosed):
+		return ErrPollClosed
+	case errors.Is(err, cache.ErrAlreadyVoted):
+		return ErrAlreadyVoted
+	case err != nil:
+		return err
+	}
+
+	s.polls.markDirty(poll.ID)
+	return nil
+}
+
+// EndPoll досрочно завершает опрос и возвращает его итоги. Доступно модераторам и бродкастеру
+func (s *ChatService) EndPoll(ctx context.Context, pollID, actorID uuid.UUID) (*entity.Poll, error) {
+	poll, err := s.repo.GetPoll(ctx, pollID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireModerator(ctx, poll.StreamID, actorID, uuid.Nil); err != nil {
+		return nil, err
+	}
+	if poll.Status != entity.PollActive {
+		return nil, ErrPollClosed
+	}
+
+	finished, err := s.finishPoll(ctx, poll)
+	if err != nil {
+		return nil, err
+	}
+	if finished == nil {
+		// Итоги уже подвел другой запрос или экземпляр
+		return nil, ErrPollClosed
+	}
+	return finished, nil
+}
+
+// ListPolls возвращает опросы чата стрима, начиная с последнего.
+// У идущего опроса указываются промежуточные итоги
+func (s *ChatService) ListPolls(ctx context.Context, streamID uuid.UUID) ([]*entity.Poll, error) {
+	polls, err := s.repo.ListPolls(ctx, streamID)
+	if err != nil {
+		return nil, err
+	}
+	for _, poll := range polls {
+		if poll.Status != entity.PollActive {
+			continue
+		}
+		votes, err := s.cache.PollTally(ctx, poll.StreamID, poll.ID, len(poll.Options))
+		if err != nil {
+			log.Warn("Failed to load poll tally", "poll_id", poll.ID, "error", err)
+			continue
+		}
+		poll.SetTally(votes)
+	}
+	return polls, nil
+}
+
+// RestorePolls подхватывает опросы, итоги которых еще не подведены, например
+// после перезапуска сервиса. Истекшие опросы завершаются при следующем тике RunPolls
+func (s *ChatService) RestorePolls(ctx context.Context) error {
+	polls, err := s.repo.ListActivePolls(ctx)
+	if err != nil {
+		return err
+	}
+	for _, poll := range polls {
+		s.polls.track(poll)
+	}
+	return nil
+}
+
+// RunPolls рассылает промежуточные итоги опросов и подводит итоги
+// истекших опросов, пока не отменен ctx
+func (s *ChatService) RunPolls(ctx context.Context) {
+	ticker := time.NewTicker(PollTallyInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.tickPolls(ctx)
+		}
+	}
+}
+
+// tickPolls выполняет одну итерацию RunPolls
+func (s *ChatService) tickPolls(ctx context.Context) {
+	expired, updated := s.polls.due(time.Now())
+	for _, poll := range expired {
+		if _, err := s.finishPoll(ctx, poll); err != nil {
+			log.Error("Failed to finish poll", "poll_id", poll.ID, "error", err)
+		}
+	}
+	for _, poll := range updated {
+		votes, err := s.cache.PollTally(ctx, poll.StreamID, poll.ID, len(poll.Options))
+		if err != nil {
+			log.Error("Failed to load poll tally", "poll_id", poll.ID, "error", err)
+			continue
+		}
+		event := &entity.PollTallyEvent{StreamID: poll.StreamID, PollID: poll.ID, Votes: votes}
+		for _, count := range votes {
+			event.TotalVotes += count
+		}
+		s.publishEvent(ctx, poll.StreamID, protocol.TypePollUpdated, event)
+	}
+}
+
+// trackedPoll возвращает опрос из числа отслеживаемых или загружает его.
+// Идущий опрос начинает отслеживаться, чтобы этот экземпляр рассылал по нему итоги
+func (s *ChatService) trackedPoll(ctx context.Context, pollID uuid.UUID) (*entity.Poll, error) {
+	if poll := s.polls.get(pollID); poll != nil {
+		return poll, nil
+	}
+	poll, err := s.repo.GetPoll(ctx, pollID)
+	if err != nil {
+		return nil, err
+	}
+	if poll.Status == entity.PollActive {
+		s.polls.track(poll)
+	}
+	return poll, nil
+}
+
+// finishPoll закрывает опрос для голосования, записывает итоги и рассылает их.
+// Возвращает nil, если итоги уже записаны другим экземпляром
+func (s *ChatService) finishPoll(ctx context.Context, poll *entity.Poll) (*entity.Poll, error) {
+	// Сначала опрос перестает принимать голоса, затем считаются итоги
+	if err := s.cache.EndPoll(ctx, poll.StreamID, poll.ID); err != nil {
+		return nil, err
+	}
+	votes, err := s.cache.PollTally(ctx, poll.StreamID, poll.ID, len(poll.Options))
+	if err != nil {
+		return nil, err
+	}
+
+	finished := *poll
+	finished.Options = append([]entity.PollOption(nil), poll.Options...)
+	finished.SetTally(votes)
+	finished.Status = entity.PollEnded
+	endedAt := time.Now().UTC()
+	if endedAt.After(poll.EndsAt) {
+		endedAt = poll.EndsAt
+	}
+	finished.EndedAt = &endedAt
+
+	ok, err := s.repo.FinishPoll(ctx, &finished)
+	if err != nil {
+		return nil, err
+	}
+	s.polls.forget(poll.ID)
+	if !ok {
+		return nil, nil
+	}
+
+	s.publishEvent(ctx, poll.StreamID, protocol.TypePollEnded, &finished)
+	return &finished, nil
+}
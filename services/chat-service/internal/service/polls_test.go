@@ -0,0 +1,135 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/events"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/protocol"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pollStore хранит опросы в памяти
+type pollStore struct {
+	*roleStore
+	pollsMu sync.Mutex
+	polls   map[uuid.UUID]entity.Poll
+}
+
+func (p *pollStore) CreatePoll(_ context.Context, poll *entity.Poll) error {
+	p.pollsMu.Lock()
+	defer p.pollsMu.Unlock()
+	p.polls[poll.ID] = *poll
+	return nil
+}
+
+func (p *pollStore) GetPoll(_ context.Context, pollID uuid.UUID) (*entity.Poll, error) {
+	p.pollsMu.Lock()
+	defer p.pollsMu.Unlock()
+	poll, ok := p.polls[pollID]
+	if !ok {
+		return nil, repository.ErrPollNotFound
+	}
+	return &poll, nil
+}
+
+func (p *pollStore) FinishPoll(_ context.Context, poll *entity.Poll) (bool, error) {
+	p.pollsMu.Lock()
+	defer p.pollsMu.Unlock()
+	if p.polls[poll.ID].Status != entity.PollActive {
+		return false, nil
+	}
+	p.polls[poll.ID] = *poll
+	return true, nil
+}
+
+func TestPollLifecycle(t *testing.T) {
+	ctx := context.Background()
+	streamID, owner, viewer, other := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	repo := &pollStore{
+		roleStore: &roleStore{roles: make(map[uuid.UUID]*entity.ChatRole)},
+		polls:     make(map[uuid.UUID]entity.Poll),
+	}
+	svc, client := newTestService(t, repo, ownerDirectory(owner))
+
+	room := client.Subscribe(ctx, events.RoomChannel(streamID))
+	t.Cleanup(func() { room.Close() })
+	_, err := room.Receive(ctx)
+	require.NoError(t, err)
+
+	// Опросы запускают модераторы и бродкастер, параметры проверяются
+	_, err = svc.CreatePoll(ctx, streamID, viewer, "Next game?", []string{"A", "B"}, time.Minute)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	_, err = svc.CreatePoll(ctx, streamID, owner, "Next game?", []string{"A"}, time.Minute)
+	assert.ErrorIs(t, err, service.ErrInvalidPoll)
+
+	poll, err := svc.CreatePoll(ctx, streamID, owner, "Next game?", []string{"Chess", "Go", "Poker"}, time.Minute)
+	require.NoError(t, err)
+	_, err = svc.CreatePoll(ctx, streamID, owner, "Another?", []string{"Yes", "No"}, time.Minute)
+	assert.ErrorIs(t, err, service.ErrPollInProgress)
+
+	// Один голос на пользователя
+	require.NoError(t, svc.Vote(ctx, poll.ID, viewer, 1))
+	assert.ErrorIs(t, svc.Vote(ctx, poll.ID, viewer, 0), service.ErrAlreadyVoted)
+	assert.ErrorIs(t, svc.Vote(ctx, poll.ID, other, 3), service.ErrInvalidPollOption)
+	require.NoError(t, svc.Vote(ctx, poll.ID, other, 1))
+
+	// Промежуточные итоги рассылаются пачкой по таймеру
+	runCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	go svc.RunPolls(runCtx)
+
+	var tally entity.PollTallyEvent
+	receiveRoomEvent(t, room, protocol.TypePollStarted, nil)
+	receiveRoomEvent(t, room, protocol.TypePollUpdated, &tally)
+	assert.Equal(t, []int{0, 2, 0}, tally.Votes)
+	assert.Equal(t, 2, tally.TotalVotes)
+
+	// Досрочное завершение записывает итоги и закрывает голосование
+	ended, err := svc.EndPoll(ctx, poll.ID, owner)
+	require.NoError(t, err)
+	assert.Equal(t, entity.PollEnded, ended.Status)
+	assert.Equal(t, 2, ended.Options[1].Votes)
+	assert.Equal(t, 2, repo.polls[poll.ID].TotalVotes)
+	assert.ErrorIs(t, svc.Vote(ctx, poll.ID, owner, 0), service.ErrPollClosed)
+
+	var final entity.Poll
+	receiveRoomEvent(t, room, protocol.TypePollEnded, &final)
+	assert.Equal(t, 2, final.TotalVotes)
+
+	// После завершения можно запустить следующий опрос
+	_, err = svc.CreatePoll(ctx, streamID, owner, "Another?", []string{"Yes", "No"}, time.Minute)
+	require.NoError(t, err)
+}
+
+// receiveRoomEvent ждет событие комнаты типа eventType и разбирает его данные в v
+func receiveRoomEvent(t *testing.T, room *redis.PubSub, eventType string, v interface{}) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	for {
+		msg, err := room.ReceiveMessage(ctx)
+		require.NoError(t, err)
+
+		var event events.RoomEvent
+		require.NoError(t, json.Unmarshal([]byte(msg.Payload), &event))
+		if event.Type != eventType {
+			continue
+		}
+		if v != nil {
+			require.NoError(t, json.Unmarshal(event.Data, v))
+		}
+		return
+	}
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultChattersLimit = 100  // Размер страницы списка пользователей в чате по умолчанию
+	MaxChattersLimit     = 1000 // Максимальный размер страницы списка пользователей в чате
+)
+
+// ErrUnknownChatter возвращается, если пользователя с таким именем нет в чате
+var ErrUnknownChatter = errors.New("user not found in chat")
+
+// ResolveChatter находит ID пользователя по имени (с @ или без) среди
+// присутствующих в чате и авторов недавних сообщений. Вместо имени можно
+// передать ID пользователя
+func (s *ChatService) ResolveChatter(ctx context.Context, streamID uuid.UUID, name string) (uuid.UUID, error) {
+	name = strings.TrimPrefix(name, "@")
+	if id, err := uuid.Parse(name); err == nil {
+		return id, nil
+	}
+
+	found, err := s.lookupChatters(ctx, streamID, []string{name})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	chatter, ok := found[strings.ToLower(name)]
+	if !ok {
+		return uuid.Nil, ErrUnknownChatter
+	}
+	return chatter.UserID, nil
+}
+
+// lookupChatters находит пользователей по именам без учета регистра среди
+// присутствующих в чате, а затем среди авторов недавних сообщений: нарушитель
+// мог успеть выйти из чата. Ключ результата — имя в нижнем регистре
+func (s *ChatService) lookupChatters(ctx context.Context, streamID uuid.UUID, names []string) (map[string]entity.Mention, error) {
+	wanted := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		wanted[strings.ToLower(name)] = struct{}{}
+	}
+	found := make(map[string]entity.Mention, len(names))
+	match := func(userID uuid.UUID, username string) {
+		key := strings.ToLower(username)
+		if _, ok := wanted[key]; !ok {
+			return
+		}
+		if _, ok := found[key]; !ok {
+			found[key] = entity.Mention{UserID: userID, Username: username}
+		}
+	}
+
+	chatters, err := s.cache.ListChatters(ctx, streamID)
+	if err != nil {
+		return nil, err
+	}
+	for _, chatter := range chatters {
+		match(chatter.UserID, chatter.Username)
+	}
+	if len(found) == len(wanted) {
+		return found, nil
+	}
+
+	recent, err := s.cache.BufferedMessagesAfter(ctx, streamID, 0, MaxReplayMessages)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(recent) - 1; i >= 0; i-- {
+		match(recent[i].UserID, recent[i].Username)
+	}
+	return found, nil
+}
+
+// TouchPresence отмечает пользователей присутствующими в чате стрима.
+// Вызывается при входе и периодически как heartbeat
+func (s *ChatService) TouchPresence(ctx context.Context, streamID uuid.UUID, chatters []entity.Chatter) error {
+	return s.cache.TouchPresence(ctx, streamID, chatters)
+}
+
+// LeavePresence отмечает, что пользователь вышел из чата стрима
+func (s *ChatService) LeavePresence(ctx context.Context, streamID, userID uuid.UUID) error {
+	return s.cache.RemovePresence(ctx, streamID, userID)
+}
+
+// CountChatters возвращает число пользователей в чате стрима
+func (s *ChatService) CountChatters(ctx context.Context, streamID uuid.UUID) (int, error) {
+	return s.cache.CountChatters(ctx, streamID)
+}
+
+// ListChatters возвращает страницу пользователей в чате стрима, сгруппированную
+// по ролям, и число пользователей каждой роли
+func (s *ChatService) ListChatters(ctx context.Context, streamID uuid.UUID, offset, limit int) (*entity.ChatterPage, error) {
+	if limit <= 0 {
+		limit = DefaultChattersLimit
+	}
+	if limit > MaxChattersLimit {
+		limit = MaxChattersLimit
+	}
+
+	chatters, err := s.cache.ListChatters(ctx, streamID)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(chatters, func(a, b entity.Chatter) int {
+		switch {
+		case a.Role.Outranks(b.Role):
+			return -1
+		case b.Role.Outranks(a.Role):
+			return 1
+		}
+		return strings.Compare(strings.ToLower(a.Username), strings.ToLower(b.Username))
+	})
+
+	page := &entity.ChatterPage{
+		Total:    len(chatters),
+		Counts:   make(map[entity.Role]int),
+		Chatters: make(map[entity.Role][]entity.Chatter),
+	}
+	for _, chatter := range chatters {
+		page.Counts[chatter.Role]++
+	}
+
+	offset = min(offset, len(chatters))
+	end := min(offset+limit, len(chatters))
+	for _, chatter := range chatters[offset:end] {
+		page.Chatters[chatter.Role] = append(page.Chatters[chatter.Role], chatter)
+	}
+	if end < len(chatters) {
+		page.HasMore = true
+		page.NextOffset = end
+	}
+	return page, nil
+}
+
+// updatePresenceRole обновляет роль пользователя в списке присутствия
+func (s *ChatService) updatePresenceRole(ctx context.Context, streamID, userID uuid.UUID, role entity.Role) {
+	if err := s.cache.SetPresenceRole(ctx, streamID, userID, role); err != nil {
+		log.Warn("Failed to update chatter role", "stream_id", streamID, "user_id", userID, "error", err)
+	}
+}
@@ -0,0 +1,66 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestListChattersGroupedByRole проверяет порядок, группировку и постраничный вывод
+func TestListChattersGroupedByRole(t *testing.T) {
+	ctx := context.Background()
+	streamID := uuid.New()
+	svc := newRoleService(t, uuid.New())
+
+	chatters := []entity.Chatter{
+		{UserID: uuid.New(), Username: "zoe", Role: entity.RoleViewer},
+		{UserID: uuid.New(), Username: "Bob", Role: entity.RoleViewer},
+		{UserID: uuid.New(), Username: "mod", Role: entity.RoleModerator},
+		{UserID: uuid.New(), Username: "owner", Role: entity.RoleBroadcaster},
+		{UserID: uuid.New(), Username: "alice", Role: entity.RoleViewer},
+	}
+	require.NoError(t, svc.TouchPresence(ctx, streamID, chatters))
+
+	page, err := svc.ListChatters(ctx, streamID, 0, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 5, page.Total)
+	assert.Equal(t, map[entity.Role]int{entity.RoleBroadcaster: 1, entity.RoleModerator: 1, entity.RoleViewer: 3}, page.Counts)
+	assert.Equal(t, "owner", page.Chatters[entity.RoleBroadcaster][0].Username)
+	assert.Equal(t, "mod", page.Chatters[entity.RoleModerator][0].Username)
+	require.Len(t, page.Chatters[entity.RoleViewer], 1)
+	assert.Equal(t, "alice", page.Chatters[entity.RoleViewer][0].Username)
+	assert.True(t, page.HasMore)
+
+	page, err = svc.ListChatters(ctx, streamID, page.NextOffset, 3)
+	require.NoError(t, err)
+	require.Len(t, page.Chatters[entity.RoleViewer], 2)
+	assert.Equal(t, "Bob", page.Chatters[entity.RoleViewer][0].Username)
+	assert.Equal(t, "zoe", page.Chatters[entity.RoleViewer][1].Username)
+	assert.False(t, page.HasMore)
+}
+
+// TestResolveChatter проверяет поиск пользователя по имени для команд модерации
+func TestResolveChatter(t *testing.T) {
+	ctx := context.Background()
+	streamID := uuid.New()
+	svc := newRoleService(t, uuid.New())
+
+	bob := entity.Chatter{UserID: uuid.New(), Username: "Bob", Role: entity.RoleViewer}
+	require.NoError(t, svc.TouchPresence(ctx, streamID, []entity.Chatter{bob}))
+
+	for _, name := range []string{"bob", "@Bob", bob.UserID.String()} {
+		id, err := svc.ResolveChatter(ctx, streamID, name)
+		require.NoError(t, err, name)
+		assert.Equal(t, bob.UserID, id, name)
+	}
+
+	_, err := svc.ResolveChatter(ctx, streamID, "alice")
+	assert.ErrorIs(t, err, service.ErrUnknownChatter)
+	_, err = svc.ResolveChatter(ctx, uuid.New(), "bob")
+	assert.ErrorIs(t, err, service.ErrUnknownChatter)
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/cache"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/protocol"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	// ReactionUpdateInterval — как часто экземпляр сервиса рассылает новые
+	// счетчики реакций. Реакции, принятые разными экземплярами, рассылаются
+	// каждым из них, но по одному сообщению не чаще этого интервала
+	ReactionUpdateInterval = time.Second
+	// ReactionFlushInterval — как часто счетчики реакций записываются в MongoDB
+	ReactionFlushInterval = 5 * time.Second
+
+	reactionFlushBatch = 500 // Сколько сообщений записывается за одну итерацию
+)
+
+var (
+	ErrInvalidReaction         = errors.New("reaction must be an emote available in this chat or an emoji")
+	ErrReactionMessageNotFound = errors.New("message to react to not found")
+	ErrReactionExpired         = errors.New("message is too old to react to")
+	ErrReactorBanned           = errors.New("banned users cannot react")
+)
+
+// reactionTracker хранит сообщения, реакции на которые менялись на этом
+// экземпляре после последней рассылки счетчиков
+type reactionTracker struct {
+	mu    sync.Mutex
+	dirty map[uuid.UUID]uuid.UUID // ID стрима по ID сообщения
+}
+
+func newReactionTracker() *reactionTracker {
+	return &reactionTracker{dirty: make(map[uuid.UUID]uuid.UUID)}
+}
+
+func (t *reactionTracker) markDirty(streamID, messageID uuid.UUID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.dirty[messageID] = streamID
+}
+
+// take возвращает измененные сообщения и сбрасывает отметки
+func (t *reactionTracker) take() map[uuid.UUID]uuid.UUID {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	dirty := t.dirty
+	t.dirty = make(map[uuid.UUID]uuid.UUID)
+	return dirty
+}
+
+// React ставит реакцию пользователя на сообщение вместо прежней: у каждого
+// пользователя на сообщении одна реакция. Реакция — код эмоута, доступного
+// в чате, или эмодзи
+func (s *ChatService) React(ctx context.Context, streamID, messageID, userID uuid.UUID, reaction string) (*entity.MessageReactions, error) {
+	if utf8.RuneCountInString(reaction) > entity.MaxReactionLength {
+		return nil, ErrInvalidReaction
+	}
+	if !entity.IsEmoji(reaction) {
+		set, err := s.emoteSet(ctx, streamID)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := set[reaction]; !ok {
+			return nil, ErrInvalidReaction
+		}
+	}
+	return s.setReaction(ctx, streamID, messageID, userID, reaction)
+}
+
+// RemoveReaction снимает реакцию пользователя с сообщения
+func (s *ChatService) RemoveReaction(ctx context.Context, streamID, messageID, userID uuid.UUID) (*entity.MessageReactions, error) {
+	return s.setReaction(ctx, streamID, messageID, userID, "")
+}
+
+// GetReactions возвращает счетчики реакций на сообщение и реакцию пользователя
+// userID (uuid.Nil — без нее). Когда счетчиков уже нет в Redis, они берутся
+// из MongoDB
+func (s *ChatService) GetReactions(ctx context.Context, streamID, messageID, userID uuid.UUID) (*entity.MessageReactions, error) {
+	msg, err := s.reactionTarget(ctx, streamID, messageID)
+	if err != nil && !errors.Is(err, ErrReactionExpired) {
+		return nil, err
+	}
+
+	result, err := s.loadReactions(ctx, streamID, messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Counts) == 0 && len(msg.Reactions) > 0 {
+		result.Counts = msg.Reactions
+	}
+	return result, nil
+}
+
+// setReaction заменяет реакцию пользователя и отмечает сообщение для рассылки счетчиков
+func (s *ChatService) setReaction(ctx context.Context, streamID, messageID, userID uuid.UUID, reaction string) (*entity.MessageReactions, error) {
+	if _, err := s.reactionTarget(ctx, streamID, messageID); err != nil {
+		return nil, err
+	}
+
+	ban, err := s.GetActiveBan(ctx, streamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if ban != nil && ban.IsActive(time.Now()) {
+		return nil, ErrReactorBanned
+	}
+
+	changed, err := s.cache.SetReaction(ctx, streamID, messageID, userID, reaction)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		s.reactions.markDirty(streamID, messageID)
+	}
+	return s.loadReactions(ctx, streamID, messageID, userID)
+}
+
+// reactionTarget находит сообщение, на которое реагирует пользователь.
+// Для слишком старого сообщения возвращает его вместе с ErrReactionExpired
+func (s *ChatService) reactionTarget(ctx context.Context, streamID, messageID uuid.UUID) (*entity.ChatMessage, error) {
+	msg, err := s.findMessage(ctx, streamID, messageID)
+	if errors.Is(err, repository.ErrMessageNotFound) {
+		return nil, ErrReactionMessageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if time.Since(msg.Timestamp) > entity.MaxReactionAge {
+		return msg, ErrReactionExpired
+	}
+	return msg, nil
+}
+
+// loadReactions возвращает текущие счетчики реакций на сообщение из Redis
+func (s *ChatService) loadReactions(ctx context.Context, streamID, messageID, userID uuid.UUID) (*entity.MessageReactions, error) {
+	counts, err := s.cache.ReactionCounts(ctx, streamID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	result := &entity.MessageReactions{StreamID: streamID, MessageID: messageID, Counts: counts}
+	if userID != uuid.Nil {
+		if result.Reaction, err = s.cache.UserReaction(ctx, streamID, messageID, userID); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// RunReactions рассылает новые счетчики реакций и записывает их в MongoDB,
+// пока не отменен ctx. Незаписанные счетчики остаются в очереди в Redis
+// и будут записаны после перезапуска или другим экземпляром
+func (s *ChatService) RunReactions(ctx context.Context) {
+	updates := time.NewTicker(ReactionUpdateInterval)
+	defer updates.Stop()
+	flushes := time.NewTicker(ReactionFlushInterval)
+	defer flushes.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-updates.C:
+			s.publishReactions(ctx)
+		case <-flushes.C:
+			s.FlushReactions(ctx)
+		}
+	}
+}
+
+// publishReactions рассылает участникам комнат счетчики реакций на сообщения,
+// измененные на этом экземпляре с прошлой рассылки
+func (s *ChatService) publishReactions(ctx context.Context) {
+	for messageID, streamID := range s.reactions.take() {
+		event, err := s.loadReactions(ctx, streamID, messageID, uuid.Nil)
+		if err != nil {
+			log.Error("Failed to load reaction counts", "message_id", messageID, "error", err)
+			continue
+		}
+		s.publishEvent(ctx, streamID, protocol.TypeReactionsUpdated, event)
+	}
+}
+
+// FlushReactions записывает в MongoDB счетчики реакций, измененные на любом
+// экземпляре сервиса
+func (s *ChatService) FlushReactions(ctx context.Context) {
+	for {
+		refs, err := s.cache.TakeDirtyReactions(ctx, reactionFlushBatch)
+		if err != nil {
+			log.Error("Failed to read pending reactions", "error", err)
+			return
+		}
+
+		var failed []cache.ReactionRef
+		for _, ref := range refs {
+			counts, err := s.cache.ReactionCounts(ctx, ref.StreamID, ref.MessageID)
+			if err == nil {
+				err = s.repo.SetMessageReactions(ctx, ref.MessageID, counts)
+			}
+			switch {
+			case errors.Is(err, repository.ErrMessageNotFound) && len(counts) > 0:
+				// Сообщение еще в очереди на запись: счетчики запишутся со следующей попытки.
+				// Сообщение, так и не попавшее в MongoDB, перестанет повторяться,
+				// когда счетчики истекут в Redis
+				failed = append(failed, ref)
+			case errors.Is(err, repository.ErrMessageNotFound):
+			case err != nil:
+				log.Error("Failed to save reactions", "message_id", ref.MessageID, "error", err)
+				failed = append(failed, ref)
+			}
+		}
+		if err := s.cache.MarkReactionsDirty(ctx, failed...); err != nil {
+			log.Error("Failed to requeue reactions", "count", len(failed), "error", err)
+		}
+
+		if len(refs) < reactionFlushBatch || len(failed) > 0 {
+			return
+		}
+	}
+}
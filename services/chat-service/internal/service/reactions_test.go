@@ -0,0 +1,109 @@
+package service_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/events"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/protocol"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reactionStore запоминает счетчики реакций, записанные в хранилище
+type reactionStore struct {
+	*emoteStore
+	reactionsMu sync.Mutex
+	reactions   map[uuid.UUID]map[string]int
+}
+
+func (r *reactionStore) SetMessageReactions(_ context.Context, messageID uuid.UUID, counts map[string]int) error {
+	if _, ok := r.messages[messageID]; !ok {
+		return repository.ErrMessageNotFound
+	}
+	r.reactionsMu.Lock()
+	defer r.reactionsMu.Unlock()
+	r.reactions[messageID] = counts
+	return nil
+}
+
+func TestReactions(t *testing.T) {
+	ctx := context.Background()
+	streamID, owner, viewer, other := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	msg := entity.NewChatMessage(streamID, owner, "owner", "going live")
+	old := entity.NewChatMessage(streamID, owner, "owner", "yesterday")
+	old.Timestamp = time.Now().Add(-entity.MaxReactionAge - time.Minute)
+	old.Reactions = map[string]int{"🔥": 3}
+	foreign := entity.NewChatMessage(uuid.New(), owner, "owner", "elsewhere")
+	repo := &reactionStore{
+		emoteStore: &emoteStore{
+			pinStore: &pinStore{
+				roleStore: &roleStore{roles: make(map[uuid.UUID]*entity.ChatRole)},
+				messages:  map[uuid.UUID]*entity.ChatMessage{msg.ID: msg, old.ID: old, foreign.ID: foreign},
+				pins:      make(map[uuid.UUID]*entity.PinnedMessage),
+			},
+			emotes: []*entity.Emote{{ID: uuid.New(), StreamID: streamID, Code: "PogChamp"}},
+		},
+		reactions: make(map[uuid.UUID]map[string]int),
+	}
+	svc, client := newTestService(t, repo, ownerDirectory(owner))
+
+	room := client.Subscribe(ctx, events.RoomChannel(streamID))
+	t.Cleanup(func() { room.Close() })
+	_, err := room.Receive(ctx)
+	require.NoError(t, err)
+
+	// Реакция — эмодзи или эмоут чата, только на свежие сообщения своей комнаты
+	for _, reaction := range []string{"", "gg", "Kappa", "🔥gg"} {
+		_, err := svc.React(ctx, streamID, msg.ID, viewer, reaction)
+		assert.ErrorIs(t, err, service.ErrInvalidReaction, reaction)
+	}
+	_, err = svc.React(ctx, streamID, foreign.ID, viewer, "🔥")
+	assert.ErrorIs(t, err, service.ErrReactionMessageNotFound)
+	_, err = svc.React(ctx, streamID, old.ID, viewer, "🔥")
+	assert.ErrorIs(t, err, service.ErrReactionExpired)
+
+	// У пользователя одна реакция: новая заменяет прежнюю
+	_, err = svc.React(ctx, streamID, msg.ID, viewer, "🔥")
+	require.NoError(t, err)
+	_, err = svc.React(ctx, streamID, msg.ID, other, "PogChamp")
+	require.NoError(t, err)
+	reactions, err := svc.React(ctx, streamID, msg.ID, viewer, "PogChamp")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"PogChamp": 2}, reactions.Counts)
+	assert.Equal(t, "PogChamp", reactions.Reaction)
+
+	// Счетчики рассылаются комнате пачкой по таймеру
+	runCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	go svc.RunReactions(runCtx)
+
+	var update entity.MessageReactions
+	receiveRoomEvent(t, room, protocol.TypeReactionsUpdated, &update)
+	assert.Equal(t, msg.ID, update.MessageID)
+	assert.Equal(t, map[string]int{"PogChamp": 2}, update.Counts)
+	assert.Empty(t, update.Reaction)
+
+	reactions, err = svc.RemoveReaction(ctx, streamID, msg.ID, other)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"PogChamp": 1}, reactions.Counts)
+	assert.Empty(t, reactions.Reaction)
+
+	// Счетчики записываются в хранилище
+	svc.FlushReactions(ctx)
+	repo.reactionsMu.Lock()
+	assert.Equal(t, map[string]int{"PogChamp": 1}, repo.reactions[msg.ID])
+	repo.reactionsMu.Unlock()
+
+	// Для старых сообщений счетчики берутся из хранилища
+	reactions, err = svc.GetReactions(ctx, streamID, old.ID, viewer)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"🔥": 3}, reactions.Counts)
+}
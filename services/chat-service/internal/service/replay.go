@@ -0,0 +1,107 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/cache"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/events"
+	"github.com/google/uuid"
+)
+
+// MaxReplayMessages — сколько пропущенных сообщений повторяется при переподключении
+const MaxReplayMessages = cache.ReplayBufferSize
+
+// sequence присваивает сообщению следующий номер в комнате, кладет его
+// в буфер повтора и рассылает участникам комнаты. Все три шага выполняются
+// одной операцией Redis, поэтому клиенты получают сообщения строго
+// по возрастанию номера, через какой бы экземпляр их ни отправили.
+// Вызывается, когда место в очереди записи уже зарезервировано (см. SendMessage)
+func (s *ChatService) sequence(ctx context.Context, msg *entity.ChatMessage) error {
+	msg.Seq = 0
+	event, err := json.Marshal(events.NewMessageEvent(msg))
+	if err != nil {
+		return err
+	}
+	channel := events.RoomChannel(msg.StreamID)
+
+	seq, err := s.cache.PublishMessage(ctx, msg, -1, channel, event)
+	if err != nil {
+		return err
+	}
+	if seq == 0 {
+		// Счетчик пропал из Redis: нумерация продолжается после последнего сохраненного номера
+		last, err := s.repo.GetLastSeq(ctx, msg.StreamID)
+		if err != nil {
+			return err
+		}
+		if seq, err = s.cache.PublishMessage(ctx, msg, last, channel, event); err != nil {
+			return err
+		}
+	}
+	msg.Seq = seq
+	return nil
+}
+
+// ReplayMessages возвращает сообщения комнаты с номером больше afterSeq.
+// Свежие сообщения берутся из буфера Redis, а если буфер не покрывает
+// окно целиком — дополняются из MongoDB. Повторяется не больше
+// MaxReplayMessages последних сообщений
+func (s *ChatService) ReplayMessages(ctx context.Context, streamID uuid.UUID, afterSeq int64) (*entity.MessageReplay, error) {
+	messages, err := s.cache.BufferedMessagesAfter(ctx, streamID, afterSeq, MaxReplayMessages+1)
+	if err != nil {
+		log.Warn("Failed to read replay buffer", "stream_id", streamID, "error", err)
+		messages = nil
+	}
+
+	if len(messages) == 0 || messages[0].Seq != afterSeq+1 {
+		stored, err := s.repo.GetMessagesAfterSeq(ctx, streamID, afterSeq, MaxReplayMessages+1)
+		if err != nil {
+			return nil, err
+		}
+		messages = mergeBySeq(stored, messages)
+	}
+
+	replay := &entity.MessageReplay{Messages: messages}
+	if len(messages) > MaxReplayMessages {
+		replay.Truncated = true
+		replay.Messages = messages[len(messages)-MaxReplayMessages:]
+	}
+
+	replay.LastSeq, err = s.cache.LastSeq(ctx, streamID)
+	if err != nil {
+		log.Warn("Failed to read room sequence", "stream_id", streamID, "error", err)
+	}
+	if n := len(replay.Messages); n > 0 && replay.Messages[n-1].Seq > replay.LastSeq {
+		replay.LastSeq = replay.Messages[n-1].Seq
+	}
+	return replay, nil
+}
+
+// forgetBuffered убирает из буфера повтора сообщения, удаленные модератором
+func (s *ChatService) forgetBuffered(ctx context.Context, streamID uuid.UUID, match func(*entity.ChatMessage) bool) {
+	if err := s.cache.RemoveBufferedMessages(ctx, streamID, match); err != nil {
+		log.Warn("Failed to remove deleted messages from replay buffer", "stream_id", streamID, "error", err)
+	}
+}
+
+// mergeBySeq объединяет сообщения из двух источников без дублей по возрастанию номера
+func mergeBySeq(stored, buffered []*entity.ChatMessage) []*entity.ChatMessage {
+	bySeq := make(map[int64]*entity.ChatMessage, len(stored)+len(buffered))
+	for _, msg := range stored {
+		bySeq[msg.Seq] = msg
+	}
+	for _, msg := range buffered {
+		bySeq[msg.Seq] = msg
+	}
+
+	merged := make([]*entity.ChatMessage, 0, len(bySeq))
+	for _, msg := range bySeq {
+		merged = append(merged, msg)
+	}
+	slices.SortFunc(merged, func(a, b *entity.ChatMessage) int { return cmp.Compare(a.Seq, b.Seq) })
+	return merged
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/protocol"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+var ErrReplyNotFound = errors.New("message to reply to not found")
+
+// AnnotateMessage дополняет новое сообщение контекстом ответа на сообщение
+// replyTo (uuid.Nil — не ответ) и упоминаниями @username, найденными в тексте.
+// Упоминания неизвестных пользователей остаются в тексте, но не сохраняются
+func (s *ChatService) AnnotateMessage(ctx context.Context, msg *entity.ChatMessage, replyTo uuid.UUID) error {
+	if replyTo != uuid.Nil {
+		parent, err := s.findMessage(ctx, msg.StreamID, replyTo)
+		if errors.Is(err, repository.ErrMessageNotFound) {
+			return ErrReplyNotFound
+		}
+		if err != nil {
+			return err
+		}
+		msg.ReplyTo = entity.NewReplyContext(parent)
+	}
+
+	names := entity.ParseMentions(msg.Content)
+	if len(names) == 0 {
+		return nil
+	}
+	// Упоминания не должны мешать отправке сообщения
+	found, err := s.lookupChatters(ctx, msg.StreamID, names)
+	if err != nil {
+		log.Warn("Failed to resolve mentions", "stream_id", msg.StreamID, "error", err)
+		return nil
+	}
+	for _, name := range names {
+		if mention, ok := found[strings.ToLower(name)]; ok {
+			msg.Mentions = append(msg.Mentions, mention)
+		}
+	}
+	return nil
+}
+
+// NotifyMentions отправляет упомянутым пользователям и автору сообщения,
+// на которое ответили, личное уведомление. Отправитель уведомлений о себе не получает
+func (s *ChatService) NotifyMentions(ctx context.Context, msg *entity.ChatMessage) {
+	notified := map[uuid.UUID]struct{}{msg.UserID: {}}
+	notify := func(userID uuid.UUID, reply bool) {
+		if _, ok := notified[userID]; ok {
+			return
+		}
+		notified[userID] = struct{}{}
+
+		// Заблокировавший автора или заблокированный им пользователь уведомлений не получает
+		blocked, err := s.repo.HasBlock(ctx, msg.UserID, userID)
+		if err != nil {
+			log.Error("Failed to check block", "message_id", msg.ID, "user_id", userID, "error", err)
+			return
+		}
+		if blocked {
+			return
+		}
+
+		frame, err := protocol.Encode(protocol.TypeMention, "", entity.NewMentionEvent(msg, reply))
+		if err != nil {
+			return
+		}
+		if err := s.publisher.PublishToUser(ctx, userID, frame); err != nil {
+			log.Error("Failed to deliver mention", "message_id", msg.ID, "user_id", userID, "error", err)
+		}
+	}
+
+	if msg.ReplyTo != nil {
+		notify(msg.ReplyTo.UserID, true)
+	}
+	for _, mention := range msg.Mentions {
+		notify(mention.UserID, false)
+	}
+}
+
+// findMessage ищет сообщение чата стрима сначала в буфере повтора, куда оно
+// попадает сразу после отправки, а затем в MongoDB. Удаленные сообщения
+// и сообщения другой комнаты не находятся: возвращается repository.ErrMessageNotFound
+func (s *ChatService) findMessage(ctx context.Context, streamID, messageID uuid.UUID) (*entity.ChatMessage, error) {
+	recent, err := s.cache.BufferedMessagesAfter(ctx, streamID, 0, MaxReplayMessages)
+	if err != nil {
+		log.Warn("Replay buffer unavailable, falling back to MongoDB", "error", err)
+	}
+	for _, msg := range recent {
+		if msg.ID == messageID {
+			return msg, nil
+		}
+	}
+
+	msg, err := s.repo.GetMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.StreamID != streamID || msg.IsDeleted {
+		return nil, repository.ErrMessageNotFound
+	}
+	return msg, nil
+}
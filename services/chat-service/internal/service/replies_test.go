@@ -0,0 +1,129 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/cache"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/events"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/protocol"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// replyStore хранит сообщения и блокировки между пользователями
+type replyStore struct {
+	*pinStore
+	blocks map[[2]uuid.UUID]bool
+}
+
+func (r *replyStore) HasBlock(_ context.Context, a, b uuid.UUID) (bool, error) {
+	return r.blocks[[2]uuid.UUID{a, b}] || r.blocks[[2]uuid.UUID{b, a}], nil
+}
+
+// bufferMessage публикует сообщение в комнату, как это делает SendMessage,
+// чтобы оно попало в буфер повтора
+func bufferMessage(t *testing.T, redisCache *cache.RedisCache, msg *entity.ChatMessage) {
+	t.Helper()
+	event, err := json.Marshal(events.NewMessageEvent(msg))
+	require.NoError(t, err)
+	msg.Seq, err = redisCache.PublishMessage(context.Background(), msg, 0, events.RoomChannel(msg.StreamID), event)
+	require.NoError(t, err)
+}
+
+func TestRepliesAndMentions(t *testing.T) {
+	ctx := context.Background()
+	streamID := uuid.New()
+	alice := entity.Chatter{UserID: uuid.New(), Username: "Alice", Role: entity.RoleViewer}
+	bob := entity.Chatter{UserID: uuid.New(), Username: "Bob", Role: entity.RoleViewer}
+	carol := uuid.New()
+
+	// Старое сообщение есть только в MongoDB, свежее — еще и в буфере повтора
+	archived := entity.NewChatMessage(streamID, bob.UserID, "Bob", "first!")
+	deleted := entity.NewChatMessage(streamID, bob.UserID, "Bob", "oops")
+	deleted.IsDeleted = true
+	foreign := entity.NewChatMessage(uuid.New(), bob.UserID, "Bob", "elsewhere")
+	recent := entity.NewChatMessage(streamID, carol, "Carol", "hello everyone")
+
+	repo := &replyStore{
+		pinStore: &pinStore{
+			roleStore: &roleStore{roles: make(map[uuid.UUID]*entity.ChatRole)},
+			messages:  map[uuid.UUID]*entity.ChatMessage{archived.ID: archived, deleted.ID: deleted, foreign.ID: foreign},
+		},
+		blocks: make(map[[2]uuid.UUID]bool),
+	}
+	svc, client := newTestService(t, repo, ownerDirectory(uuid.New()))
+	redisCache := cache.NewRedisCache(client)
+	bufferMessage(t, redisCache, recent)
+	require.NoError(t, svc.TouchPresence(ctx, streamID, []entity.Chatter{alice, bob}))
+
+	inbox := client.Subscribe(ctx, events.UserChannel(bob.UserID), events.UserChannel(carol), events.UserChannel(alice.UserID))
+	t.Cleanup(func() { inbox.Close() })
+	for range 3 {
+		_, err := inbox.Receive(ctx)
+		require.NoError(t, err)
+	}
+
+	// Отвечать можно только на неудаленные сообщения своей комнаты
+	for _, parent := range []uuid.UUID{uuid.New(), deleted.ID, foreign.ID} {
+		msg := entity.NewChatMessage(streamID, alice.UserID, "Alice", "what?")
+		assert.ErrorIs(t, svc.AnnotateMessage(ctx, msg, parent), service.ErrReplyNotFound)
+	}
+
+	reply := entity.NewChatMessage(streamID, alice.UserID, "Alice", "@bob @BOB @nobody @alice hi")
+	require.NoError(t, svc.AnnotateMessage(ctx, reply, archived.ID))
+	require.NotNil(t, reply.ReplyTo)
+	assert.Equal(t, archived.ID, reply.ReplyTo.ThreadID)
+	assert.Equal(t, "first!", reply.ReplyTo.Content)
+	// Имена приводятся к написанию пользователя, неизвестные пропускаются
+	assert.Equal(t, []entity.Mention{{UserID: bob.UserID, Username: "Bob"}, {UserID: alice.UserID, Username: "Alice"}}, reply.Mentions)
+
+	// Ответ на ответ остается в той же ветке
+	nested := entity.NewChatMessage(streamID, alice.UserID, "Alice", "also @carol")
+	nested.ReplyTo = reply.ReplyTo
+	bufferMessage(t, redisCache, nested)
+	second := entity.NewChatMessage(streamID, bob.UserID, "Bob", "sure")
+	require.NoError(t, svc.AnnotateMessage(ctx, second, nested.ID))
+	assert.Equal(t, archived.ID, second.ReplyTo.ThreadID)
+	assert.Equal(t, alice.UserID, second.ReplyTo.UserID)
+
+	// Автор недавнего сообщения находится, даже если уже вышел из чата
+	require.NoError(t, svc.AnnotateMessage(ctx, nested, uuid.Nil))
+	assert.Equal(t, []entity.Mention{{UserID: carol, Username: "Carol"}}, nested.Mentions)
+
+	// Упомянутый автор исходного сообщения получает одно уведомление, отправитель — ни одного
+	svc.NotifyMentions(ctx, reply)
+	msg, err := inbox.ReceiveMessage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, events.UserChannel(bob.UserID), msg.Channel)
+	env, err := protocol.Decode([]byte(msg.Payload))
+	require.NoError(t, err)
+	require.Equal(t, protocol.TypeMention, env.Type)
+	var mention entity.MentionEvent
+	require.NoError(t, json.Unmarshal(env.Payload, &mention))
+	assert.Equal(t, reply.ID, mention.MessageID)
+	assert.True(t, mention.Reply)
+
+	svc.NotifyMentions(ctx, nested)
+	msg, err = inbox.ReceiveMessage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, events.UserChannel(bob.UserID), msg.Channel)
+	msg, err = inbox.ReceiveMessage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, events.UserChannel(carol), msg.Channel)
+
+	// Пользователь, заблокировавший автора, не получает уведомлений о его упоминаниях
+	repo.blocks[[2]uuid.UUID{carol, alice.UserID}] = true
+	svc.NotifyMentions(ctx, nested)
+	msg, err = inbox.ReceiveMessage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, events.UserChannel(bob.UserID), msg.Channel)
+	svc.NotifyMentions(ctx, second)
+	msg, err = inbox.ReceiveMessage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, events.UserChannel(alice.UserID), msg.Channel)
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrForbidden        = errors.New("not enough permissions in this chat")
+	ErrRoleNotGrantable = errors.New("only moderator and vip roles can be granted")
+	ErrBroadcasterRole  = errors.New("broadcaster role is defined by stream ownership")
+	ErrTargetOutranks   = errors.New("target user has an equal or higher role")
+)
+
+// StreamDirectory предоставляет сведения о стримах, в том числе их владельцев
+type StreamDirectory interface {
+	GetStream(ctx context.Context, streamID uuid.UUID) (*entity.StreamInfo, error)
+}
+
+// GetRole возвращает роль пользователя в чате стрима.
+// Бродкастер определяется владельцем стрима, модераторы и VIP — выданными ролями
+func (s *ChatService) GetRole(ctx context.Context, streamID, userID uuid.UUID) (entity.Role, error) {
+	role, err := s.cache.GetRole(ctx, streamID, userID)
+	if err != nil {
+		log.Warn("Role cache unavailable", "error", err)
+	}
+	if role != "" {
+		return role, nil
+	}
+
+	stream, err := s.streams.GetStream(ctx, streamID)
+	if err != nil {
+		return entity.RoleViewer, err
+	}
+
+	role = entity.RoleViewer
+	if stream.OwnerID == userID {
+		role = entity.RoleBroadcaster
+	} else {
+		granted, err := s.repo.GetRole(ctx, streamID, userID)
+		if err != nil {
+			return entity.RoleViewer, err
+		}
+		if granted != nil {
+			role = granted.Role
+		}
+	}
+
+	if err := s.cache.SetRole(ctx, streamID, userID, role); err != nil {
+		log.Warn("Failed to cache role", "stream_id", streamID, "user_id", userID, "error", err)
+	}
+	return role, nil
+}
+
+// GrantRole выдает пользователю роль модератора или VIP. Доступно только бродкастеру
+func (s *ChatService) GrantRole(ctx context.Context, streamID, userID, actorID uuid.UUID, role entity.Role) (*entity.ChatRole, error) {
+	if !role.IsGrantable() {
+		return nil, ErrRoleNotGrantable
+	}
+	if err := s.requireRole(ctx, streamID, actorID, entity.RoleBroadcaster); err != nil {
+		return nil, err
+	}
+	if userID == actorID {
+		return nil, ErrBroadcasterRole
+	}
+
+	granted := &entity.ChatRole{
+		StreamID:  streamID,
+		UserID:    userID,
+		Role:      role,
+		GrantedBy: actorID,
+		GrantedAt: time.Now().UTC(),
+	}
+	if err := s.repo.SetRole(ctx, granted); err != nil {
+		return nil, err
+	}
+	s.invalidateRole(ctx, streamID, userID)
+	s.updatePresenceRole(ctx, streamID, userID, role)
+	return granted, nil
+}
+
+// RevokeRole снимает с пользователя роль модератора или VIP. Доступно только бродкастеру
+func (s *ChatService) RevokeRole(ctx context.Context, streamID, userID, actorID uuid.UUID) error {
+	if err := s.requireRole(ctx, streamID, actorID, entity.RoleBroadcaster); err != nil {
+		return err
+	}
+	if err := s.repo.RemoveRole(ctx, streamID, userID); err != nil {
+		return err
+	}
+	s.invalidateRole(ctx, streamID, userID)
+	s.updatePresenceRole(ctx, streamID, userID, entity.RoleViewer)
+	return nil
+}
+
+// ListRoles возвращает модераторов и VIP чата стрима
+func (s *ChatService) ListRoles(ctx context.Context, streamID uuid.UUID) ([]*entity.ChatRole, error) {
+	return s.repo.ListRoles(ctx, streamID)
+}
+
+// requireRole возвращает ErrForbidden, если роль пользователя ниже требуемой
+func (s *ChatService) requireRole(ctx context.Context, streamID, userID uuid.UUID, required entity.Role) error {
+	role, err := s.GetRole(ctx, streamID, userID)
+	if err != nil {
+		return err
+	}
+	if required.Outranks(role) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// requireModerator проверяет, что пользователь может модерировать чат стрима
+// и, если задан target, что роль цели ниже роли модератора. Администраторам
+// платформы модерация доступна в любом чате
+func (s *ChatService) requireModerator(ctx context.Context, streamID, actorID, targetID uuid.UUID) error {
+	if s.IsAdmin(actorID) {
+		return nil
+	}
+	actorRole, err := s.GetRole(ctx, streamID, actorID)
+	if err != nil {
+		return err
+	}
+	if !actorRole.CanModerate() {
+		return ErrForbidden
+	}
+	if targetID == uuid.Nil {
+		return nil
+	}
+
+	targetRole, err := s.GetRole(ctx, streamID, targetID)
+	if err != nil {
+		return err
+	}
+	if !actorRole.Outranks(targetRole) {
+		return ErrTargetOutranks
+	}
+	return nil
+}
+
+// invalidateRole сбрасывает закешированную роль после ее изменения
+func (s *ChatService) invalidateRole(ctx context.Context, streamID, userID uuid.UUID) {
+	if err := s.cache.InvalidateRole(ctx, streamID, userID); err != nil {
+		log.Error("Failed to invalidate role cache", "stream_id", streamID, "user_id", userID, "error", err)
+	}
+}
@@ -0,0 +1,106 @@
+package service_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/cache"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/events"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roleStore хранит выданные роли и баны в памяти
+type roleStore struct {
+	repository.ChatRepository
+	mu    sync.Mutex
+	roles map[uuid.UUID]*entity.ChatRole
+}
+
+func (r *roleStore) SetRole(_ context.Context, role *entity.ChatRole) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.roles[role.UserID] = role
+	return nil
+}
+
+func (r *roleStore) GetRole(_ context.Context, _, userID uuid.UUID) (*entity.ChatRole, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.roles[userID], nil
+}
+
+func (r *roleStore) BanUser(context.Context, *entity.ChatBan) error { return nil }
+
+func (r *roleStore) ListEmotes(context.Context, uuid.UUID) ([]*entity.Emote, error) { return nil, nil }
+
+// ownerDirectory сообщает одного и того же владельца для любого стрима
+type ownerDirectory uuid.UUID
+
+func (d ownerDirectory) GetStream(_ context.Context, streamID uuid.UUID) (*entity.StreamInfo, error) {
+	return &entity.StreamInfo{ID: streamID, OwnerID: uuid.UUID(d)}, nil
+}
+
+// newTestService создает сервис поверх хранилища repo, справочника стримов
+// streams и отдельного miniredis. Клиент Redis нужен тестам для подписки
+// на события комнат и пользователей
+func newTestService(t *testing.T, repo repository.ChatRepository, streams service.StreamDirectory) (*service.ChatService, *redis.Client) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return service.NewChatService(repo, cache.NewRedisCache(client), events.NewPublisher(client), streams), client
+}
+
+func newRoleService(t *testing.T, owner uuid.UUID) *service.ChatService {
+	t.Helper()
+
+	svc, _ := newTestService(t, &roleStore{roles: make(map[uuid.UUID]*entity.ChatRole)}, ownerDirectory(owner))
+	return svc
+}
+
+func TestRolesAndModerationPermissions(t *testing.T) {
+	ctx := context.Background()
+	streamID, owner, mod, viewer := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	svc := newRoleService(t, owner)
+
+	role, err := svc.GetRole(ctx, streamID, owner)
+	require.NoError(t, err)
+	assert.Equal(t, entity.RoleBroadcaster, role)
+
+	// Модераторов назначает только бродкастер
+	_, err = svc.GrantRole(ctx, streamID, mod, viewer, entity.RoleModerator)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	_, err = svc.GrantRole(ctx, streamID, mod, owner, entity.RoleBroadcaster)
+	assert.ErrorIs(t, err, service.ErrRoleNotGrantable)
+	_, err = svc.GrantRole(ctx, streamID, mod, owner, entity.RoleModerator)
+	require.NoError(t, err)
+
+	role, err = svc.GetRole(ctx, streamID, mod)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"moderator"}, role.Badges())
+
+	// Зритель не модерирует, модератор не может заблокировать бродкастера
+	_, err = svc.BanUser(ctx, streamID, mod, viewer, time.Minute, "")
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	_, err = svc.BanUser(ctx, streamID, owner, mod, time.Minute, "")
+	assert.ErrorIs(t, err, service.ErrTargetOutranks)
+	_, err = svc.BanUser(ctx, streamID, viewer, mod, time.Minute, "spam")
+	assert.NoError(t, err)
+
+	// Администратор платформы модерирует любой чат без роли в нем
+	admin := uuid.New()
+	svc.SetAdmins([]uuid.UUID{admin})
+	_, err = svc.BanUser(ctx, streamID, mod, admin, time.Minute, "")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"unicode/utf8"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultSearchLimit  = 50  // Размер страницы поиска по умолчанию
+	MaxSearchLimit      = 100 // Максимальный размер страницы поиска
+	MaxSearchTextLength = 200 // Максимальная длина поискового запроса в символах
+)
+
+var (
+	ErrInvalidSearchRange = errors.New("search since must be before until")
+	ErrSearchTextTooLong  = errors.New("search text is too long")
+)
+
+// SearchMessages ищет сообщения по тексту и фильтрам. Искать в чате стрима могут
+// его модераторы, по всем чатам сразу — только администраторы платформы.
+// В результатах раскрываются сведения об удалении сообщений
+func (s *ChatService) SearchMessages(ctx context.Context, actorID uuid.UUID, search entity.MessageSearch) (*entity.MessageSearchPage, error) {
+	if !s.IsAdmin(actorID) {
+		if search.StreamID == uuid.Nil {
+			return nil, ErrForbidden
+		}
+		if err := s.requireModerator(ctx, search.StreamID, actorID, uuid.Nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if utf8.RuneCountInString(search.Text) > MaxSearchTextLength {
+		return nil, ErrSearchTextTooLong
+	}
+	if !search.Since.IsZero() && !search.Until.IsZero() && !search.Since.Before(search.Until) {
+		return nil, ErrInvalidSearchRange
+	}
+	if search.Limit <= 0 {
+		search.Limit = DefaultSearchLimit
+	}
+	if search.Limit > MaxSearchLimit {
+		search.Limit = MaxSearchLimit
+	}
+	return s.repo.SearchMessages(ctx, search)
+}
@@ -0,0 +1,59 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// searchStore запоминает последний поисковый запрос
+type searchStore struct {
+	*roleStore
+	search *entity.MessageSearch
+}
+
+func (s *searchStore) SearchMessages(_ context.Context, search entity.MessageSearch) (*entity.MessageSearchPage, error) {
+	s.search = &search
+	return &entity.MessageSearchPage{}, nil
+}
+
+func TestSearchMessagesPermissions(t *testing.T) {
+	ctx := context.Background()
+	streamID, owner, mod, viewer, admin := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	repo := &searchStore{roleStore: &roleStore{roles: make(map[uuid.UUID]*entity.ChatRole)}}
+	svc, _ := newTestService(t, repo, ownerDirectory(owner))
+	svc.SetAdmins([]uuid.UUID{admin})
+
+	_, err := svc.GrantRole(ctx, streamID, mod, owner, entity.RoleModerator)
+	require.NoError(t, err)
+
+	// Зрители не ищут ни в чате, ни по всем чатам
+	_, err = svc.SearchMessages(ctx, viewer, entity.MessageSearch{StreamID: streamID, Text: "spam"})
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	assert.Nil(t, repo.search)
+
+	// Модератор ищет только в своем чате
+	_, err = svc.SearchMessages(ctx, mod, entity.MessageSearch{Text: "spam"})
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	_, err = svc.SearchMessages(ctx, mod, entity.MessageSearch{StreamID: streamID, Text: "spam", Limit: 1000})
+	require.NoError(t, err)
+	require.NotNil(t, repo.search)
+	assert.Equal(t, service.MaxSearchLimit, repo.search.Limit)
+
+	// Администратор платформы ищет по всем чатам
+	_, err = svc.SearchMessages(ctx, admin, entity.MessageSearch{UserID: viewer})
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, repo.search.StreamID)
+	assert.Equal(t, service.DefaultSearchLimit, repo.search.Limit)
+
+	now := time.Now()
+	_, err = svc.SearchMessages(ctx, admin, entity.MessageSearch{Since: now, Until: now.Add(-time.Hour)})
+	assert.ErrorIs(t, err, service.ErrInvalidSearchRange)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/google/uuid"
+)
+
+const (
+	MessageRateLimit  = 10          // Сообщений чата на пользователя за MessageRateWindow
+	MessageRateWindow = time.Minute // Окно лимита сообщений чата
+)
+
+var (
+	ErrInvalidMessage = errors.New("invalid message format")
+	ErrRateLimited    = errors.New("too many messages, slow down")
+)
+
+// PrepareMessage проверяет сообщение пользователя msg.UserID перед отправкой
+// в чат и дополняет его значками, ответом на replyTo (uuid.Nil — не ответ)
+// и упоминаниями. Одинаково применяется к сообщениям из WebSocket и gRPC:
+// бан, лимит сообщений, режимы чата и AutoMod. Принятое сообщение остается
+// сохранить через QueueMessage или SendMessage
+func (s *ChatService) PrepareMessage(ctx context.Context, msg *entity.ChatMessage, replyTo uuid.UUID) error {
+	// Служебные поля заполняются только сервером
+	msg.ID = uuid.New()
+	msg.Timestamp = time.Now().UTC()
+	msg.IsDeleted = false
+
+	if !msg.Validate() || msg.UserID == uuid.Nil {
+		return ErrInvalidMessage
+	}
+
+	// Бан или таймаут мог быть выдан уже после подключения
+	if err := s.CheckBan(ctx, msg.StreamID, msg.UserID); err != nil {
+		return err
+	}
+
+	count, err := s.cache.TouchMessageRate(ctx, msg.UserID, MessageRateWindow)
+	if err != nil {
+		return err
+	}
+	if count > MessageRateLimit {
+		return ErrRateLimited
+	}
+
+	// Значки ролей не критичны: при ошибке сообщение уходит без них,
+	// а режимы чата применяются как к обычному зрителю
+	role, err := s.GetRole(ctx, msg.StreamID, msg.UserID)
+	if err != nil {
+		log.Warn("Failed to resolve chat role", "user_id", msg.UserID, "stream_id", msg.StreamID, "error", err)
+	}
+	msg.Badges = role.Badges()
+
+	// Slow mode, followers-only, emote-only и subscribers-only
+	if err := s.CheckChatModes(ctx, msg, role); err != nil {
+		return err
+	}
+
+	// Ответ и упоминания заполняются до AutoMod: задержанное сообщение
+	// после одобрения модератором уходит в чат уже с ними
+	if err := s.AnnotateMessage(ctx, msg, replyTo); err != nil {
+		return err
+	}
+
+	// Фильтры AutoMod могут замаскировать текст или задержать сообщение для модераторов
+	if err := s.ModerateMessage(ctx, msg, role); err != nil {
+		return err
+	}
+
+	// Интервал slow mode занимает только принятое сообщение
+	return s.touchSlowMode(ctx, msg, role)
+}
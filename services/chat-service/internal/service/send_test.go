@@ -0,0 +1,79 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sendStore хранит запрещенные выражения AutoMod в памяти
+type sendStore struct {
+	*emoteStore
+	terms []*entity.BlockedTerm
+}
+
+func (s *sendStore) GetAutoModSettings(context.Context, uuid.UUID) (*entity.AutoModSettings, error) {
+	return nil, nil
+}
+
+func (s *sendStore) ListBlockedTerms(_ context.Context, streamID uuid.UUID) ([]*entity.BlockedTerm, error) {
+	var result []*entity.BlockedTerm
+	for _, term := range s.terms {
+		if term.StreamID == streamID {
+			result = append(result, term)
+		}
+	}
+	return result, nil
+}
+
+func TestPrepareMessage(t *testing.T) {
+	ctx := context.Background()
+	streamID, owner, viewer, spammer := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	repo := &sendStore{
+		emoteStore: &emoteStore{pinStore: &pinStore{
+			roleStore: &roleStore{roles: make(map[uuid.UUID]*entity.ChatRole)},
+			messages:  make(map[uuid.UUID]*entity.ChatMessage),
+			pins:      make(map[uuid.UUID]*entity.PinnedMessage),
+		}},
+		terms: []*entity.BlockedTerm{{ID: uuid.New(), StreamID: streamID, Pattern: "scam", Action: entity.AutoModReject}},
+	}
+	svc, _ := newTestService(t, repo, ownerDirectory(owner))
+
+	prepare := func(userID uuid.UUID, content string) error {
+		return svc.PrepareMessage(ctx, entity.NewChatMessage(streamID, userID, "user", content), uuid.Nil)
+	}
+
+	// Принятое сообщение получает значки роли отправителя
+	msg := entity.NewChatMessage(streamID, owner, "owner", "welcome")
+	require.NoError(t, svc.PrepareMessage(ctx, msg, uuid.Nil))
+	assert.Equal(t, entity.RoleBroadcaster.Badges(), msg.Badges)
+
+	assert.ErrorIs(t, prepare(viewer, ""), service.ErrInvalidMessage)
+	assert.ErrorIs(t, prepare(uuid.Nil, "hello"), service.ErrInvalidMessage)
+	assert.ErrorIs(t, prepare(viewer, "free scam here"), service.ErrMessageRejected)
+
+	// Бан или таймаут, выданный после подключения, действует сразу
+	_, err := svc.BanUser(ctx, streamID, viewer, owner, time.Minute, "")
+	require.NoError(t, err)
+	assert.ErrorIs(t, prepare(viewer, "hello"), service.ErrTimedOut)
+
+	for i := range service.MessageRateLimit {
+		require.NoError(t, prepare(spammer, fmt.Sprintf("hello %d", i)))
+	}
+	assert.ErrorIs(t, prepare(spammer, "one more"), service.ErrRateLimited)
+
+	// Интервал slow mode занимает только сообщение, пропущенное AutoMod
+	require.NoError(t, svc.SetChatModes(ctx, streamID, owner, entity.ChatModes{SlowModeSeconds: 30}))
+	slow := uuid.New()
+	assert.ErrorIs(t, prepare(slow, "free scam here"), service.ErrMessageRejected)
+	require.NoError(t, prepare(slow, "hello"))
+	assert.ErrorIs(t, prepare(slow, "hello again"), service.ErrSlowMode)
+}
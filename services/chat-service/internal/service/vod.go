@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/google/uuid"
+)
+
+// MaxVODWindow — максимальная длина отрезка записи в одном запросе
+const MaxVODWindow = 10 * time.Minute
+
+var (
+	// ErrStreamNotStarted возвращается, если у стрима нет времени начала трансляции
+	ErrStreamNotStarted = errors.New("stream has not been started")
+	// ErrInvalidVODWindow возвращается для пустого, отрицательного или слишком длинного отрезка
+	ErrInvalidVODWindow = fmt.Errorf("playback window must be non-empty and at most %s", MaxVODWindow)
+)
+
+// GetVODChat возвращает сообщения чата, отправленные в отрезке [from, to)
+// от начала трансляции, с отступами для синхронизации с записью.
+// Следующая страница того же отрезка запрашивается с after — ID последнего
+// полученного сообщения. Удаленные сообщения не возвращаются
+func (s *ChatService) GetVODChat(ctx context.Context, streamID uuid.UUID, from, to time.Duration, after uuid.UUID, limit int) (*entity.VODChatPage, error) {
+	if from < 0 || to <= from || to-from > MaxVODWindow {
+		return nil, ErrInvalidVODWindow
+	}
+
+	stream, err := s.streams.GetStream(ctx, streamID)
+	if err != nil {
+		return nil, err
+	}
+	if stream.StartTime.IsZero() {
+		return nil, ErrStreamNotStarted
+	}
+
+	// MongoDB хранит время с точностью до миллисекунды, а курсор по времени
+	// строгий, поэтому начало отрезка сдвигается на миллисекунду назад
+	query := entity.MessageQuery{
+		StreamID: streamID,
+		After:    &entity.MessageCursor{Time: stream.StartTime.Add(from - time.Millisecond)},
+		Before:   &entity.MessageCursor{Time: stream.StartTime.Add(to)},
+		Limit:    limit,
+	}
+	if after != uuid.Nil {
+		query.After = &entity.MessageCursor{MessageID: after}
+	}
+
+	page, err := s.GetMessages(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	vod := &entity.VODChatPage{
+		StreamID:  streamID,
+		StartTime: stream.StartTime,
+		Messages:  make([]entity.VODChatMessage, 0, len(page.Messages)),
+		HasMore:   page.HasMore,
+	}
+	for _, msg := range page.Messages {
+		vod.Messages = append(vod.Messages, entity.VODChatMessage{
+			OffsetMs:    msg.Timestamp.Sub(stream.StartTime).Milliseconds(),
+			ChatMessage: msg,
+		})
+	}
+	return vod, nil
+}
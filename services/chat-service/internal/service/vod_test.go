@@ -0,0 +1,64 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// historyStore отдает заданные сообщения и запоминает последний запрос истории
+type historyStore struct {
+	repository.ChatRepository
+	query    entity.MessageQuery
+	messages []*entity.ChatMessage
+}
+
+func (h *historyStore) GetMessages(_ context.Context, query entity.MessageQuery) (*entity.MessagePage, error) {
+	h.query = query
+	return &entity.MessagePage{Messages: h.messages}, nil
+}
+
+// startedDirectory сообщает стрим, начавшийся в заданное время
+type startedDirectory time.Time
+
+func (d startedDirectory) GetStream(_ context.Context, streamID uuid.UUID) (*entity.StreamInfo, error) {
+	return &entity.StreamInfo{ID: streamID, StartTime: time.Time(d)}, nil
+}
+
+func TestVODChatOffsets(t *testing.T) {
+	ctx := context.Background()
+	streamID := uuid.New()
+	start := time.Date(2025, 3, 1, 18, 0, 0, 0, time.UTC)
+
+	msg := entity.NewChatMessage(streamID, uuid.New(), "viewer", "first!")
+	msg.Timestamp = start.Add(90*time.Second + 250*time.Millisecond)
+	repo := &historyStore{messages: []*entity.ChatMessage{msg}}
+
+	svc, _ := newTestService(t, repo, startedDirectory(start))
+
+	page, err := svc.GetVODChat(ctx, streamID, time.Minute, 2*time.Minute, uuid.Nil, 0)
+	require.NoError(t, err)
+	require.Len(t, page.Messages, 1)
+	assert.Equal(t, int64(90250), page.Messages[0].OffsetMs)
+	assert.Equal(t, start, page.StartTime)
+
+	// Отрезок переводится во время относительно начала трансляции
+	assert.Equal(t, start.Add(time.Minute-time.Millisecond), repo.query.After.Time)
+	assert.Equal(t, start.Add(2*time.Minute), repo.query.Before.Time)
+
+	_, err = svc.GetVODChat(ctx, streamID, time.Minute, time.Minute, uuid.Nil, 0)
+	assert.ErrorIs(t, err, service.ErrInvalidVODWindow)
+	_, err = svc.GetVODChat(ctx, streamID, 0, time.Hour, uuid.Nil, 0)
+	assert.ErrorIs(t, err, service.ErrInvalidVODWindow)
+
+	notStarted, _ := newTestService(t, repo, startedDirectory(time.Time{}))
+	_, err = notStarted.GetVODChat(ctx, streamID, 0, time.Minute, uuid.Nil, 0)
+	assert.ErrorIs(t, err, service.ErrStreamNotStarted)
+}
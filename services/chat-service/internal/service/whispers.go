@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/protocol"
+	"github.com/google/uuid"
+)
+
+const (
+	MaxWhisperLength         = 500         // Максимальная длина личного сообщения в символах
+	WhisperRateLimit         = 20          // Личных сообщений на пользователя за WhisperRateWindow
+	WhisperRateWindow        = time.Minute // Окно лимита личных сообщений
+	DefaultWhisperLimit      = 50          // Размер страницы переписки по умолчанию
+	MaxWhisperLimit          = 100         // Максимальный размер страницы переписки
+	DefaultConversationLimit = 20          // Размер страницы входящих по умолчанию
+	MaxConversationLimit     = 100         // Максимальный размер страницы входящих
+)
+
+var (
+	ErrInvalidWhisper     = errors.New("whisper must be between 1 and 500 characters")
+	ErrWhisperSelf        = errors.New("cannot whisper yourself")
+	ErrWhisperBlocked     = errors.New("user does not accept whispers from you")
+	ErrWhisperRateLimited = errors.New("too many whispers, slow down")
+	ErrCannotBlockSelf    = errors.New("cannot block yourself")
+)
+
+// SendWhisper сохраняет личное сообщение и доставляет его на все подключения
+// получателя и отправителя. Сообщения между пользователями, один из которых
+// заблокировал другого, не принимаются
+func (s *ChatService) SendWhisper(ctx context.Context, fromUserID uuid.UUID, fromUsername string, toUserID uuid.UUID, content string) (*entity.Whisper, error) {
+	content = strings.TrimSpace(content)
+	if content == "" || utf8.RuneCountInString(content) > MaxWhisperLength || toUserID == uuid.Nil {
+		return nil, ErrInvalidWhisper
+	}
+	if toUserID == fromUserID {
+		return nil, ErrWhisperSelf
+	}
+
+	count, err := s.cache.TouchWhisperRate(ctx, fromUserID, WhisperRateWindow)
+	if err != nil {
+		return nil, err
+	}
+	if count > WhisperRateLimit {
+		return nil, ErrWhisperRateLimited
+	}
+
+	blocked, err := s.repo.HasBlock(ctx, fromUserID, toUserID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrWhisperBlocked
+	}
+
+	whisper := entity.NewWhisper(fromUserID, fromUsername, toUserID, content)
+	if err := s.repo.SaveWhisper(ctx, whisper); err != nil {
+		return nil, err
+	}
+
+	// Сообщение уже сохранено: при сбое доставки получатель увидит его во входящих
+	frame, err := protocol.Encode(protocol.TypeWhisper, "", whisper)
+	if err != nil {
+		return whisper, nil
+	}
+	for _, userID := range []uuid.UUID{toUserID, fromUserID} {
+		if err := s.publisher.PublishToUser(ctx, userID, frame); err != nil {
+			log.Error("Failed to deliver whisper", "whisper_id", whisper.ID, "user_id", userID, "error", err)
+		}
+	}
+	return whisper, nil
+}
+
+// ListConversations возвращает переписки пользователя, начиная с самых свежих
+func (s *ChatService) ListConversations(ctx context.Context, userID uuid.UUID, before time.Time, limit int) (*entity.ConversationPage, error) {
+	if limit <= 0 {
+		limit = DefaultConversationLimit
+	}
+	if limit > MaxConversationLimit {
+		limit = MaxConversationLimit
+	}
+	return s.repo.ListConversations(ctx, userID, before, limit)
+}
+
+// GetWhispers возвращает страницу переписки пользователя с собеседником otherID
+func (s *ChatService) GetWhispers(ctx context.Context, userID, otherID uuid.UUID, before *entity.MessageCursor, limit int) (*entity.WhisperPage, error) {
+	if limit <= 0 {
+		limit = DefaultWhisperLimit
+	}
+	if limit > MaxWhisperLimit {
+		limit = MaxWhisperLimit
+	}
+	return s.repo.GetWhispers(ctx, entity.ConversationID(userID, otherID), before, limit)
+}
+
+// BlockUser запрещает личные сообщения между пользователем и blockedID
+func (s *ChatService) BlockUser(ctx context.Context, userID, blockedID uuid.UUID) (*entity.UserBlock, error) {
+	if userID == blockedID {
+		return nil, ErrCannotBlockSelf
+	}
+	block := &entity.UserBlock{BlockerID: userID, BlockedID: blockedID, CreatedAt: time.Now().UTC()}
+	if err := s.repo.BlockUser(ctx, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// UnblockUser снимает блокировку личных сообщений
+func (s *ChatService) UnblockUser(ctx context.Context, userID, blockedID uuid.UUID) error {
+	return s.repo.UnblockUser(ctx, userID, blockedID)
+}
+
+// ListBlocks возвращает пользователей, заблокированных пользователем
+func (s *ChatService) ListBlocks(ctx context.Context, userID uuid.UUID) ([]*entity.UserBlock, error) {
+	return s.repo.ListBlocks(ctx, userID)
+}
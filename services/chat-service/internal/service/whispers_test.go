@@ -0,0 +1,103 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/events"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/protocol"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// whisperStore хранит личные сообщения и блокировки в памяти
+type whisperStore struct {
+	repository.ChatRepository
+	mu       sync.Mutex
+	whispers []entity.Whisper
+	blocks   map[[2]uuid.UUID]bool
+}
+
+func (w *whisperStore) SaveWhisper(_ context.Context, whisper *entity.Whisper) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.whispers = append(w.whispers, *whisper)
+	return nil
+}
+
+func (w *whisperStore) BlockUser(_ context.Context, block *entity.UserBlock) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.blocks[[2]uuid.UUID{block.BlockerID, block.BlockedID}] = true
+	return nil
+}
+
+func (w *whisperStore) HasBlock(_ context.Context, a, b uuid.UUID) (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.blocks[[2]uuid.UUID{a, b}] || w.blocks[[2]uuid.UUID{b, a}], nil
+}
+
+func TestSendWhisper(t *testing.T) {
+	ctx := context.Background()
+	alice, bob, carol := uuid.New(), uuid.New(), uuid.New()
+
+	repo := &whisperStore{blocks: make(map[[2]uuid.UUID]bool)}
+	svc, client := newTestService(t, repo, nil)
+
+	inbox := client.Subscribe(ctx, events.UserChannel(bob), events.UserChannel(alice))
+	t.Cleanup(func() { inbox.Close() })
+	_, err := inbox.Receive(ctx)
+	require.NoError(t, err)
+	_, err = inbox.Receive(ctx)
+	require.NoError(t, err)
+
+	_, err = svc.SendWhisper(ctx, alice, "alice", alice, "hi me")
+	assert.ErrorIs(t, err, service.ErrWhisperSelf)
+	_, err = svc.SendWhisper(ctx, alice, "alice", bob, "   ")
+	assert.ErrorIs(t, err, service.ErrInvalidWhisper)
+
+	// Сообщение сохраняется и приходит в каналы получателя и отправителя
+	whisper, err := svc.SendWhisper(ctx, alice, "alice", bob, " hey ")
+	require.NoError(t, err)
+	assert.Equal(t, "hey", whisper.Content)
+	assert.Equal(t, entity.ConversationID(alice, bob), whisper.ConversationID)
+
+	delivered := make(map[string]bool)
+	for range 2 {
+		msg, err := inbox.ReceiveMessage(ctx)
+		require.NoError(t, err)
+		env, err := protocol.Decode([]byte(msg.Payload))
+		require.NoError(t, err)
+		require.Equal(t, protocol.TypeWhisper, env.Type)
+		var got entity.Whisper
+		require.NoError(t, json.Unmarshal(env.Payload, &got))
+		assert.Equal(t, whisper.ID, got.ID)
+		delivered[msg.Channel] = true
+	}
+	assert.True(t, delivered[events.UserChannel(bob)])
+	assert.True(t, delivered[events.UserChannel(alice)])
+
+	// Блокировка действует в обе стороны
+	_, err = svc.BlockUser(ctx, bob, alice)
+	require.NoError(t, err)
+	_, err = svc.SendWhisper(ctx, alice, "alice", bob, "are you there?")
+	assert.ErrorIs(t, err, service.ErrWhisperBlocked)
+	_, err = svc.SendWhisper(ctx, bob, "bob", alice, "no")
+	assert.ErrorIs(t, err, service.ErrWhisperBlocked)
+
+	// Лимит считается на отправителя, а не на переписку, и учитывает отклоненные попытки
+	for range service.WhisperRateLimit - 2 {
+		_, err = svc.SendWhisper(ctx, alice, "alice", carol, "spam")
+		require.NoError(t, err)
+	}
+	_, err = svc.SendWhisper(ctx, alice, "alice", carol, "one more")
+	assert.ErrorIs(t, err, service.ErrWhisperRateLimited)
+	assert.Len(t, repo.whispers, service.WhisperRateLimit-1)
+}
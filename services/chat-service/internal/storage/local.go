@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage хранит файлы в каталоге на локальном диске
+type LocalStorage struct {
+	root    string
+	baseURL string
+}
+
+// NewLocalStorage создает хранилище в каталоге root. Ссылки на файлы
+// строятся от baseURL, по которому раздается Handler
+func NewLocalStorage(root, baseURL string) (*LocalStorage, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{root: root, baseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+// path возвращает путь файла на диске по ключу
+func (s *LocalStorage) path(key string) (string, error) {
+	name := filepath.FromSlash(key)
+	if !filepath.IsLocal(name) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.root, name), nil
+}
+
+// Save записывает файл через временный файл, чтобы клиенты
+// не получили его частично записанным
+func (s *LocalStorage) Save(_ context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Delete удаляет файл и опустевший каталог над ним
+func (s *LocalStorage) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	// Каталог с другими файлами не удаляется
+	if dir := filepath.Dir(path); dir != filepath.Clean(s.root) {
+		_ = os.Remove(dir)
+	}
+	return nil
+}
+
+// URL возвращает ссылку на файл
+func (s *LocalStorage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// Handler раздает файлы хранилища без списков содержимого каталогов.
+// Файл по ключу не меняется, поэтому клиенты кешируют его без срока
+func (s *LocalStorage) Handler() http.Handler {
+	files := http.FileServer(http.Dir(s.root))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "" || strings.HasSuffix(r.URL.Path, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		files.ServeHTTP(w, r)
+	})
+}
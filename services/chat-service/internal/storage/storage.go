@@ -0,0 +1,22 @@
+// Package storage хранит файлы, которые клиенты загружают по ссылке,
+// например изображения эмоутов
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrInvalidKey возвращается для ключа, выходящего за пределы хранилища
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Storage — хранилище файлов. Ключ — относительный путь через /,
+// например emotes/<id>/28.png
+type Storage interface {
+	// Save сохраняет файл, заменяя файл с тем же ключом
+	Save(ctx context.Context, key string, data []byte) error
+	// Delete удаляет файл; отсутствие файла ошибкой не считается
+	Delete(ctx context.Context, key string) error
+	// URL возвращает ссылку, по которой клиенты загружают файл
+	URL(key string) string
+}
@@ -0,0 +1,82 @@
+package websocket_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/events"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// broadcastBatch меньше очереди подключения, чтобы читатели бенчмарка
+// не считались медленными и не отключались
+const broadcastBatch = 64
+
+// BenchmarkRoomBroadcast измеряет доставку сообщений из Redis во все
+// подключения комнаты через очереди и write pump подключений
+func BenchmarkRoomBroadcast(b *testing.B) {
+	for _, n := range []int{100, 1000, 2500} {
+		b.Run(fmt.Sprintf("conns=%d", n), func(b *testing.B) {
+			benchmarkBroadcast(b, n)
+		})
+	}
+}
+
+func benchmarkBroadcast(b *testing.B, conns int) {
+	mr := miniredis.RunT(b)
+	ts := startInstance(b, mr.Addr())
+	streamID := uuid.New()
+
+	var delivered atomic.Int64
+	marker := []byte(`"type":"message"`)
+	for i := 0; i < conns; i++ {
+		conn := dial(b, ts, uuid.New(), streamID)
+		go func() {
+			for {
+				_, data, err := conn.ReadMessage()
+				if err != nil {
+					return
+				}
+				if bytes.Contains(data, marker) {
+					delivered.Add(1)
+				}
+			}
+		}()
+	}
+	waitSubscribers(b, mr, streamID, 1)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	b.Cleanup(func() { client.Close() })
+	publisher := events.NewPublisher(client)
+	msg := entity.NewChatMessage(streamID, uuid.New(), "bench", "hello everyone")
+
+	sent := 0
+	publish := func(count int) {
+		for i := 0; i < count; i++ {
+			require.NoError(b, publisher.Publish(context.Background(), events.NewMessageEvent(msg)))
+		}
+		sent += count
+		want := int64(sent * conns)
+		require.Eventually(b, func() bool { return delivered.Load() == want }, time.Minute, time.Millisecond)
+	}
+
+	// Первое сообщение приходит после всех событий присутствия, накопленных при подключении
+	publish(1)
+
+	b.ResetTimer()
+	start := time.Now()
+	for remaining := b.N; remaining > 0; remaining -= broadcastBatch {
+		publish(min(remaining, broadcastBatch))
+	}
+	b.StopTimer()
+
+	b.ReportMetric(float64(b.N*conns)/time.Since(start).Seconds(), "deliveries/s")
+}
@@ -0,0 +1,71 @@
+package websocket
+
+import (
+	"errors"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/commands"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/protocol"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/repository"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+)
+
+// errUserNotConnected возвращается, если у пользователя нет подключений на этом экземпляре
+var errUserNotConnected = errors.New("user not connected")
+
+// nackCodes сопоставляет причины отказа с кодами ошибок протокола
+var nackCodes = []struct {
+	err  error
+	code string
+}{
+	{service.ErrInvalidMessage, protocol.CodeInvalidMessage},
+	{service.ErrRateLimited, protocol.CodeRateLimited},
+	{service.ErrBanned, protocol.CodeBanned},
+	{service.ErrTimedOut, protocol.CodeTimedOut},
+	{service.ErrSlowMode, protocol.CodeSlowMode},
+	{service.ErrFollowersOnly, protocol.CodeFollowersOnly},
+	{service.ErrSubscribersOnly, protocol.CodeSubscribersOnly},
+	{service.ErrEmoteOnly, protocol.CodeEmoteOnly},
+	{service.ErrMessageRejected, protocol.CodeAutoModRejected},
+	{service.ErrMessageHeld, protocol.CodeHeldForReview},
+	{service.ErrReplyNotFound, protocol.CodeInvalidMessage},
+	{service.ErrInvalidWhisper, protocol.CodeInvalidMessage},
+	{service.ErrWhisperSelf, protocol.CodeInvalidMessage},
+	{service.ErrWhisperRateLimited, protocol.CodeRateLimited},
+	{service.ErrWhisperBlocked, protocol.CodeWhisperBlocked},
+	{commands.ErrUnknownCommand, protocol.CodeUnknownCommand},
+	{commands.ErrUsage, protocol.CodeInvalidCommand},
+	{service.ErrForbidden, protocol.CodeForbidden},
+	{service.ErrTargetOutranks, protocol.CodeForbidden},
+	{service.ErrUnknownChatter, protocol.CodeInvalidCommand},
+	{service.ErrCannotBanSelf, protocol.CodeInvalidCommand},
+	{service.ErrInvalidBanDuration, protocol.CodeInvalidCommand},
+	{service.ErrBanReasonTooLong, protocol.CodeInvalidCommand},
+	{service.ErrInvalidChatModes, protocol.CodeInvalidCommand},
+	{service.ErrAudienceUnavailable, protocol.CodeInvalidCommand},
+	{service.ErrInvalidPin, protocol.CodeInvalidCommand},
+	{service.ErrInvalidPinDuration, protocol.CodeInvalidCommand},
+	{service.ErrNoPinnedMessage, protocol.CodeInvalidCommand},
+	{repository.ErrBanNotFound, protocol.CodeInvalidCommand},
+	{repository.ErrMessageNotFound, protocol.CodeInvalidCommand},
+}
+
+// nackCode возвращает код ошибки протокола для причины отказа
+func nackCode(err error) string {
+	for _, c := range nackCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return protocol.CodeInternal
+}
+
+// nackFrame формирует кадр nack для запроса клиента. Текст внутренних ошибок
+// не передается клиенту
+func nackFrame(id string, err error) []byte {
+	code := nackCode(err)
+	message := err.Error()
+	if code == protocol.CodeInternal {
+		message = "internal error"
+	}
+	return protocol.Nack(id, code, message)
+}
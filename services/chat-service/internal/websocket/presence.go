@@ -0,0 +1,153 @@
+package websocket
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/protocol"
+	"github.com/google/uuid"
+)
+
+const (
+	presenceFlushInterval = 2 * time.Second  // Как часто рассылаются накопленные входы и выходы
+	presenceHeartbeat     = 30 * time.Second // Период heartbeat, заметно меньше cache.PresenceTTL
+	presenceListLimit     = 50               // Больше входов и выходов за интервал передаются только счетчиками
+)
+
+// presenceBatch — входы и выходы пользователей комнаты, накопленные за интервал
+type presenceBatch struct {
+	joined map[uuid.UUID]entity.Chatter
+	left   map[uuid.UUID]entity.Chatter
+}
+
+// connectionRole определяет роль пользователя для списка присутствия.
+// При ошибке пользователь считается обычным зрителем
+func (s *ChatServer) connectionRole(ctx context.Context, streamID, userID uuid.UUID) entity.Role {
+	role, err := s.chat.GetRole(ctx, streamID, userID)
+	if err != nil || !role.IsValid() {
+		log.Warn("Failed to resolve chat role", "user_id", userID, "stream_id", streamID, "error", err)
+		return entity.RoleViewer
+	}
+	return role
+}
+
+// joinPresence отмечает пользователя в списке присутствия и ставит вход
+// в очередь на рассылку
+func (s *ChatServer) joinPresence(uc *entity.UserConnection) {
+	if err := s.chat.TouchPresence(context.Background(), uc.StreamID, []entity.Chatter{uc.Chatter()}); err != nil {
+		log.Warn("Failed to track presence", "user_id", uc.UserID, "stream_id", uc.StreamID, "error", err)
+	}
+	s.queuePresence(uc.StreamID, uc.Chatter(), true)
+}
+
+// leavePresence убирает пользователя из списка присутствия и ставит выход
+// в очередь на рассылку. Если пользователь остался в чате через другой
+// экземпляр сервиса, тот вернет его в список ближайшим heartbeat
+func (s *ChatServer) leavePresence(uc *entity.UserConnection) {
+	if err := s.chat.LeavePresence(context.Background(), uc.StreamID, uc.UserID); err != nil {
+		log.Warn("Failed to track presence", "user_id", uc.UserID, "stream_id", uc.StreamID, "error", err)
+	}
+	s.queuePresence(uc.StreamID, uc.Chatter(), false)
+}
+
+// queuePresence добавляет вход или выход в накопленные события комнаты
+func (s *ChatServer) queuePresence(streamID uuid.UUID, chatter entity.Chatter, joined bool) {
+	s.presenceMu.Lock()
+	defer s.presenceMu.Unlock()
+
+	batch, ok := s.presence[streamID]
+	if !ok {
+		batch = &presenceBatch{
+			joined: make(map[uuid.UUID]entity.Chatter),
+			left:   make(map[uuid.UUID]entity.Chatter),
+		}
+		s.presence[streamID] = batch
+	}
+
+	// Вход и выход одного пользователя за интервал взаимно сокращаются
+	if joined {
+		if _, ok := batch.left[chatter.UserID]; ok {
+			delete(batch.left, chatter.UserID)
+			return
+		}
+		batch.joined[chatter.UserID] = chatter
+		return
+	}
+	if _, ok := batch.joined[chatter.UserID]; ok {
+		delete(batch.joined, chatter.UserID)
+		return
+	}
+	batch.left[chatter.UserID] = chatter
+}
+
+// runPresence рассылает накопленные события присутствия и обновляет
+// heartbeat пользователей, подключенных к этому экземпляру
+func (s *ChatServer) runPresence(ctx context.Context) {
+	flush := time.NewTicker(presenceFlushInterval)
+	heartbeat := time.NewTicker(presenceHeartbeat)
+	defer flush.Stop()
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-flush.C:
+			s.flushPresence(ctx)
+		case <-heartbeat.C:
+			s.heartbeatPresence(ctx)
+		}
+	}
+}
+
+// flushPresence публикует по одному событию presence на комнату за интервал
+func (s *ChatServer) flushPresence(ctx context.Context) {
+	s.presenceMu.Lock()
+	batches := s.presence
+	s.presence = make(map[uuid.UUID]*presenceBatch)
+	s.presenceMu.Unlock()
+
+	for streamID, batch := range batches {
+		if len(batch.joined) == 0 && len(batch.left) == 0 {
+			continue
+		}
+
+		event := entity.PresenceEvent{
+			StreamID:    streamID,
+			JoinedCount: len(batch.joined),
+			LeftCount:   len(batch.left),
+		}
+		if event.JoinedCount+event.LeftCount <= presenceListLimit {
+			event.Joined = slices.Collect(maps.Values(batch.joined))
+			event.Left = slices.Collect(maps.Values(batch.left))
+		}
+		count, err := s.chat.CountChatters(ctx, streamID)
+		if err != nil {
+			log.Warn("Failed to count chatters", "stream_id", streamID, "error", err)
+		}
+		event.Chatters = count
+
+		if err := s.publisher.PublishEvent(ctx, streamID, protocol.TypePresence, event); err != nil {
+			log.Error("Failed to publish presence event", "stream_id", streamID, "error", err)
+		}
+	}
+}
+
+// heartbeatPresence продлевает присутствие всех пользователей этого экземпляра
+func (s *ChatServer) heartbeatPresence(ctx context.Context) {
+	s.mu.RLock()
+	rooms := make(map[uuid.UUID][]entity.Chatter, len(s.rooms))
+	for streamID, room := range s.rooms {
+		rooms[streamID] = room.Chatters()
+	}
+	s.mu.RUnlock()
+
+	for streamID, chatters := range rooms {
+		if err := s.chat.TouchPresence(ctx, streamID, chatters); err != nil {
+			log.Warn("Failed to refresh presence", "stream_id", streamID, "error", err)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/events"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// watch подписывает экземпляр на каналы, на которые он еще не подписан.
+// Вызывается под s.mu, но на время запроса к Redis блокировку отпускает:
+// вызывающий перепроверяет состояние, если watch вернул true. Подписка
+// повторяется, если за это время от какого-либо канала отписались — отписка
+// могла уйти в Redis после подписки и отменить ее
+func (s *ChatServer) watch(channels ...string) bool {
+	released := false
+	for {
+		var missing []string
+		for _, channel := range channels {
+			if _, ok := s.watching[channel]; !ok {
+				missing = append(missing, channel)
+			}
+		}
+		if len(missing) == 0 {
+			return released
+		}
+
+		unwatches := s.unwatches
+		s.mu.Unlock()
+		err := s.pubsub.Subscribe(context.Background(), missing...)
+		s.mu.Lock()
+		released = true
+
+		if err != nil {
+			// Как и раньше, участники подключаются и без подписки: сообщения
+			// других экземпляров до них не дойдут, пока канал не переподпишут
+			log.Error("Failed to subscribe to channels", "channels", missing, "error", err)
+		}
+		if s.unwatches != unwatches {
+			continue
+		}
+		for _, channel := range missing {
+			s.watching[channel] = struct{}{}
+		}
+	}
+}
+
+// unwatch отписывает экземпляр от канала. Вызывается под s.mu: отписка уходит
+// в Redis в том же порядке, в котором ее видят конкурирующие подписки
+func (s *ChatServer) unwatch(channel string) error {
+	delete(s.watching, channel)
+	s.unwatches++
+	return s.pubsub.Unsubscribe(context.Background(), channel)
+}
+
+// unwatchRoomIfIdle отписывается от канала комнаты, если на экземпляре
+// не осталось ни участников, ни внутренних подписчиков. Вызывается под s.mu
+func (s *ChatServer) unwatchRoomIfIdle(streamID uuid.UUID) {
+	if _, ok := s.rooms[streamID]; ok {
+		return
+	}
+	if len(s.subscribers[streamID]) > 0 {
+		return
+	}
+	if err := s.unwatch(events.RoomChannel(streamID)); err != nil {
+		log.Error("Failed to unsubscribe from room channel", "stream_id", streamID, "error", err)
+	}
+}
+
+// unwatchUser отписывается от канала пользователя, когда у него не осталось
+// подключений на экземпляре. Вызывается под s.mu
+func (s *ChatServer) unwatchUser(userID uuid.UUID) {
+	if err := s.unwatch(events.UserChannel(userID)); err != nil {
+		log.Error("Failed to unsubscribe from user channel", "user_id", userID, "error", err)
+	}
+}
+
+// handleRoomMessage доставляет полученное из Redis событие локальным участникам
+// комнаты или, для канала пользователя, на его подключения
+func (s *ChatServer) handleRoomMessage(redisMsg *redis.Message) {
+	if userID, ok := events.UserFromChannel(redisMsg.Channel); ok {
+		// Пользователь мог отключиться, пока кадр шел через Redis
+		if err := s.SendMessage(userID, []byte(redisMsg.Payload)); err != nil && !errors.Is(err, errUserNotConnected) {
+			log.Warn("Failed to deliver user frame", "user_id", userID, "error", err)
+		}
+		return
+	}
+
+	event, err := events.DecodeRoomEvent([]byte(redisMsg.Payload))
+	if err != nil {
+		log.Error("Failed to unmarshal room event", "channel", redisMsg.Channel, "error", err)
+		return
+	}
+
+	s.deliverLocal(event)
+}
@@ -0,0 +1,74 @@
+package websocket
+
+import (
+	"time"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	DefaultWriteTimeout = 10 * time.Second // Дедлайн записи, если он не задан в конфигурации
+	pongWait            = 60 * time.Second // Сколько ждать pong от клиента
+	pingPeriod          = pongWait * 9 / 10
+	maxFrameSize        = 8 * 1024 // Максимальный размер кадра клиента
+)
+
+// readPump читает кадры клиента, пока соединение живо. Клиент, не ответивший
+// на ping за pongWait, считается отключившимся
+func (s *ChatServer) readPump(uc *entity.UserConnection) {
+	uc.Conn.SetReadLimit(maxFrameSize)
+	uc.Conn.SetReadDeadline(time.Now().Add(pongWait))
+	uc.Conn.SetPongHandler(func(string) error {
+		return uc.Conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := uc.Conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure, websocket.CloseNoStatusReceived) {
+				log.Warn("WebSocket read failed", "connection_id", uc.ID, "error", err)
+			}
+			return
+		}
+		s.handleFrame(uc, data)
+	}
+}
+
+// writePump — единственный писатель в соединение: передает кадры из очереди
+// подключения и отправляет ping. Каждая запись ограничена дедлайном, поэтому
+// зависший клиент не блокирует рассылку. Переполнение очереди обрабатывается
+// на стороне отправителя: такой клиент отключается (см. ChatRoom.Broadcast)
+func (s *ChatServer) writePump(uc *entity.UserConnection) {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		uc.Close()
+	}()
+
+	for {
+		select {
+		case msg, ok := <-uc.SendChan:
+			if !ok {
+				// Очередь закрыта: кадр закрытия уже отправлен в UserConnection.Close
+				return
+			}
+			uc.Conn.SetWriteDeadline(time.Now().Add(s.writeWait))
+			if err := uc.Conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			uc.Conn.SetWriteDeadline(time.Now().Add(s.writeWait))
+			if err := uc.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeFrame записывает кадр в соединение в обход очереди. Используется
+// только до запуска writePump, пока других писателей нет
+func (s *ChatServer) writeFrame(uc *entity.UserConnection, frame []byte) error {
+	uc.Conn.SetWriteDeadline(time.Now().Add(s.writeWait))
+	return uc.Conn.WriteMessage(websocket.TextMessage, frame)
+}
@@ -0,0 +1,63 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/events"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/protocol"
+)
+
+// parseLastSeq разбирает необязательный параметр last_seq. Второе значение
+// сообщает, передан ли параметр, то есть переподключается ли клиент
+func parseLastSeq(r *http.Request) (int64, bool, error) {
+	value := r.URL.Query().Get("last_seq")
+	if value == "" {
+		return 0, false, nil
+	}
+	seq, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, false, err
+	}
+	if seq < 0 {
+		return 0, false, errors.New("last_seq must not be negative")
+	}
+	return seq, true, nil
+}
+
+// resume отправляет клиенту сообщения комнаты после lastSeq и кадр resumed.
+// Вызывается после входа в комнату, но до запуска writePump, поэтому пишет
+// в соединение напрямую. Живые сообщения, пришедшие во время повтора, ждут
+// в очереди подключения: окно между повтором и живой доставкой не теряется,
+// а попавшие в оба потока сообщения клиент отбрасывает по seq
+func (s *ChatServer) resume(ctx context.Context, uc *entity.UserConnection, lastSeq int64) error {
+	replay, err := s.chat.ReplayMessages(ctx, uc.StreamID, lastSeq)
+	if err != nil {
+		// Клиент узнает о неполном повторе и догрузит сообщения из истории
+		log.Error("Failed to replay messages", "stream_id", uc.StreamID, "last_seq", lastSeq, "error", err)
+		replay = &entity.MessageReplay{Truncated: true}
+	}
+
+	for _, msg := range replay.Messages {
+		frame, err := protocol.Encode(events.MessageFrameType(msg), "", msg)
+		if err != nil {
+			return err
+		}
+		if err := s.writeFrame(uc, frame); err != nil {
+			return err
+		}
+	}
+
+	frame, err := protocol.Encode(protocol.TypeResumed, "", protocol.ResumedPayload{
+		LastSeq:   replay.LastSeq,
+		Replayed:  len(replay.Messages),
+		Truncated: replay.Truncated,
+	})
+	if err != nil {
+		return err
+	}
+	return s.writeFrame(uc, frame)
+}
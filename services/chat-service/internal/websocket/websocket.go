@@ -4,18 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"net/http"
 	"sync"
 	"time"
 
+	"github.com/exPriceD/Streaming-platform/config"
 	"github.com/exPriceD/Streaming-platform/pkg/logger"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/auth"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/commands"
 	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/events"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/protocol"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/redis/go-redis/v9"
-	"golang.org/x/time/rate"
 )
 
 var (
@@ -25,31 +27,85 @@ var (
 	log = logger.InitLogger("websocket")
 )
 
+// DefaultMaxConnectionsPerUser — лимит одновременных подключений пользователя,
+// если он не задан в конфигурации
+const DefaultMaxConnectionsPerUser = 5
+
+// errTooManyConnections возвращается, если пользователь превысил лимит подключений
+var errTooManyConnections = errors.New("too many connections")
+
+// ChatService описывает бизнес-логику чата, которую использует WebSocket-сервер
+type ChatService interface {
+	QueueMessage(msg *entity.ChatMessage) error
+	PrepareMessage(ctx context.Context, msg *entity.ChatMessage, replyTo uuid.UUID) error
+	CheckBan(ctx context.Context, streamID, userID uuid.UUID) error
+	GetRole(ctx context.Context, streamID, userID uuid.UUID) (entity.Role, error)
+	GetChatModes(ctx context.Context, streamID uuid.UUID) (entity.ChatModes, error)
+	GetPinnedMessage(ctx context.Context, streamID uuid.UUID) (*entity.PinnedMessage, error)
+	NotifyMentions(ctx context.Context, msg *entity.ChatMessage)
+	ReplayMessages(ctx context.Context, streamID uuid.UUID, afterSeq int64) (*entity.MessageReplay, error)
+	TouchPresence(ctx context.Context, streamID uuid.UUID, chatters []entity.Chatter) error
+	LeavePresence(ctx context.Context, streamID, userID uuid.UUID) error
+	CountChatters(ctx context.Context, streamID uuid.UUID) (int, error)
+	SendWhisper(ctx context.Context, fromUserID uuid.UUID, fromUsername string, toUserID uuid.UUID, content string) (*entity.Whisper, error)
+}
+
 // ChatServer управляет подключениями пользователей
 type ChatServer struct {
-	clients     map[uuid.UUID]*websocket.Conn // Хранение соединений по ID пользователя
+	rooms       map[uuid.UUID]*entity.ChatRoom                      // Комнаты чата по ID стрима
+	clients     map[uuid.UUID]map[uuid.UUID]*entity.UserConnection  // Подключения пользователя по ID пользователя и ID подключения
+	subscribers map[uuid.UUID]map[chan *entity.ChatMessage]struct{} // Внутренние подписчики комнат (gRPC)
 	mu          sync.RWMutex
-	broadcast   chan *entity.ChatMessage
-	jwtSecret   string
+	tokens      *auth.TokenValidator
 	redisClient *redis.Client
-	spamLimiter *rate.Limiter
+	chat        ChatService
+	commands    *commands.Registry // Команды модерации; без реестра текст с / уходит в чат как есть
+	publisher   *events.Publisher
+	pubsub      *redis.PubSub       // Подписки на каналы комнат, активных на этом экземпляре
+	watching    map[string]struct{} // Каналы Redis, на которые экземпляр подписан
+	unwatches   uint64              // Число отписок от каналов; по нему подписка узнает, что ее обогнали
+	maxPerUser  int                 // Лимит одновременных подключений одного пользователя
+	writeWait   time.Duration       // Дедлайн записи одного кадра
+	presenceMu  sync.Mutex
+	presence    map[uuid.UUID]*presenceBatch // Входы и выходы, ожидающие рассылки, по ID стрима
 }
 
 // NewChatServer создает новый WebSocket-сервер
-func NewChatServer(jwtSecret string, redisClient *redis.Client) *ChatServer {
+func NewChatServer(cfg config.WebSocketConfig, redisClient *redis.Client, chat ChatService) *ChatServer {
+	maxPerUser := cfg.MaxConnectionsPerUser
+	if maxPerUser <= 0 {
+		maxPerUser = DefaultMaxConnectionsPerUser
+	}
+	writeWait := time.Duration(cfg.WriteTimeout) * time.Second
+	if writeWait <= 0 {
+		writeWait = DefaultWriteTimeout
+	}
+
 	return &ChatServer{
-		clients:     make(map[uuid.UUID]*websocket.Conn),
-		broadcast:   make(chan *entity.ChatMessage),
-		jwtSecret:   jwtSecret,
+		rooms:       make(map[uuid.UUID]*entity.ChatRoom),
+		clients:     make(map[uuid.UUID]map[uuid.UUID]*entity.UserConnection),
+		subscribers: make(map[uuid.UUID]map[chan *entity.ChatMessage]struct{}),
+		tokens:      auth.NewTokenValidator(cfg.JWTSecret),
 		redisClient: redisClient,
-		spamLimiter: rate.NewLimiter(rate.Every(time.Minute), 20), // 20 сообщений в минуту
+		chat:        chat,
+		publisher:   events.NewPublisher(redisClient),
+		pubsub:      redisClient.Subscribe(context.Background()),
+		watching:    make(map[string]struct{}),
+		maxPerUser:  maxPerUser,
+		writeWait:   writeWait,
+		presence:    make(map[uuid.UUID]*presenceBatch),
 	}
 }
 
-// HandleConnection обрабатывает новое подключение
+// SetCommands подключает реестр команд, которые модераторы вводят в чат
+func (s *ChatServer) SetCommands(registry *commands.Registry) {
+	s.commands = registry
+}
+
+// HandleConnection обрабатывает новое подключение к чату стрима
 func (s *ChatServer) HandleConnection(w http.ResponseWriter, r *http.Request) {
 	// Аутентификация через JWT
-	tokenString := r.URL.Query().Get("token")
+	tokenString := auth.TokenFromRequest(r)
 	if tokenString == "" {
 		log.Warn("Connection attempt without token")
 		w.WriteHeader(http.StatusUnauthorized)
@@ -57,147 +113,422 @@ func (s *ChatServer) HandleConnection(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Валидация токена
-	claims, err := s.validateToken(tokenString)
+	claims, err := s.tokens.Validate(tokenString)
 	if err != nil {
 		log.Warn("Invalid token", "error", err)
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
 
+	// Комната чата определяется стримом, который смотрит пользователь
+	streamID, err := uuid.Parse(r.URL.Query().Get("stream_id"))
+	if err != nil {
+		log.Warn("Invalid stream_id", "error", err)
+		http.Error(w, "Invalid stream_id", http.StatusBadRequest)
+		return
+	}
+
+	// Номер последнего полученного сообщения при переподключении
+	lastSeq, resuming, err := parseLastSeq(r)
+	if err != nil {
+		log.Warn("Invalid last_seq", "error", err)
+		http.Error(w, "Invalid last_seq", http.StatusBadRequest)
+		return
+	}
+
+	// Забаненные пользователи и пользователи в таймауте не допускаются в чат
+	userID := claims.UserID
+	if err := s.chat.CheckBan(r.Context(), streamID, userID); err != nil {
+		log.Info("Connection rejected", "user_id", userID, "stream_id", streamID, "error", err)
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	// Лимит проверяется до обновления соединения, чтобы ответить обычным HTTP-статусом
+	if s.connectionCount(userID) >= s.maxPerUser {
+		log.Info("Connection rejected", "user_id", userID, "stream_id", streamID, "error", errTooManyConnections)
+		http.Error(w, errTooManyConnections.Error(), http.StatusTooManyRequests)
+		return
+	}
+
 	// Обновление соединения до WebSocket
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Error("WebSocket upgrade failed", "error", err)
 		return
 	}
-	defer conn.Close()
-
-	// Сохранение соединения
-	userID := uuid.MustParse(claims["user_id"].(string))
-	s.mu.Lock()
-	s.clients[userID] = conn
-	s.mu.Unlock()
-
-	log.Info("New WebSocket connection", "user_id", userID)
 
-	// Обработка входящих сообщений
-	for {
-		var msg entity.ChatMessage
-		if err := conn.ReadJSON(&msg); err != nil {
-			s.mu.Lock()
-			delete(s.clients, userID)
-			s.mu.Unlock()
-			log.Info("WebSocket connection closed", "user_id", userID)
-			break
+	// Сохранение соединения и вход в комнату. Лимит проверяется повторно
+	// на случай одновременных подключений
+	uc := entity.NewUserConnection(userID, claims.Username, conn)
+	uc.Role = s.connectionRole(r.Context(), streamID, userID)
+	firstInRoom, err := s.joinRoom(streamID, uc)
+	if err != nil {
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, err.Error()),
+			time.Now().Add(time.Second))
+		conn.Close()
+		return
+	}
+	defer func() {
+		if lastInRoom := s.leaveRoom(uc); lastInRoom {
+			s.leavePresence(uc)
 		}
+	}()
 
-		// Валидация и обработка сообщения
-		if err := s.processMessage(userID, &msg); err != nil {
-			log.Warn("Message processing failed", "error", err)
-			continue
+	// Повтор пропущенных сообщений до запуска writePump: живые сообщения
+	// копятся в очереди подключения и уходят клиенту следом за повтором
+	if resuming {
+		if err := s.resume(r.Context(), uc, lastSeq); err != nil {
+			log.Warn("Failed to resume connection", "user_id", userID, "stream_id", streamID, "error", err)
+			return
 		}
+	}
 
-		s.broadcast <- &msg
+	go s.writePump(uc)
+
+	log.Info("New WebSocket connection", "user_id", userID, "stream_id", streamID, "connection_id", uc.ID)
+	s.sendRoomState(r.Context(), uc)
+	s.sendPinnedMessage(r.Context(), uc)
+	if firstInRoom {
+		s.joinPresence(uc)
 	}
+
+	// Обработка входящих кадров до закрытия соединения
+	s.readPump(uc)
+	log.Info("WebSocket connection closed", "user_id", userID, "stream_id", streamID, "connection_id", uc.ID)
 }
 
-// validateToken проверяет JWT токен
-func (s *ChatServer) validateToken(tokenString string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(s.jwtSecret), nil
+// handleFrame разбирает кадр клиента и отвечает на него ack или nack
+func (s *ChatServer) handleFrame(uc *entity.UserConnection, data []byte) {
+	env, err := protocol.Decode(data)
+	switch {
+	case errors.Is(err, protocol.ErrUnsupportedVersion):
+		uc.SendMessage(protocol.Nack(env.ID, protocol.CodeUnsupportedVersion, err.Error()))
+		return
+	case err != nil:
+		uc.SendMessage(protocol.Nack("", protocol.CodeInvalidFrame, "malformed frame"))
+		return
+	}
+
+	switch env.Type {
+	case protocol.TypeSend:
+		s.handleSend(uc, env)
+	case protocol.TypeWhisper:
+		s.handleWhisper(uc, env)
+	default:
+		uc.SendMessage(protocol.Nack(env.ID, protocol.CodeUnknownType, "unknown frame type "+env.Type))
+	}
+}
+
+// handleSend обрабатывает кадр send: проверяет сообщение, сохраняет и рассылает его
+func (s *ChatServer) handleSend(uc *entity.UserConnection, env *protocol.Envelope) {
+	var payload protocol.SendPayload
+	if err := json.Unmarshal(env.Payload, &payload); err != nil {
+		uc.SendMessage(protocol.Nack(env.ID, protocol.CodeInvalidFrame, "malformed send payload"))
+		return
+	}
+
+	// Команды не рассылаются в чат: результат получает только отправитель
+	if name, args, ok := commands.Parse(payload.Content); ok && s.commands != nil {
+		s.handleCommand(uc, env, name, args)
+		return
+	}
+
+	// Сообщение всегда относится к комнате, в которой находится пользователь
+	msg := &entity.ChatMessage{
+		StreamID: uc.StreamID,
+		UserID:   uc.UserID,
+		Username: uc.Username,
+		Content:  payload.Content,
+	}
+
+	// Валидация и обработка сообщения
+	if err := s.chat.PrepareMessage(context.Background(), msg, payload.ReplyTo); err != nil {
+		log.Info("Message rejected", "user_id", uc.UserID, "stream_id", uc.StreamID, "error", err)
+		uc.SendMessage(nackFrame(env.ID, err))
+		return
+	}
+
+	// Сервисный слой сохраняет и рассылает только принятые сообщения
+	if err := s.chat.QueueMessage(msg); err != nil {
+		log.Error("Failed to queue message for saving", "error", err)
+		uc.SendMessage(protocol.Nack(env.ID, protocol.CodeInternal, "message was not saved"))
+		return
+	}
+
+	if ack, err := protocol.Encode(protocol.TypeAck, env.ID, msg); err == nil {
+		uc.SendMessage(ack)
+	}
+	s.chat.NotifyMentions(context.Background(), msg)
+}
+
+// handleCommand выполняет команду чата от имени отправителя с его текущей ролью в комнате
+func (s *ChatServer) handleCommand(uc *entity.UserConnection, env *protocol.Envelope, name string, args []string) {
+	ctx := context.Background()
+	role, err := s.chat.GetRole(ctx, uc.StreamID, uc.UserID)
+	if err != nil {
+		log.Error("Failed to resolve chat role", "user_id", uc.UserID, "stream_id", uc.StreamID, "error", err)
+		uc.SendMessage(nackFrame(env.ID, err))
+		return
+	}
+
+	reply, err := s.commands.Dispatch(ctx, &commands.Invocation{
+		StreamID: uc.StreamID,
+		UserID:   uc.UserID,
+		Username: uc.Username,
+		Role:     role,
+		Name:     name,
+		Args:     args,
 	})
+	if err != nil {
+		log.Info("Command rejected", "user_id", uc.UserID, "stream_id", uc.StreamID, "command", name, "error", err)
+		uc.SendMessage(nackFrame(env.ID, err))
+		return
+	}
+
+	log.Info("Command executed", "user_id", uc.UserID, "stream_id", uc.StreamID, "command", name)
+	if ack, err := protocol.Encode(protocol.TypeAck, env.ID, protocol.CommandResultPayload{Command: name, Message: reply}); err == nil {
+		uc.SendMessage(ack)
+	}
+}
 
+// handleWhisper обрабатывает кадр whisper: сохраняет личное сообщение
+// и доставляет его получателю на всех экземплярах сервиса
+func (s *ChatServer) handleWhisper(uc *entity.UserConnection, env *protocol.Envelope) {
+	var payload protocol.WhisperPayload
+	if err := json.Unmarshal(env.Payload, &payload); err != nil {
+		uc.SendMessage(protocol.Nack(env.ID, protocol.CodeInvalidFrame, "malformed whisper payload"))
+		return
+	}
+
+	whisper, err := s.chat.SendWhisper(context.Background(), uc.UserID, uc.Username, payload.ToUserID, payload.Content)
 	if err != nil {
-		return nil, err
+		log.Info("Whisper rejected", "user_id", uc.UserID, "to_user_id", payload.ToUserID, "error", err)
+		uc.SendMessage(nackFrame(env.ID, err))
+		return
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		return claims, nil
+	if ack, err := protocol.Encode(protocol.TypeAck, env.ID, whisper); err == nil {
+		uc.SendMessage(ack)
 	}
+}
 
-	return nil, errors.New("invalid token")
+// sendRoomState отправляет новому участнику текущие режимы чата
+func (s *ChatServer) sendRoomState(ctx context.Context, uc *entity.UserConnection) {
+	modes, err := s.chat.GetChatModes(ctx, uc.StreamID)
+	if err != nil {
+		log.Warn("Failed to load chat modes", "stream_id", uc.StreamID, "error", err)
+		return
+	}
+	frame, err := protocol.Encode(protocol.TypeRoomState, "", entity.RoomStateEvent{StreamID: uc.StreamID, Modes: modes})
+	if err != nil {
+		return
+	}
+	uc.SendMessage(frame)
 }
 
-// processMessage обрабатывает входящее сообщение
-func (s *ChatServer) processMessage(userID uuid.UUID, msg *entity.ChatMessage) error {
-	// Установка идентификатора пользователя
-	msg.UserID = userID
-	msg.Timestamp = time.Now().UTC()
+// sendPinnedMessage отправляет новому участнику закрепленное сообщение, если оно есть
+func (s *ChatServer) sendPinnedMessage(ctx context.Context, uc *entity.UserConnection) {
+	pin, err := s.chat.GetPinnedMessage(ctx, uc.StreamID)
+	if err != nil {
+		log.Warn("Failed to load pinned message", "stream_id", uc.StreamID, "error", err)
+		return
+	}
+	if pin == nil {
+		return
+	}
+	frame, err := protocol.Encode(protocol.TypeMessagePinned, "", pin)
+	if err != nil {
+		return
+	}
+	uc.SendMessage(frame)
+}
+
+// joinRoom добавляет подключение в комнату стрима, создавая ее при необходимости.
+// Возвращает true, если это первое подключение пользователя в комнате
+func (s *ChatServer) joinRoom(streamID uuid.UUID, uc *entity.UserConnection) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	// Валидация сообщения
-	if !msg.Validate() {
-		return errors.New("invalid message format")
+	if len(s.clients[uc.UserID]) >= s.maxPerUser {
+		return false, errTooManyConnections
+	}
+	// Пока шла подписка на каналы, пользователь мог занять все подключения
+	// с другого запроса
+	released := s.watch(events.RoomChannel(streamID), events.UserChannel(uc.UserID))
+	if released && len(s.clients[uc.UserID]) >= s.maxPerUser {
+		s.unwatchRoomIfIdle(streamID)
+		return false, errTooManyConnections
 	}
 
-	// Проверка на спам
-	if s.isSpam(userID) {
-		return errors.New("spam detected")
+	room, ok := s.rooms[streamID]
+	if !ok {
+		room = entity.NewChatRoom(streamID)
+		s.rooms[streamID] = room
 	}
+	first := !room.HasUser(uc.UserID)
+	room.AddConnection(uc)
 
-	return nil
+	if _, ok := s.clients[uc.UserID]; !ok {
+		s.clients[uc.UserID] = make(map[uuid.UUID]*entity.UserConnection)
+	}
+	s.clients[uc.UserID][uc.ID] = uc
+	return first, nil
+}
+
+// leaveRoom удаляет подключение из комнаты и удаляет опустевшую комнату.
+// Возвращает true, если у пользователя не осталось подключений в комнате
+func (s *ChatServer) leaveRoom(uc *entity.UserConnection) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last := true
+	if room, ok := s.rooms[uc.StreamID]; ok {
+		room.RemoveConnection(uc.ID)
+		last = !room.HasUser(uc.UserID)
+		if room.Len() == 0 {
+			delete(s.rooms, uc.StreamID)
+			s.unwatchRoomIfIdle(uc.StreamID)
+		}
+	}
+	if conns, ok := s.clients[uc.UserID]; ok {
+		delete(conns, uc.ID)
+		if len(conns) == 0 {
+			delete(s.clients, uc.UserID)
+			s.unwatchUser(uc.UserID)
+		}
+	}
+	uc.Close()
+	return last
 }
 
-// StartBroadcast запускает рассылку сообщений
+// connectionCount возвращает число подключений пользователя на этом экземпляре
+func (s *ChatServer) connectionCount(userID uuid.UUID) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.clients[userID])
+}
+
+// StartBroadcast запускает рассылку сообщений по комнатам стримов. Сообщения
+// и события публикуются в Redis сервисным слоем, а здесь полученные из Redis
+// доставляются локальным участникам комнат, поэтому комнаты работают между
+// экземплярами сервиса
 func (s *ChatServer) StartBroadcast(ctx context.Context) {
+	defer s.pubsub.Close()
+	incoming := s.pubsub.Channel()
+	go s.runPresence(ctx)
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case msg := <-s.broadcast:
-			data, _ := json.Marshal(msg)
-			s.mu.Lock()
-			for client := range s.clients {
-				client.WriteMessage(websocket.TextMessage, data)
+		case redisMsg, ok := <-incoming:
+			if !ok {
+				return
 			}
-			s.mu.Unlock()
+			s.handleRoomMessage(redisMsg)
 		}
 	}
 }
 
-// isSpam проверяет, не отправляет ли пользователь слишком много сообщений
-func (s *ChatServer) isSpam(userID uuid.UUID) bool {
-	// Проверка через Redis
-	key := fmt.Sprintf("spam:%s", userID)
-	count, err := s.redisClient.Incr(context.Background(), key).Result()
+// deliverLocal доставляет событие участникам комнаты на этом экземпляре
+func (s *ChatServer) deliverLocal(event *events.RoomEvent) {
+	data, err := event.Payload()
 	if err != nil {
-		log.Error("Redis error", "error", err)
-		return true
+		log.Error("Failed to marshal room event", "stream_id", event.StreamID, "error", err)
+		return
 	}
 
-	if count == 1 {
-		s.redisClient.Expire(context.Background(), key, time.Minute)
+	var slow []chan *entity.ChatMessage
+	s.mu.RLock()
+	room, ok := s.rooms[event.StreamID]
+	if event.Message != nil {
+		for sub := range s.subscribers[event.StreamID] {
+			select {
+			case sub <- event.Message:
+			default:
+				slow = append(slow, sub)
+			}
+		}
+	}
+	s.mu.RUnlock()
+	for _, sub := range slow {
+		log.Warn("Subscriber is too slow, subscription closed", "stream_id", event.StreamID)
+		s.dropSubscriber(event.StreamID, sub, true)
 	}
 
-	return count > 10
+	if ok {
+		if dropped := room.Broadcast(data); dropped > 0 {
+			log.Warn("Slow consumers disconnected", "stream_id", event.StreamID, "count", dropped)
+		}
+	}
 }
 
-// SendMessage отправляет сообщение пользователю
-func (s *ChatServer) SendMessage(userID uuid.UUID, message []byte) error {
-	// Проверка лимита
-	if !s.spamLimiter.Allow() {
-		return errors.New("message rate limit exceeded")
+// Subscribe подписывает на сообщения комнаты стрима.
+// Возвращает канал сообщений и функцию отмены подписки. Канал подписчика,
+// который не успевает забирать сообщения, закрывается: пропусков без сигнала
+// не бывает, подписчик должен переподключиться и повторить пропущенное
+func (s *ChatServer) Subscribe(streamID uuid.UUID) (<-chan *entity.ChatMessage, func()) {
+	ch := make(chan *entity.ChatMessage, 64)
+
+	s.mu.Lock()
+	s.watch(events.RoomChannel(streamID))
+	if _, ok := s.subscribers[streamID]; !ok {
+		s.subscribers[streamID] = make(map[chan *entity.ChatMessage]struct{})
+	}
+	s.subscribers[streamID][ch] = struct{}{}
+	s.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() { s.dropSubscriber(streamID, ch, false) })
 	}
 
-	// Отправка с таймаутом
+	return ch, unsubscribe
+}
+
+// dropSubscriber отменяет подписку на комнату. Канал закрывается, только если
+// подписка отменяется сервером и подписчик еще не отписался сам
+func (s *ChatServer) dropSubscriber(streamID uuid.UUID, ch chan *entity.ChatMessage, closeChan bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscribers[streamID][ch]; !ok {
+		return
+	}
+	delete(s.subscribers[streamID], ch)
+	if closeChan {
+		close(ch)
+	}
+	if len(s.subscribers[streamID]) == 0 {
+		delete(s.subscribers, streamID)
+		s.unwatchRoomIfIdle(streamID)
+	}
+}
+
+// SendMessage отправляет кадр на все подключения пользователя на этом экземпляре
+func (s *ChatServer) SendMessage(userID uuid.UUID, message []byte) error {
 	s.mu.RLock()
-	conn, ok := s.clients[userID]
+	conns := make([]*entity.UserConnection, 0, len(s.clients[userID]))
+	for _, uc := range s.clients[userID] {
+		conns = append(conns, uc)
+	}
 	s.mu.RUnlock()
 
-	if !ok {
-		return errors.New("user not connected")
+	if len(conns) == 0 {
+		return errUserNotConnected
 	}
 
-	conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
-	err := conn.WriteMessage(websocket.TextMessage, message)
-	if err != nil {
-		s.mu.Lock()
-		delete(s.clients, userID)
-		s.mu.Unlock()
-		return err
+	delivered := 0
+	for _, uc := range conns {
+		if uc.SendMessage(message) {
+			delivered++
+		} else {
+			uc.Close()
+		}
+	}
+	if delivered == 0 {
+		return errors.New("user connections are closed or overloaded")
 	}
-
 	return nil
 }
@@ -0,0 +1,513 @@
+package websocket_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/exPriceD/Streaming-platform/config"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/commands"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/entity"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/events"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/protocol"
+	"github.com/exPriceD/Streaming-platform/services/chat-service/internal/service"
+	chatws "github.com/exPriceD/Streaming-platform/services/chat-service/internal/websocket"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "test_secret"
+
+// stubChat рассылает принятые сообщения, ничего не сохраняя, никого не банит,
+// считает всех обычными зрителями и пропускает все сообщения без фильтров.
+// В каждой комнате закреплено одно и то же сообщение. При переподключении повторяет два сообщения после last_seq, присутствие не хранит
+type stubChat struct {
+	chatws.ChatService
+	publisher *events.Publisher
+}
+
+func (c stubChat) QueueMessage(msg *entity.ChatMessage) error {
+	return c.publisher.Publish(context.Background(), events.NewMessageEvent(msg))
+}
+
+func (stubChat) PrepareMessage(_ context.Context, msg *entity.ChatMessage, _ uuid.UUID) error {
+	if !msg.Validate() {
+		return service.ErrInvalidMessage
+	}
+	msg.ID = uuid.New()
+	return nil
+}
+
+func (stubChat) CheckBan(context.Context, uuid.UUID, uuid.UUID) error { return nil }
+
+func (stubChat) GetRole(context.Context, uuid.UUID, uuid.UUID) (entity.Role, error) {
+	return entity.RoleViewer, nil
+}
+
+func (stubChat) GetChatModes(context.Context, uuid.UUID) (entity.ChatModes, error) {
+	return entity.ChatModes{SlowModeSeconds: 3}, nil
+}
+
+func (stubChat) GetPinnedMessage(_ context.Context, streamID uuid.UUID) (*entity.PinnedMessage, error) {
+	msg := entity.NewAnnouncement(streamID, uuid.New(), "broadcaster", "welcome", entity.AnnouncementBlue)
+	return entity.NewPinnedMessage(msg, msg.UserID, time.Hour), nil
+}
+
+func (stubChat) NotifyMentions(context.Context, *entity.ChatMessage) {}
+
+func (stubChat) TouchPresence(context.Context, uuid.UUID, []entity.Chatter) error { return nil }
+
+func (stubChat) LeavePresence(context.Context, uuid.UUID, uuid.UUID) error { return nil }
+
+func (stubChat) CountChatters(context.Context, uuid.UUID) (int, error) { return 0, nil }
+
+func (stubChat) ReplayMessages(_ context.Context, streamID uuid.UUID, afterSeq int64) (*entity.MessageReplay, error) {
+	replay := &entity.MessageReplay{LastSeq: afterSeq + 2}
+	for seq := afterSeq + 1; seq <= replay.LastSeq; seq++ {
+		msg := entity.NewChatMessage(streamID, uuid.New(), "missed", fmt.Sprintf("missed %d", seq))
+		msg.Seq = seq
+		replay.Messages = append(replay.Messages, msg)
+	}
+	return replay, nil
+}
+
+// startInstance поднимает экземпляр ChatServer поверх общего Redis
+func startInstance(t testing.TB, redisAddr string) *httptest.Server {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{Addr: redisAddr})
+	cfg := config.WebSocketConfig{JWTSecret: testSecret, MaxConnectionsPerUser: 2}
+	srv := chatws.NewChatServer(cfg, client, stubChat{publisher: events.NewPublisher(client)})
+	srv.SetCommands(commands.NewRegistry(nil, append(commands.DefaultCommands(), commands.Command{
+		Name: "ping",
+		Role: entity.RoleViewer,
+		Run: func(context.Context, commands.Service, *commands.Invocation) (string, error) {
+			return "pong", nil
+		},
+	})...))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go srv.StartBroadcast(ctx)
+
+	ts := httptest.NewServer(http.HandlerFunc(srv.HandleConnection))
+	t.Cleanup(func() {
+		ts.Close()
+		cancel()
+		client.Close()
+	})
+	return ts
+}
+
+// connect открывает WebSocket-подключение пользователя к чату стрима
+func connect(t testing.TB, ts *httptest.Server, userID, streamID uuid.UUID) (*websocket.Conn, *http.Response, error) {
+	t.Helper()
+	return websocket.DefaultDialer.Dial(wsURL(t, ts, userID, streamID), nil)
+}
+
+// wsURL возвращает адрес подключения пользователя к чату стрима
+func wsURL(t testing.TB, ts *httptest.Server, userID, streamID uuid.UUID) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id":  userID.String(),
+		"username": "user-" + userID.String()[:8],
+	}).SignedString([]byte(testSecret))
+	require.NoError(t, err)
+
+	return "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws?token=" + token + "&stream_id=" + streamID.String()
+}
+
+// dial подключает пользователя к чату стрима
+func dial(t testing.TB, ts *httptest.Server, userID, streamID uuid.UUID) *websocket.Conn {
+	t.Helper()
+
+	conn, _, err := connect(t, ts, userID, streamID)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// send отправляет сообщение в кадре send
+func send(t *testing.T, conn *websocket.Conn, id, content string) {
+	t.Helper()
+
+	payload, err := json.Marshal(protocol.SendPayload{Content: content})
+	require.NoError(t, err)
+	require.NoError(t, conn.WriteJSON(protocol.Envelope{Version: protocol.Version, Type: protocol.TypeSend, ID: id, Payload: payload}))
+}
+
+// readFrame читает кадры, пока не встретит кадр нужного типа
+func readFrame(t *testing.T, conn *websocket.Conn, frameType string) protocol.Envelope {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var env protocol.Envelope
+		require.NoError(t, conn.ReadJSON(&env))
+		require.Equal(t, protocol.Version, env.Version)
+		if env.Type == frameType {
+			return env
+		}
+	}
+}
+
+// waitSubscribers ждёт, пока на канал комнаты подпишется нужное число экземпляров
+func waitSubscribers(t testing.TB, mr *miniredis.Miniredis, streamID uuid.UUID, want int) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		return mr.PubSubNumSub("chat:room:" + streamID.String())["chat:room:"+streamID.String()] == want
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+// TestBroadcastAcrossInstances проверяет, что сообщение, отправленное через
+// один экземпляр, доходит до зрителей той же комнаты на другом экземпляре
+func TestBroadcastAcrossInstances(t *testing.T) {
+	mr := miniredis.RunT(t)
+	first := startInstance(t, mr.Addr())
+	second := startInstance(t, mr.Addr())
+
+	streamID := uuid.New()
+	otherStreamID := uuid.New()
+
+	sender := dial(t, first, uuid.New(), streamID)
+	receiver := dial(t, second, uuid.New(), streamID)
+	outsider := dial(t, second, uuid.New(), otherStreamID)
+	waitSubscribers(t, mr, streamID, 2)
+	waitSubscribers(t, mr, otherStreamID, 1)
+
+	send(t, sender, "c-1", "hello")
+
+	ack := readFrame(t, sender, protocol.TypeAck)
+	assert.Equal(t, "c-1", ack.ID)
+
+	var got entity.ChatMessage
+	require.NoError(t, json.Unmarshal(readFrame(t, receiver, protocol.TypeMessage).Payload, &got))
+	assert.Equal(t, "hello", got.Content)
+	assert.Equal(t, streamID, got.StreamID)
+	assert.NotEmpty(t, got.Username)
+
+	// Сообщение не должно попасть в комнату другого стрима
+	outsider.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	for {
+		var env protocol.Envelope
+		if err := outsider.ReadJSON(&env); err != nil {
+			break
+		}
+		assert.NotEqual(t, protocol.TypeMessage, env.Type)
+	}
+}
+
+// TestSlowSubscriberClosed проверяет, что канал внутреннего подписчика,
+// который не забирает сообщения, закрывается, а не теряет их молча
+func TestSlowSubscriberClosed(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	publisher := events.NewPublisher(client)
+	srv := chatws.NewChatServer(config.WebSocketConfig{JWTSecret: testSecret}, client, stubChat{publisher: publisher})
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go srv.StartBroadcast(ctx)
+
+	streamID := uuid.New()
+	messages, unsubscribe := srv.Subscribe(streamID)
+	defer unsubscribe()
+	waitSubscribers(t, mr, streamID, 1)
+
+	for i := 0; i < 100; i++ {
+		msg := entity.NewChatMessage(streamID, uuid.New(), "viewer", fmt.Sprintf("message %d", i))
+		require.NoError(t, publisher.Publish(ctx, events.NewMessageEvent(msg)))
+	}
+	// Последний подписчик комнаты снят, поэтому экземпляр отписывается от канала Redis
+	waitSubscribers(t, mr, streamID, 0)
+
+	received := 0
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case _, ok := <-messages:
+			if !ok {
+				assert.Less(t, received, 100)
+				return
+			}
+			received++
+		case <-timeout:
+			t.Fatalf("subscription was not closed after %d messages", received)
+		}
+	}
+}
+
+// TestProtocolErrors проверяет, что клиент получает явный отказ с кодом ошибки
+func TestProtocolErrors(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ts := startInstance(t, mr.Addr())
+
+	streamID := uuid.New()
+	conn := dial(t, ts, uuid.New(), streamID)
+
+	var state entity.RoomStateEvent
+	require.NoError(t, json.Unmarshal(readFrame(t, conn, protocol.TypeRoomState).Payload, &state))
+	assert.Equal(t, 3, state.Modes.SlowModeSeconds)
+
+	require.NoError(t, conn.WriteJSON(protocol.Envelope{Version: 99, Type: protocol.TypeSend, ID: "c-1"}))
+	var nack protocol.NackPayload
+	env := readFrame(t, conn, protocol.TypeNack)
+	require.NoError(t, json.Unmarshal(env.Payload, &nack))
+	assert.Equal(t, "c-1", env.ID)
+	assert.Equal(t, protocol.CodeUnsupportedVersion, nack.Code)
+
+	send(t, conn, "c-2", "")
+	env = readFrame(t, conn, protocol.TypeNack)
+	require.NoError(t, json.Unmarshal(env.Payload, &nack))
+	assert.Equal(t, "c-2", env.ID)
+	assert.Equal(t, protocol.CodeInvalidMessage, nack.Code)
+}
+
+// TestAnnouncementAndPin проверяет, что новый участник получает закрепленное
+// сообщение, а объявления приходят отдельным от обычных сообщений кадром
+func TestAnnouncementAndPin(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ts := startInstance(t, mr.Addr())
+
+	streamID := uuid.New()
+	viewer := dial(t, ts, uuid.New(), streamID)
+
+	var pin entity.PinnedMessage
+	require.NoError(t, json.Unmarshal(readFrame(t, viewer, protocol.TypeMessagePinned).Payload, &pin))
+	assert.Equal(t, streamID, pin.StreamID)
+	assert.Equal(t, "welcome", pin.Content)
+	assert.Equal(t, entity.KindAnnouncement, pin.Kind)
+	require.NotNil(t, pin.ExpiresAt)
+	waitSubscribers(t, mr, streamID, 1)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	announcement := entity.NewAnnouncement(streamID, uuid.New(), "broadcaster", "stream starts soon", entity.AnnouncementGreen)
+	require.NoError(t, events.NewPublisher(client).Publish(context.Background(), events.NewMessageEvent(announcement)))
+
+	var got entity.ChatMessage
+	require.NoError(t, json.Unmarshal(readFrame(t, viewer, protocol.TypeAnnouncement).Payload, &got))
+	assert.Equal(t, announcement.ID, got.ID)
+	assert.Equal(t, entity.AnnouncementGreen, got.Color)
+}
+
+// TestCommandsAnsweredPrivately проверяет, что команды не попадают в чат,
+// а результат или отказ получает только отправитель
+func TestCommandsAnsweredPrivately(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ts := startInstance(t, mr.Addr())
+
+	streamID := uuid.New()
+	issuer := dial(t, ts, uuid.New(), streamID)
+	viewer := dial(t, ts, uuid.New(), streamID)
+	waitSubscribers(t, mr, streamID, 1)
+
+	send(t, issuer, "c-1", "/PING")
+	env := readFrame(t, issuer, protocol.TypeAck)
+	var result protocol.CommandResultPayload
+	require.NoError(t, json.Unmarshal(env.Payload, &result))
+	assert.Equal(t, "c-1", env.ID)
+	assert.Equal(t, protocol.CommandResultPayload{Command: "ping", Message: "pong"}, result)
+
+	// Зритель не может выполнять команды модерации
+	var nack protocol.NackPayload
+	send(t, issuer, "c-2", "/ban someone spam")
+	require.NoError(t, json.Unmarshal(readFrame(t, issuer, protocol.TypeNack).Payload, &nack))
+	assert.Equal(t, protocol.CodeForbidden, nack.Code)
+
+	send(t, issuer, "c-3", "/dance")
+	require.NoError(t, json.Unmarshal(readFrame(t, issuer, protocol.TypeNack).Payload, &nack))
+	assert.Equal(t, protocol.CodeUnknownCommand, nack.Code)
+
+	// Первым сообщением, которое увидят остальные, будет обычный текст
+	send(t, issuer, "c-4", "hello")
+	var got entity.ChatMessage
+	require.NoError(t, json.Unmarshal(readFrame(t, viewer, protocol.TypeMessage).Payload, &got))
+	assert.Equal(t, "hello", got.Content)
+}
+
+// TestRoomEventDelivered проверяет, что служебные события, опубликованные
+// сервисным слоем, доходят до участников комнаты
+func TestRoomEventDelivered(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ts := startInstance(t, mr.Addr())
+
+	streamID := uuid.New()
+	viewer := dial(t, ts, uuid.New(), streamID)
+	waitSubscribers(t, mr, streamID, 1)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	messageID := uuid.New()
+	event := entity.NewMessageDeletedEvent(streamID, uuid.New(), []uuid.UUID{messageID}, "spam")
+	require.NoError(t, events.NewPublisher(client).PublishEvent(context.Background(), streamID, protocol.TypeMessageDeleted, event))
+
+	var got entity.MessageDeletedEvent
+	require.NoError(t, json.Unmarshal(readFrame(t, viewer, protocol.TypeMessageDeleted).Payload, &got))
+	assert.Equal(t, []uuid.UUID{messageID}, got.MessageIDs)
+}
+
+// TestMultipleConnectionsPerUser проверяет, что вторая вкладка не вытесняет
+// первую, закрытие одной из них не затрагивает другую, а лимит соблюдается
+func TestMultipleConnectionsPerUser(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ts := startInstance(t, mr.Addr())
+
+	streamID := uuid.New()
+	userID := uuid.New()
+	firstTab := dial(t, ts, userID, streamID)
+	secondTab := dial(t, ts, userID, streamID)
+	sender := dial(t, ts, uuid.New(), streamID)
+	waitSubscribers(t, mr, streamID, 1)
+
+	_, resp, err := connect(t, ts, userID, uuid.New())
+	require.Error(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	send(t, sender, "c-1", "hello tabs")
+	for _, tab := range []*websocket.Conn{firstTab, secondTab} {
+		var got entity.ChatMessage
+		require.NoError(t, json.Unmarshal(readFrame(t, tab, protocol.TypeMessage).Payload, &got))
+		assert.Equal(t, "hello tabs", got.Content)
+	}
+
+	// После закрытия первой вкладки вторая продолжает получать сообщения,
+	// а освободившееся место можно занять новым подключением
+	require.NoError(t, firstTab.Close())
+	require.Eventually(t, func() bool {
+		conn, _, err := connect(t, ts, userID, streamID)
+		if err != nil {
+			return false
+		}
+		t.Cleanup(func() { conn.Close() })
+		return true
+	}, 2*time.Second, 20*time.Millisecond)
+
+	send(t, sender, "c-2", "still here")
+	var got entity.ChatMessage
+	require.NoError(t, json.Unmarshal(readFrame(t, secondTab, protocol.TypeMessage).Payload, &got))
+	assert.Equal(t, "still here", got.Content)
+}
+
+// TestResumeReplaysMissedMessages проверяет, что при переподключении с last_seq
+// пропущенные сообщения приходят по порядку до кадра resumed и живых событий
+func TestResumeReplaysMissedMessages(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ts := startInstance(t, mr.Addr())
+
+	streamID := uuid.New()
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL(t, ts, uuid.New(), streamID)+"&last_seq=41", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for _, want := range []int64{42, 43} {
+		var env protocol.Envelope
+		require.NoError(t, conn.ReadJSON(&env))
+		require.Equal(t, protocol.TypeMessage, env.Type)
+
+		var msg entity.ChatMessage
+		require.NoError(t, json.Unmarshal(env.Payload, &msg))
+		assert.Equal(t, want, msg.Seq)
+	}
+
+	var env protocol.Envelope
+	require.NoError(t, conn.ReadJSON(&env))
+	require.Equal(t, protocol.TypeResumed, env.Type)
+	var resumed protocol.ResumedPayload
+	require.NoError(t, json.Unmarshal(env.Payload, &resumed))
+	assert.Equal(t, protocol.ResumedPayload{LastSeq: 43, Replayed: 2}, resumed)
+
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL(t, ts, uuid.New(), streamID)+"&last_seq=-1", nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+// TestPresenceCoalesced проверяет, что входы и выходы приходят накопленными
+// событиями presence, по которым клиент восстанавливает состав чата
+func TestPresenceCoalesced(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ts := startInstance(t, mr.Addr())
+
+	streamID := uuid.New()
+	viewerID, secondID, passerbyID := uuid.New(), uuid.New(), uuid.New()
+	viewer := dial(t, ts, viewerID, streamID)
+	dial(t, ts, secondID, streamID)
+	require.NoError(t, dial(t, ts, passerbyID, streamID).Close())
+
+	// Присутствие по итогам всех событий: вход добавляет, выход убирает
+	present := make(map[uuid.UUID]bool)
+	viewer.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for !present[viewerID] || !present[secondID] || present[passerbyID] {
+		var env protocol.Envelope
+		require.NoError(t, viewer.ReadJSON(&env))
+		if env.Type != protocol.TypePresence {
+			continue
+		}
+		var event entity.PresenceEvent
+		require.NoError(t, json.Unmarshal(env.Payload, &event))
+		assert.Equal(t, len(event.Joined), event.JoinedCount)
+		for _, chatter := range event.Joined {
+			present[chatter.UserID] = true
+		}
+		for _, chatter := range event.Left {
+			delete(present, chatter.UserID)
+		}
+	}
+}
+
+// TestWhisperDeliveredToUser проверяет, что личный кадр, опубликованный через
+// канал пользователя, доходит до всех его вкладок на другом экземпляре
+func TestWhisperDeliveredToUser(t *testing.T) {
+	mr := miniredis.RunT(t)
+	first := startInstance(t, mr.Addr())
+	second := startInstance(t, mr.Addr())
+
+	// Вкладки получателя открыты на разных экземплярах: каждый экземпляр
+	// подписан на канал пользователя и доставляет кадр своим подключениям
+	recipientID := uuid.New()
+	firstTab := dial(t, first, recipientID, uuid.New())
+	secondTab := dial(t, second, recipientID, uuid.New())
+	channel := events.UserChannel(recipientID)
+	require.Eventually(t, func() bool {
+		return mr.PubSubNumSub(channel)[channel] == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	whisper := entity.NewWhisper(uuid.New(), "sender", recipientID, "psst")
+	frame, err := protocol.Encode(protocol.TypeWhisper, "", whisper)
+	require.NoError(t, err)
+	require.NoError(t, events.NewPublisher(client).PublishToUser(context.Background(), recipientID, frame))
+
+	for _, tab := range []*websocket.Conn{firstTab, secondTab} {
+		var got entity.Whisper
+		require.NoError(t, json.Unmarshal(readFrame(t, tab, protocol.TypeWhisper).Payload, &got))
+		assert.Equal(t, whisper.ID, got.ID)
+		assert.Equal(t, "psst", got.Content)
+	}
+
+	// После закрытия последней вкладки экземпляр отписывается от канала пользователя
+	require.NoError(t, secondTab.Close())
+	require.Eventually(t, func() bool {
+		return mr.PubSubNumSub(channel)[channel] == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, firstTab.Close())
+	require.Eventually(t, func() bool {
+		return mr.PubSubNumSub(channel)[channel] == 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP TABLE IF EXISTS chat_bans;
+DROP TABLE IF EXISTS chat_rooms;
@@ -0,0 +1,28 @@
+-- +migrate Up
+CREATE EXTENSION IF NOT EXISTS "pgcrypto";
+
+-- Комнаты чата (по одной активной на стрим)
+CREATE TABLE IF NOT EXISTS chat_rooms (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    stream_id    UUID      NOT NULL,
+    stream_title TEXT,
+    created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    is_active    BOOLEAN   NOT NULL DEFAULT TRUE
+);
+
+CREATE INDEX idx_chat_rooms_stream_id ON chat_rooms (stream_id);
+
+-- Баны и таймауты (expires_at IS NULL — постоянный бан)
+CREATE TABLE IF NOT EXISTS chat_bans (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    stream_id    UUID      NOT NULL,
+    user_id      UUID      NOT NULL,
+    moderator_id UUID,
+    reason       TEXT,
+    banned_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at   TIMESTAMP
+);
+
+CREATE INDEX idx_chat_bans_stream_id ON chat_bans (stream_id);
+CREATE INDEX idx_chat_bans_user_id ON chat_bans (user_id);
+CREATE INDEX idx_chat_bans_expires_at ON chat_bans (expires_at);
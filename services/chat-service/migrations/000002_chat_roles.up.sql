@@ -0,0 +1,12 @@
+-- +migrate Up
+-- Модераторы и VIP чатов стримов. Бродкастер определяется владельцем стрима
+CREATE TABLE IF NOT EXISTS chat_roles (
+    stream_id  UUID      NOT NULL,
+    user_id    UUID      NOT NULL,
+    role       TEXT CHECK (role IN ('moderator', 'vip')) NOT NULL,
+    granted_by UUID      NOT NULL,
+    granted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (stream_id, user_id)
+);
+
+CREATE INDEX idx_chat_roles_user_id ON chat_roles (user_id);
@@ -0,0 +1,7 @@
+-- +migrate Down
+ALTER TABLE chat_rooms
+    DROP COLUMN IF EXISTS slow_mode_seconds,
+    DROP COLUMN IF EXISTS followers_only,
+    DROP COLUMN IF EXISTS followers_min_minutes,
+    DROP COLUMN IF EXISTS emote_only,
+    DROP COLUMN IF EXISTS subscribers_only;
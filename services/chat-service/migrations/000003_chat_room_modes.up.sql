@@ -0,0 +1,8 @@
+-- +migrate Up
+-- Режимы чата: slow mode, followers-only, emote-only, subscriber-only
+ALTER TABLE chat_rooms
+    ADD COLUMN slow_mode_seconds     INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN followers_only        BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN followers_min_minutes INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN emote_only            BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN subscribers_only      BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP TABLE IF EXISTS chat_automod_settings;
+DROP TABLE IF EXISTS chat_blocked_terms;
@@ -0,0 +1,25 @@
+-- +migrate Up
+-- Запрещенные выражения AutoMod (* — любая последовательность букв)
+CREATE TABLE IF NOT EXISTS chat_blocked_terms (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    stream_id  UUID      NOT NULL,
+    pattern    TEXT      NOT NULL,
+    action     TEXT CHECK (action IN ('mask', 'hold', 'reject')) NOT NULL,
+    created_by UUID      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (stream_id, pattern)
+);
+
+CREATE INDEX idx_chat_blocked_terms_stream_id ON chat_blocked_terms (stream_id);
+
+-- Настройки эвристических фильтров AutoMod (0 выключает фильтр)
+CREATE TABLE IF NOT EXISTS chat_automod_settings (
+    stream_id                UUID PRIMARY KEY,
+    caps_percent             INTEGER   NOT NULL DEFAULT 70,
+    max_repeated_chars       INTEGER   NOT NULL DEFAULT 10,
+    duplicate_window_seconds INTEGER   NOT NULL DEFAULT 30,
+    block_links              BOOLEAN   NOT NULL DEFAULT FALSE,
+    link_action              TEXT CHECK (link_action IN ('mask', 'hold', 'reject')) NOT NULL DEFAULT 'reject',
+    link_allowlist           JSONB     NOT NULL DEFAULT '[]',
+    updated_at               TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,18 @@
+-- +migrate Up
+-- Опросы в чатах стримов. Во время голосования голоса считаются в Redis,
+-- итоги записываются в options и total_votes при завершении опроса
+CREATE TABLE IF NOT EXISTS chat_polls (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    stream_id   UUID      NOT NULL,
+    question    TEXT      NOT NULL,
+    options     JSONB     NOT NULL,
+    total_votes INTEGER   NOT NULL DEFAULT 0,
+    status      TEXT CHECK (status IN ('active', 'ended')) NOT NULL DEFAULT 'active',
+    created_by  UUID      NOT NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ends_at     TIMESTAMP NOT NULL,
+    ended_at    TIMESTAMP
+);
+
+CREATE INDEX idx_chat_polls_stream_id ON chat_polls (stream_id, created_at DESC);
+CREATE INDEX idx_chat_polls_active ON chat_polls (ends_at) WHERE status = 'active';
@@ -0,0 +1,10 @@
+-- +migrate Up
+-- Блокировки личных сообщений: blocked_id не может писать blocker_id, и наоборот
+CREATE TABLE IF NOT EXISTS chat_user_blocks (
+    blocker_id UUID      NOT NULL,
+    blocked_id UUID      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (blocker_id, blocked_id)
+);
+
+CREATE INDEX idx_chat_user_blocks_blocked_id ON chat_user_blocks (blocked_id);
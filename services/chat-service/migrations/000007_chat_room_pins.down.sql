@@ -0,0 +1,3 @@
+-- +migrate Down
+ALTER TABLE chat_rooms
+    DROP COLUMN IF EXISTS pinned_message;
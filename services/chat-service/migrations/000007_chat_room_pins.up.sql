@@ -0,0 +1,5 @@
+-- +migrate Up
+-- Закрепленное сообщение комнаты: снимок сообщения, кто и когда его закрепил,
+-- срок закрепления. NULL — ничего не закреплено
+ALTER TABLE chat_rooms
+    ADD COLUMN pinned_message JSONB;
@@ -0,0 +1,12 @@
+-- +migrate Up
+-- Эмоуты каналов; stream_id из нулей — глобальный набор, доступный во всех чатах.
+-- Изображения лежат в файловом хранилище по ключу emotes/<id>/<размер>.<format>
+CREATE TABLE IF NOT EXISTS chat_emotes (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    stream_id  UUID      NOT NULL,
+    code       TEXT      NOT NULL,
+    format     TEXT CHECK (format IN ('png', 'gif')) NOT NULL,
+    created_by UUID      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (stream_id, code)
+);
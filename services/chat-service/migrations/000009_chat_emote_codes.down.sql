@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP TRIGGER IF EXISTS chat_emotes_check_code ON chat_emotes;
+DROP FUNCTION IF EXISTS chat_emotes_check_code();
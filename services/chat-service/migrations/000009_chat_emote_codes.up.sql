@@ -0,0 +1,26 @@
+-- +migrate Up
+-- Код глобального эмоута не должен совпадать ни с одним кодом канала, и наоборот.
+-- UNIQUE (stream_id, code) этого не покрывает, поэтому проверка выполняется
+-- триггером; advisory-блокировка по коду упорядочивает параллельные вставки
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION chat_emotes_check_code() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('chat_emotes:' || NEW.code));
+    IF EXISTS (
+        SELECT 1 FROM chat_emotes
+        WHERE code = NEW.code
+          AND id <> NEW.id
+          AND (NEW.stream_id = '00000000-0000-0000-0000-000000000000'
+               OR stream_id IN ('00000000-0000-0000-0000-000000000000', NEW.stream_id))
+    ) THEN
+        RAISE EXCEPTION 'emote code % is already in use', NEW.code
+            USING ERRCODE = 'unique_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+CREATE TRIGGER chat_emotes_check_code
+    BEFORE INSERT OR UPDATE OF stream_id, code ON chat_emotes
+    FOR EACH ROW EXECUTE FUNCTION chat_emotes_check_code();
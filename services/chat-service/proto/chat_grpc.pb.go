@@ -22,6 +22,7 @@ const (
 	ChatService_SendMessage_FullMethodName    = "/chat.ChatService/SendMessage"
 	ChatService_GetChatHistory_FullMethodName = "/chat.ChatService/GetChatHistory"
 	ChatService_StreamMessages_FullMethodName = "/chat.ChatService/StreamMessages"
+	ChatService_GetChatters_FullMethodName    = "/chat.ChatService/GetChatters"
 )
 
 // ChatServiceClient is the client API for ChatService service.
@@ -30,12 +31,16 @@ const (
 //
 // gRPC-сервис для работы с чатом
 type ChatServiceClient interface {
-	// Отправка сообщения
+	// Отправка сообщения от имени владельца access-токена
+	// из метаданных authorization ("Bearer <token>")
 	SendMessage(ctx context.Context, in *ChatMessage, opts ...grpc.CallOption) (*ChatResponse, error)
 	// Получение истории сообщений
 	GetChatHistory(ctx context.Context, in *ChatHistoryRequest, opts ...grpc.CallOption) (*ChatHistoryResponse, error)
-	// Стриминг новых сообщений
+	// Стриминг новых сообщений. Если клиент не успевает их забирать, поток
+	// завершается с RESOURCE_EXHAUSTED: нужно переподключиться с last_seq
 	StreamMessages(ctx context.Context, in *StreamMessageRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChatMessage], error)
+	// Пользователи, находящиеся в чате
+	GetChatters(ctx context.Context, in *ChattersRequest, opts ...grpc.CallOption) (*ChattersResponse, error)
 }
 
 type chatServiceClient struct {
@@ -85,18 +90,32 @@ func (c *chatServiceClient) StreamMessages(ctx context.Context, in *StreamMessag
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type ChatService_StreamMessagesClient = grpc.ServerStreamingClient[ChatMessage]
 
+func (c *chatServiceClient) GetChatters(ctx context.Context, in *ChattersRequest, opts ...grpc.CallOption) (*ChattersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChattersResponse)
+	err := c.cc.Invoke(ctx, ChatService_GetChatters_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ChatServiceServer is the server API for ChatService service.
 // All implementations must embed UnimplementedChatServiceServer
 // for forward compatibility.
 //
 // gRPC-сервис для работы с чатом
 type ChatServiceServer interface {
-	// Отправка сообщения
+	// Отправка сообщения от имени владельца access-токена
+	// из метаданных authorization ("Bearer <token>")
 	SendMessage(context.Context, *ChatMessage) (*ChatResponse, error)
 	// Получение истории сообщений
 	GetChatHistory(context.Context, *ChatHistoryRequest) (*ChatHistoryResponse, error)
-	// Стриминг новых сообщений
+	// Стриминг новых сообщений. Если клиент не успевает их забирать, поток
+	// завершается с RESOURCE_EXHAUSTED: нужно переподключиться с last_seq
 	StreamMessages(*StreamMessageRequest, grpc.ServerStreamingServer[ChatMessage]) error
+	// Пользователи, находящиеся в чате
+	GetChatters(context.Context, *ChattersRequest) (*ChattersResponse, error)
 	mustEmbedUnimplementedChatServiceServer()
 }
 
@@ -116,6 +135,9 @@ func (UnimplementedChatServiceServer) GetChatHistory(context.Context, *ChatHisto
 func (UnimplementedChatServiceServer) StreamMessages(*StreamMessageRequest, grpc.ServerStreamingServer[ChatMessage]) error {
 	return status.Errorf(codes.Unimplemented, "method StreamMessages not implemented")
 }
+func (UnimplementedChatServiceServer) GetChatters(context.Context, *ChattersRequest) (*ChattersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChatters not implemented")
+}
 func (UnimplementedChatServiceServer) mustEmbedUnimplementedChatServiceServer() {}
 func (UnimplementedChatServiceServer) testEmbeddedByValue()                     {}
 
@@ -184,6 +206,24 @@ func _ChatService_StreamMessages_Handler(srv interface{}, stream grpc.ServerStre
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type ChatService_StreamMessagesServer = grpc.ServerStreamingServer[ChatMessage]
 
+func _ChatService_GetChatters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChattersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).GetChatters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatService_GetChatters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).GetChatters(ctx, req.(*ChattersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ChatService_ServiceDesc is the grpc.ServiceDesc for ChatService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -199,6 +239,10 @@ var ChatService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetChatHistory",
 			Handler:    _ChatService_GetChatHistory_Handler,
 		},
+		{
+			MethodName: "GetChatters",
+			Handler:    _ChatService_GetChatters_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -36,6 +36,7 @@ func (h *StreamHandler) StartStream(ctx context.Context, req *proto.StartStreamR
 		Status:      stream.Status,
 		CreatedAt:   timestamppb.New(stream.CreatedAt),
 		UpdatedAt:   timestamppb.New(stream.UpdatedAt),
+		StartTime:   timestamppb.New(stream.StartTime),
 	}, nil
 }
 
@@ -67,14 +68,21 @@ func (h *StreamHandler) GetStream(ctx context.Context, req *proto.GetStreamReque
 		return nil, err
 	}
 
-	return &proto.StreamResponse{
+	resp := &proto.StreamResponse{
 		Id:        stream.ID.String(),
 		UserId:    stream.UserID,
 		Title:     stream.Title,
 		Status:    stream.Status,
 		CreatedAt: timestamppb.New(stream.CreatedAt),
 		UpdatedAt: timestamppb.New(stream.UpdatedAt),
-	}, nil
+	}
+	if !stream.StartTime.IsZero() {
+		resp.StartTime = timestamppb.New(stream.StartTime)
+	}
+	if !stream.EndTime.IsZero() {
+		resp.EndTime = timestamppb.New(stream.EndTime)
+	}
+	return resp, nil
 }
 
 // GenerateStreamKey создаёт новый stream-key для пользователя
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"fmt"
 	"log"
 
 	"github.com/exPriceD/Streaming-platform/config"
 	"github.com/exPriceD/Streaming-platform/pkg/db"
+	streamgrpc "github.com/exPriceD/Streaming-platform/services/streaming-service/api/grpc"
 	"github.com/exPriceD/Streaming-platform/services/streaming-service/internal/handler"
 	"github.com/exPriceD/Streaming-platform/services/streaming-service/internal/repository"
 	"github.com/exPriceD/Streaming-platform/services/streaming-service/internal/service"
@@ -39,8 +41,16 @@ func main() {
 	// Регистрируем обработчики
 	handler.NewStreamHandler(e, streamService)
 
+	// Запускаем gRPC сервер для других сервисов (например, chat-service)
+	go func() {
+		grpcAddress := fmt.Sprintf("%s:%d", cfg.GRPC.Host, cfg.GRPC.Port)
+		if err := streamgrpc.StartGRPCServer(streamService, grpcAddress); err != nil {
+			log.Fatalf("Ошибка запуска gRPC сервера: %v", err)
+		}
+	}()
+
 	// Запускаем сервер
-	address := cfg.Server.Host + ":" + cfg.Server.Port
+	address := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
 	log.Printf("Streaming Service запущен на %s", address)
 	if err := e.Start(address); err != nil {
 		log.Fatalf("Ошибка запуска сервера: %v", err)
@@ -3,6 +3,7 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/exPriceD/Streaming-platform/services/streaming-service/internal/models"
 	"github.com/google/uuid"
@@ -20,36 +21,44 @@ func NewStreamRepository(db *sql.DB) *StreamRepository {
 
 // CreateStream добавляет новый стрим в БД.
 func (r *StreamRepository) CreateStream(stream models.Stream) error {
-	query := `INSERT INTO streams (id, title, user_id, status, created_at, updated_at)
-			  VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err := r.db.Exec(query, stream.ID, stream.Title, stream.UserID, stream.Status, stream.CreatedAt, stream.UpdatedAt)
+	query := `INSERT INTO streams (id, title, user_id, status, start_time, created_at, updated_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := r.db.Exec(query, stream.ID, stream.Title, stream.UserID, stream.Status, nullTime(stream.StartTime), stream.CreatedAt, stream.UpdatedAt)
 	return err
 }
 
 // GetStreamByID получает стрим по ID.
 func (r *StreamRepository) GetStreamByID(id uuid.UUID) (*models.Stream, error) {
-	query := `SELECT id, title, user_id, status, created_at, updated_at FROM streams WHERE id = $1`
+	query := `SELECT id, title, user_id, status, start_time, end_time, created_at, updated_at FROM streams WHERE id = $1`
 	row := r.db.QueryRow(query, id)
 
 	var stream models.Stream
-	err := row.Scan(&stream.ID, &stream.Title, &stream.UserID, &stream.Status, &stream.CreatedAt, &stream.UpdatedAt)
+	var startTime, endTime sql.NullTime
+	err := row.Scan(&stream.ID, &stream.Title, &stream.UserID, &stream.Status, &startTime, &endTime, &stream.CreatedAt, &stream.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, errors.New("stream not found")
 		}
 		return nil, err
 	}
+	stream.StartTime = startTime.Time
+	stream.EndTime = endTime.Time
 
 	return &stream, nil
 }
 
 // UpdateStream обновляет данные стрима.
 func (r *StreamRepository) UpdateStream(stream models.Stream) error {
-	query := `UPDATE streams SET title=$1, status=$2, updated_at=$3 WHERE id=$4`
-	_, err := r.db.Exec(query, stream.Title, stream.Status, stream.UpdatedAt, stream.ID)
+	query := `UPDATE streams SET title=$1, status=$2, end_time=$3, updated_at=$4 WHERE id=$5`
+	_, err := r.db.Exec(query, stream.Title, stream.Status, nullTime(stream.EndTime), stream.UpdatedAt, stream.ID)
 	return err
 }
 
+// nullTime сохраняет нулевое время как NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
 // DeleteStream удаляет стрим по ID.
 func (r *StreamRepository) DeleteStream(id uuid.UUID) error {
 	query := `DELETE FROM streams WHERE id = $1`